@@ -0,0 +1,141 @@
+package ed25519
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+
+	"github.com/cocher/crypto/ed25519/internal/edwards25519"
+)
+
+// batchScalarSize is the size, in bytes, of the random coefficients used to
+// combine the signatures of a batch. 128 bits bound the chance of an invalid
+// batch passing to 2^-128.
+const batchScalarSize = 16
+
+// BatchVerify reports whether every sigs[i] is a valid signature of
+// messages[i] by publicKeys[i]. An empty batch is valid.
+//
+// The signatures are checked together as a random linear combination of their
+// verification equations, which costs much less than calling Verify on each.
+// A false result does not tell which signature is invalid; use Verify to find
+// it. The combined equation is multiplied by the cofactor, so a signature
+// that only differs from a valid one by a small-order component is accepted
+// here although Verify rejects it. Such signatures can only be crafted by the
+// owner of the key.
+func BatchVerify(publicKeys []PublicKey, messages, sigs [][]byte) bool {
+	if len(publicKeys) != len(messages) || len(publicKeys) != len(sigs) {
+		return false
+	}
+	if len(publicKeys) == 0 {
+		return true
+	}
+
+	n := len(publicKeys)
+
+	random := make([]byte, n*batchScalarSize)
+	if _, err := cryptorand.Read(random); err != nil {
+		return false
+	}
+
+	// Points are negated so that a valid batch sums up to the identity:
+	//   b*B + sum(z[i]*h[i]*(-A[i]) + z[i]*(-R[i])) = 0, where b = sum(z[i]*s[i]).
+	scalars := make([][32]byte, 2*n)
+	points := make([]edwards25519.ExtendedGroupElement, 2*n)
+	var b, zero [32]byte
+
+	for i := 0; i < n; i++ {
+		publicKey, sig := publicKeys[i], sigs[i]
+		if len(publicKey) != PublicKeySize || len(sig) != SignatureSize || sig[63]&224 != 0 {
+			return false
+		}
+
+		var publicKeyBytes, R [32]byte
+		copy(publicKeyBytes[:], publicKey)
+		copy(R[:], sig[:32])
+
+		// Verify compares encodings, so a non-canonical R can never be valid.
+		if !isCanonical(&R) {
+			return false
+		}
+
+		A, RPoint := &points[2*i], &points[2*i+1]
+		if !A.FromBytes(&publicKeyBytes) || !RPoint.FromBytes(&R) {
+			return false
+		}
+		edwards25519.FeNeg(&A.X, &A.X)
+		edwards25519.FeNeg(&A.T, &A.T)
+		edwards25519.FeNeg(&RPoint.X, &RPoint.X)
+		edwards25519.FeNeg(&RPoint.T, &RPoint.T)
+
+		h := sha512.New()
+		h.Write(sig[:32])
+		h.Write(publicKey)
+		h.Write(messages[i])
+		var digest [64]byte
+		h.Sum(digest[:0])
+
+		var hReduced [32]byte
+		edwards25519.ScReduce(&hReduced, &digest)
+
+		var z, s [32]byte
+		copy(z[:], random[i*batchScalarSize:(i+1)*batchScalarSize])
+		copy(s[:], sig[32:])
+
+		edwards25519.ScMulAdd(&scalars[2*i], &z, &hReduced, &zero)
+		scalars[2*i+1] = z
+		edwards25519.ScMulAdd(&b, &z, &s, &b)
+	}
+
+	var check edwards25519.ProjectiveGroupElement
+	edwards25519.GeMultiScalarMultVartime(&check, scalars, points, &b)
+
+	// Clear any small-order component by multiplying with the cofactor 8.
+	var t edwards25519.CompletedGroupElement
+	for i := 0; i < 3; i++ {
+		check.Double(&t)
+		t.ToProjective(&check)
+	}
+
+	var checkBytes [32]byte
+	check.ToBytes(&checkBytes)
+	return checkBytes == identity
+}
+
+// identity is the encoding of the neutral element (0, 1).
+var identity = [32]byte{1}
+
+// isCanonical reports whether s is the unique encoding of the point it
+// decodes to: y must be reduced modulo p = 2^255 - 19, and x = 0 must not carry
+// a sign bit.
+func isCanonical(s *[32]byte) bool {
+	// y >= p only if all bits of y above the lowest byte are set and the
+	// lowest byte is at least 0xed.
+	reduced := s[0] < 0xed || s[31]&0x7f != 0x7f
+	for i := 1; !reduced && i < 31; i++ {
+		if s[i] != 0xff {
+			reduced = true
+		}
+	}
+	if !reduced {
+		return false
+	}
+
+	// x = 0 only for y = 1 and y = p - 1.
+	if s[31]&0x80 != 0 {
+		var y [32]byte
+		copy(y[:], s[:])
+		y[31] &= 0x7f
+		if y == identity || y == minusOne {
+			return false
+		}
+	}
+	return true
+}
+
+// minusOne is the encoding of p - 1.
+var minusOne = [32]byte{
+	0xec, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+}
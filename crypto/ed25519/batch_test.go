@@ -0,0 +1,189 @@
+package ed25519
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newBatch(n int) ([]PublicKey, [][]byte, [][]byte) {
+	publicKeys := make([]PublicKey, n)
+	messages := make([][]byte, n)
+	sigs := make([][]byte, n)
+
+	for i := 0; i < n; i++ {
+		publicKey, privateKey, err := GenerateKey(rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		publicKeys[i] = publicKey
+		messages[i] = []byte(fmt.Sprintf("test message %d", i))
+		sigs[i] = Sign(privateKey, messages[i])
+	}
+	return publicKeys, messages, sigs
+}
+
+func BenchmarkBatchVerify(b *testing.B) {
+	for _, n := range []int{8, 64, 256} {
+		publicKeys, messages, sigs := newBatch(n)
+
+		b.Run(fmt.Sprintf("batch-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !BatchVerify(publicKeys, messages, sigs) {
+					panic("verification failed")
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("single-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range sigs {
+					if !Verify(publicKeys[j], messages[j], sigs[j]) {
+						panic("verification failed")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	t.Parallel()
+
+	publicKeys, messages, sigs := newBatch(16)
+
+	if !BatchVerify(publicKeys, messages, sigs) {
+		t.Errorf("BatchVerify() = false, want true")
+	}
+
+	if !BatchVerify(nil, nil, nil) {
+		t.Errorf("BatchVerify() of an empty batch = false, want true")
+	}
+
+	if BatchVerify(publicKeys, messages, sigs[1:]) {
+		t.Errorf("BatchVerify() with mismatched lengths = true, want false")
+	}
+
+	// a single bad signature anywhere in the batch should fail it
+	for _, i := range []int{0, 7, 15} {
+		tampered := make([][]byte, len(sigs))
+		copy(tampered, sigs)
+		tampered[i] = append([]byte{}, sigs[i]...)
+		tampered[i][40] ^= 1
+
+		if BatchVerify(publicKeys, messages, tampered) {
+			t.Errorf("BatchVerify() with a tampered signature at %d = true, want false", i)
+		}
+	}
+
+	// wrong message should fail the batch
+	wrongMessages := make([][]byte, len(messages))
+	copy(wrongMessages, messages)
+	wrongMessages[3] = []byte("wrong message")
+	if BatchVerify(publicKeys, wrongMessages, sigs) {
+		t.Errorf("BatchVerify() with a wrong message = true, want false")
+	}
+
+	// swapped signatures are individually valid but not for these messages
+	swapped := make([][]byte, len(sigs))
+	copy(swapped, sigs)
+	swapped[1], swapped[2] = swapped[2], swapped[1]
+	if BatchVerify(publicKeys, messages, swapped) {
+		t.Errorf("BatchVerify() with swapped signatures = true, want false")
+	}
+
+	// bad public key length should fail the batch instead of panicking
+	badKeys := make([]PublicKey, len(publicKeys))
+	copy(badKeys, publicKeys)
+	badKeys[5] = PublicKey("bad key")
+	if BatchVerify(badKeys, messages, sigs) {
+		t.Errorf("BatchVerify() with a bad public key = true, want false")
+	}
+}
+
+func TestBatchVerifyNonCanonical(t *testing.T) {
+	t.Parallel()
+
+	publicKeys, messages, sigs := newBatch(2)
+
+	// R = (0, 1) with the sign bit set decodes to the identity, but is not its encoding.
+	nonCanonical := make([][]byte, len(sigs))
+	copy(nonCanonical, sigs)
+	nonCanonical[0] = append([]byte{}, sigs[0]...)
+	copy(nonCanonical[0][:32], identity[:])
+	nonCanonical[0][31] |= 0x80
+
+	if BatchVerify(publicKeys, messages, nonCanonical) {
+		t.Errorf("BatchVerify() with a non-canonical R = true, want false")
+	}
+
+	for _, tt := range []struct {
+		encoding  string
+		canonical bool
+	}{
+		{"0100000000000000000000000000000000000000000000000000000000000000", true},
+		{"0100000000000000000000000000000000000000000000000000000000000080", false},
+		{"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", true},
+		{"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", false},
+		{"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", false},
+		{"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", false},
+		{"edfffffffffffffffffffffffffffffffffffffffffffffffffffffffeffff7f", true},
+	} {
+		var s [32]byte
+		b, _ := hex.DecodeString(tt.encoding)
+		copy(s[:], b)
+		if isCanonical(&s) != tt.canonical {
+			t.Errorf("isCanonical(%s) = %v, want %v", tt.encoding, !tt.canonical, tt.canonical)
+		}
+	}
+}
+
+func TestBatchVerifyGolden(t *testing.T) {
+	testDataZ, err := os.Open("testdata/sign.input.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDataZ.Close()
+	testData, err := gzip.NewReader(testDataZ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testData.Close()
+
+	var publicKeys []PublicKey
+	var messages, sigs [][]byte
+
+	scanner := bufio.NewScanner(testData)
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), ":")
+		if len(parts) != 5 {
+			t.Fatalf("bad number of parts on line %d", len(sigs)+1)
+		}
+
+		pubKey, _ := hex.DecodeString(parts[1])
+		msg, _ := hex.DecodeString(parts[2])
+		sig, _ := hex.DecodeString(parts[3])
+
+		publicKeys = append(publicKeys, pubKey)
+		messages = append(messages, msg)
+		sigs = append(sigs, sig[:SignatureSize])
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("error reading test data: %s", err)
+	}
+
+	if !BatchVerify(publicKeys, messages, sigs) {
+		t.Errorf("BatchVerify() of %d golden signatures = false, want true", len(sigs))
+	}
+
+	// the last golden message is tampered with to fail the batch
+	messages[len(messages)-1] = append(messages[len(messages)-1], 0)
+	if BatchVerify(publicKeys, messages, sigs) {
+		t.Errorf("BatchVerify() of a tampered golden batch = true, want false")
+	}
+}
@@ -12,6 +12,7 @@ type Ed25519 struct {
 
 var (
 	_ crypto.SignaturePolicy = (*Ed25519)(nil)
+	_ crypto.BatchVerifier   = (*Ed25519)(nil)
 )
 
 // New returns an Ed25519 structure.
@@ -64,6 +65,15 @@ func (p *Ed25519) Verify(publicKey []byte, message []byte, signature []byte) boo
 	return Verify(publicKey, message, signature)
 }
 
+// BatchVerify returns true if every signature was signed using the public key and message at the same index.
+func (p *Ed25519) BatchVerify(publicKeys [][]byte, messages [][]byte, signatures [][]byte) bool {
+	keys := make([]PublicKey, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		keys[i] = publicKey
+	}
+	return BatchVerify(keys, messages, signatures)
+}
+
 // RandomKeyPair generates a randomly seeded ed25519 key pair.
 func RandomKeyPair() *crypto.KeyPair {
 	publicKey, privateKey, err := GenerateKey(rand.Reader)
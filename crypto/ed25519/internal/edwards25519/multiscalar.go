@@ -0,0 +1,78 @@
+package edwards25519
+
+// GeMultiScalarMultVartime sets r = b*B + a[0]*A[0] + ... + a[n-1]*A[n-1]
+// where every scalar is encoded as in GeDoubleScalarMultVartime and B is the
+// Ed25519 base point.
+//
+// It uses Straus' method: the 256 doublings are shared between all points, so
+// it is considerably cheaper than n separate scalar multiplications. It is not
+// constant time and must only be used with public inputs.
+func GeMultiScalarMultVartime(r *ProjectiveGroupElement, a [][32]byte, A []ExtendedGroupElement, b *[32]byte) {
+	if len(a) != len(A) {
+		panic("edwards25519: mismatched number of scalars and points")
+	}
+
+	aSlide := make([][256]int8, len(a))
+	Ai := make([][8]CachedGroupElement, len(A)) // A,3A,5A,7A,9A,11A,13A,15A
+	var bSlide [256]int8
+	var t CompletedGroupElement
+	var u, A2 ExtendedGroupElement
+	var i int
+
+	for j := range a {
+		slide(&aSlide[j], &a[j])
+
+		A[j].ToCached(&Ai[j][0])
+		A[j].Double(&t)
+		t.ToExtended(&A2)
+
+		for k := 0; k < 7; k++ {
+			geAdd(&t, &A2, &Ai[j][k])
+			t.ToExtended(&u)
+			u.ToCached(&Ai[j][k+1])
+		}
+	}
+	slide(&bSlide, b)
+
+	r.Zero()
+
+	for i = 255; i >= 0; i-- {
+		if bSlide[i] != 0 {
+			break
+		}
+		nonZero := false
+		for j := range aSlide {
+			if aSlide[j][i] != 0 {
+				nonZero = true
+				break
+			}
+		}
+		if nonZero {
+			break
+		}
+	}
+
+	for ; i >= 0; i-- {
+		r.Double(&t)
+
+		for j := range aSlide {
+			if aSlide[j][i] > 0 {
+				t.ToExtended(&u)
+				geAdd(&t, &u, &Ai[j][aSlide[j][i]/2])
+			} else if aSlide[j][i] < 0 {
+				t.ToExtended(&u)
+				geSub(&t, &u, &Ai[j][(-aSlide[j][i])/2])
+			}
+		}
+
+		if bSlide[i] > 0 {
+			t.ToExtended(&u)
+			geMixedAdd(&t, &u, &bi[bSlide[i]/2])
+		} else if bSlide[i] < 0 {
+			t.ToExtended(&u)
+			geMixedSub(&t, &u, &bi[(-bSlide[i])/2])
+		}
+
+		t.ToProjective(r)
+	}
+}
@@ -0,0 +1,27 @@
+package keccak
+
+import (
+	"github.com/cocher/crypto"
+
+	sha3lib "golang.org/x/crypto/sha3"
+)
+
+// Keccak represents the original Keccak-256 hash algorithm, as used by Ethereum.
+// It differs from SHA3-256 only in its padding.
+type Keccak struct{}
+
+var (
+	_ crypto.HashPolicy = (*Keccak)(nil)
+)
+
+// New returns a Keccak-256 hash policy.
+func New() *Keccak {
+	return &Keccak{}
+}
+
+// HashBytes hashes the given bytes using the Keccak-256 hash algorithm.
+func (p *Keccak) HashBytes(bytes []byte) []byte {
+	h := sha3lib.NewLegacyKeccak256()
+	h.Write(bytes)
+	return h.Sum(nil)
+}
@@ -0,0 +1,65 @@
+package keccak
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/cocher/crypto"
+)
+
+func BenchmarkHash(b *testing.B) {
+	hp := New()
+
+	message := make([]byte, 64)
+	_, err := rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		hp.HashBytes(message)
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+	hp := New()
+
+	r := crypto.Hash(hp, big.NewInt(123))
+
+	n := new(big.Int)
+	n, ok := n.SetString("76495408746680389550338499638379293609691976073007980668405434357245214666578", 10)
+	if !ok {
+		t.Errorf("big int error")
+	}
+	if n.String() != r.String() {
+		t.Errorf("String() n = %v, want %v", n, r)
+	}
+}
+
+func TestHashBytes(t *testing.T) {
+	t.Parallel()
+	hp := New()
+
+	// Known Keccak-256 digests; note they differ from SHA3-256 of the same input.
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	}
+
+	for _, tt := range testCases {
+		expected, _ := hex.DecodeString(tt.expected)
+		r := hp.HashBytes([]byte(tt.input))
+		if !bytes.Equal(expected, r) {
+			t.Errorf("HashBytes(%q) = %x, want %x", tt.input, r, expected)
+		}
+	}
+}
@@ -81,3 +81,32 @@ func Verify(sp SignaturePolicy, hp HashPolicy, publicKey []byte, message []byte,
 	message = hp.HashBytes(message)
 	return sp.Verify(publicKey, message, signature)
 }
+
+// VerifyBatch returns, for every index, whether the signature was generated using the public key and message at that
+// index, the signature policy, and the hash policy. Signature policies implementing BatchVerifier check the whole batch
+// at once, falling back to verifying each signature individually to find the invalid ones only if the batch fails.
+func VerifyBatch(sp SignaturePolicy, hp HashPolicy, publicKeys [][]byte, messages [][]byte, signatures [][]byte) []bool {
+	if len(publicKeys) != len(messages) || len(publicKeys) != len(signatures) {
+		return make([]bool, len(signatures))
+	}
+
+	hashed := make([][]byte, len(messages))
+	for i, message := range messages {
+		hashed[i] = hp.HashBytes(message)
+	}
+
+	results := make([]bool, len(signatures))
+
+	if bv, ok := sp.(BatchVerifier); ok && len(signatures) > 1 && bv.BatchVerify(publicKeys, hashed, signatures) {
+		for i := range results {
+			results[i] = true
+		}
+		return results
+	}
+
+	for i := range results {
+		// Public key must be a set size.
+		results[i] = len(publicKeys[i]) == sp.PublicKeySize() && sp.Verify(publicKeys[i], hashed[i], signatures[i])
+	}
+	return results
+}
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/cocher/crypto"
+	"github.com/cocher/crypto/blake2b"
+	"github.com/cocher/crypto/ed25519"
 	"github.com/cocher/crypto/mocks"
 
 	gomock "github.com/golang/mock/gomock"
@@ -157,3 +159,70 @@ func TestFromPrivateKey(t *testing.T) {
 		t.Errorf("expected keypair %+v = %+v", kp1, kp2)
 	}
 }
+
+func TestVerifyBatch(t *testing.T) {
+	t.Parallel()
+
+	sp := ed25519.New()
+	hp := blake2b.New()
+
+	var publicKeys, messages, signatures [][]byte
+	for i := 0; i < 8; i++ {
+		kp := sp.RandomKeyPair()
+		msg := []byte{byte(i)}
+		sig, err := kp.Sign(sp, hp, msg)
+		if err != nil {
+			t.Fatalf("Sign() = %v, expected <nil>", err)
+		}
+		publicKeys = append(publicKeys, kp.PublicKey)
+		messages = append(messages, msg)
+		signatures = append(signatures, sig)
+	}
+
+	for i, ok := range crypto.VerifyBatch(sp, hp, publicKeys, messages, signatures) {
+		if !ok {
+			t.Errorf("VerifyBatch()[%d] = false, expected true", i)
+		}
+	}
+
+	// the batch fails, so each signature is checked to find the bad one
+	signatures[5] = signatures[4]
+	for i, ok := range crypto.VerifyBatch(sp, hp, publicKeys, messages, signatures) {
+		if ok != (i != 5) {
+			t.Errorf("VerifyBatch()[%d] = %v, expected %v", i, ok, i != 5)
+		}
+	}
+
+	// mismatched lengths fail every signature
+	for i, ok := range crypto.VerifyBatch(sp, hp, publicKeys[1:], messages, signatures) {
+		if ok {
+			t.Errorf("VerifyBatch()[%d] = true, expected false", i)
+		}
+	}
+}
+
+func TestVerifyBatchFallback(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sp := mocks.NewMockSignaturePolicy(mockCtrl)
+	hp := mocks.NewMockHashPolicy(mockCtrl)
+
+	// policies without batch support verify every signature individually
+	sp.EXPECT().PublicKeySize().Return(len(publicKey)).AnyTimes()
+	sp.EXPECT().Verify(publicKey, hashed, signature).Return(true).Times(2)
+	hp.EXPECT().HashBytes(message).Return(hashed).Times(3)
+
+	results := crypto.VerifyBatch(
+		sp,
+		hp,
+		[][]byte{publicKey, publicKey, []byte{}},
+		[][]byte{message, message, message},
+		[][]byte{signature, signature, signature},
+	)
+	if !reflect.DeepEqual(results, []bool{true, true, false}) {
+		t.Errorf("VerifyBatch() = %v, expected [true true false]", results)
+	}
+}
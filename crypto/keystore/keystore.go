@@ -0,0 +1,397 @@
+// Package keystore saves node identities to passphrase-encrypted files.
+//
+// Every identity is stored in its own file named after it inside the keystore
+// directory. The private key is encrypted with AES-256-GCM under a key derived
+// from the passphrase with scrypt. The name, signature policy and public key
+// are kept in plain text so identities can be listed without a passphrase, but
+// they are authenticated together with the private key.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cocher/crypto"
+	"github.com/pkg/errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// StandardScryptN is the default scrypt CPU/memory cost, taking about one
+	// second and 256MB of memory on a modern processor.
+	StandardScryptN = 1 << 18
+	// StandardScryptP is the default scrypt parallelization parameter.
+	StandardScryptP = 1
+	// LightScryptN is a scrypt CPU/memory cost for constrained devices and tests.
+	LightScryptN = 1 << 12
+	// LightScryptP is the scrypt parallelization parameter matching LightScryptN.
+	LightScryptP = 6
+
+	version      = 1
+	fileExt      = ".key"
+	scryptR      = 8
+	scryptKeyLen = 32
+	saltSize     = 32
+	kdfName      = "scrypt"
+	cipherName   = "aes-256-gcm"
+)
+
+var (
+	// ErrNotFound is returned when no identity with the given name is stored.
+	ErrNotFound = errors.New("keystore: identity not found")
+	// ErrExists is returned when storing an identity under a name already in use.
+	ErrExists = errors.New("keystore: identity already exists")
+	// ErrDecrypt is returned when the passphrase is wrong or the file was tampered with.
+	ErrDecrypt = errors.New("keystore: could not decrypt key with given passphrase")
+	// ErrInvalidName is returned for names that can not be used as file names.
+	ErrInvalidName = errors.New("keystore: identity names may only contain letters, digits, '.', '-' and '_'")
+	// ErrKeyPairPolicy is returned when a key pair does not belong to the given signature policy.
+	ErrKeyPairPolicy = errors.New("keystore: key pair does not match the signature policy")
+	// ErrScryptParams is returned for scrypt parameters costlier than the standard ones.
+	ErrScryptParams = errors.New("keystore: scrypt parameters exceed the standard cost")
+
+	validName = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9_.-]*$`)
+)
+
+// Keystore stores named identities in a directory.
+type Keystore struct {
+	dir     string
+	scryptN int
+	scryptP int
+}
+
+// Option sets a parameter of the keystore.
+type Option func(*Keystore)
+
+// ScryptParams returns an Option that sets the scrypt parameters used when
+// storing new identities (default: StandardScryptN, StandardScryptP). Stored
+// identities keep the parameters they were encrypted with. Parameters costlier
+// than the standard ones are rejected, so that key files can not exhaust the
+// memory and CPU of the node loading them.
+func ScryptParams(n, p int) Option {
+	return func(ks *Keystore) {
+		ks.scryptN = n
+		ks.scryptP = p
+	}
+}
+
+// New returns a keystore backed by the given directory, which is created on
+// the first write if it does not exist.
+func New(dir string, opts ...Option) *Keystore {
+	ks := &Keystore{
+		dir:     dir,
+		scryptN: StandardScryptN,
+		scryptP: StandardScryptP,
+	}
+
+	for _, o := range opts {
+		o(ks)
+	}
+
+	return ks
+}
+
+// keyFile is the on-disk representation of an identity.
+type keyFile struct {
+	Version    int       `json:"version"`
+	Name       string    `json:"name"`
+	Policy     string    `json:"policy"`
+	PublicKey  string    `json:"public_key"`
+	KDF        kdfParams `json:"kdf"`
+	Cipher     string    `json:"cipher"`
+	Nonce      string    `json:"nonce"`
+	Ciphertext string    `json:"ciphertext"`
+}
+
+type kdfParams struct {
+	Name string `json:"name"`
+	Salt string `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// additionalData binds the plain text fields of the file to the ciphertext.
+func (f *keyFile) additionalData() []byte {
+	return []byte(strings.Join([]string{f.Name, f.Policy, f.PublicKey}, "\x00"))
+}
+
+// Store encrypts the key pair with the passphrase and saves it under the
+// given name, recording the signature policy it belongs to.
+func (ks *Keystore) Store(name string, sp crypto.SignaturePolicy, keys *crypto.KeyPair, passphrase string) error {
+	if !validName.MatchString(name) {
+		return ErrInvalidName
+	}
+
+	policy, err := PolicyName(sp)
+	if err != nil {
+		return err
+	}
+
+	if len(keys.PrivateKey) != sp.PrivateKeySize() || len(keys.PublicKey) != sp.PublicKeySize() {
+		return ErrKeyPairPolicy
+	}
+
+	if _, err := os.Stat(ks.path(name)); err == nil {
+		return ErrExists
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	f := &keyFile{
+		Version:   version,
+		Name:      name,
+		Policy:    policy,
+		PublicKey: hex.EncodeToString(keys.PublicKey),
+		KDF: kdfParams{
+			Name: kdfName,
+			Salt: hex.EncodeToString(salt),
+			N:    ks.scryptN,
+			R:    scryptR,
+			P:    ks.scryptP,
+		},
+		Cipher: cipherName,
+	}
+
+	aead, err := newAEAD(passphrase, salt, f.KDF)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	f.Nonce = hex.EncodeToString(nonce)
+	f.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, keys.PrivateKey, f.additionalData()))
+
+	raw, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ks.write(name, raw)
+}
+
+// Load decrypts the identity stored under the given name, returning its key
+// pair and signature policy.
+func (ks *Keystore) Load(name string, passphrase string) (*crypto.KeyPair, crypto.SignaturePolicy, error) {
+	f, err := ks.read(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if f.KDF.Name != kdfName || f.Cipher != cipherName {
+		return nil, nil, errors.Errorf("keystore: unsupported encryption %s/%s", f.KDF.Name, f.Cipher)
+	}
+
+	sp, err := Policy(f.Policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	salt, err := hex.DecodeString(f.KDF.Salt)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "keystore: invalid salt")
+	}
+	nonce, err := hex.DecodeString(f.Nonce)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "keystore: invalid nonce")
+	}
+	ciphertext, err := hex.DecodeString(f.Ciphertext)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "keystore: invalid ciphertext")
+	}
+
+	aead, err := newAEAD(passphrase, salt, f.KDF)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, nil, errors.New("keystore: invalid nonce")
+	}
+
+	privateKey, err := aead.Open(nil, nonce, ciphertext, f.additionalData())
+	if err != nil {
+		return nil, nil, ErrDecrypt
+	}
+
+	keys, err := crypto.FromPrivateKey(sp, hex.EncodeToString(privateKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if keys.PublicKeyHex() != f.PublicKey {
+		return nil, nil, ErrKeyPairPolicy
+	}
+
+	return keys, sp, nil
+}
+
+// LoadOrCreate loads the identity stored under the given name. If there is
+// none, a new key pair is generated with the signature policy and stored
+// encrypted with the passphrase.
+func (ks *Keystore) LoadOrCreate(name string, sp crypto.SignaturePolicy, passphrase string) (*crypto.KeyPair, crypto.SignaturePolicy, error) {
+	keys, policy, err := ks.Load(name, passphrase)
+	if err != ErrNotFound {
+		return keys, policy, err
+	}
+
+	keys = sp.RandomKeyPair()
+	if err := ks.Store(name, sp, keys, passphrase); err != nil {
+		return nil, nil, err
+	}
+
+	return keys, sp, nil
+}
+
+// PolicyOf returns the name of the signature policy recorded for an identity,
+// without decrypting it.
+func (ks *Keystore) PolicyOf(name string) (string, error) {
+	f, err := ks.read(name)
+	if err != nil {
+		return "", err
+	}
+	return f.Policy, nil
+}
+
+// List returns the names of all stored identities in lexical order.
+func (ks *Keystore) List() ([]string, error) {
+	files, err := ioutil.ReadDir(ks.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), fileExt)
+		if file.IsDir() || name == file.Name() || !validName.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete removes the identity stored under the given name.
+func (ks *Keystore) Delete(name string) error {
+	if !validName.MatchString(name) {
+		return ErrInvalidName
+	}
+
+	err := os.Remove(ks.path(name))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (ks *Keystore) path(name string) string {
+	return filepath.Join(ks.dir, name+fileExt)
+}
+
+func (ks *Keystore) read(name string) (*keyFile, error) {
+	if !validName.MatchString(name) {
+		return nil, ErrInvalidName
+	}
+
+	raw, err := ioutil.ReadFile(ks.path(name))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := new(keyFile)
+	if err := json.Unmarshal(raw, f); err != nil {
+		return nil, errors.Wrapf(err, "keystore: invalid key file for %s", name)
+	}
+	if f.Version != version {
+		return nil, errors.Errorf("keystore: unsupported key file version %d", f.Version)
+	}
+	if f.Name != name {
+		return nil, errors.Errorf("keystore: key file for %s holds identity %s", name, f.Name)
+	}
+
+	return f, nil
+}
+
+// write atomically creates the key file, readable only by the current user.
+func (ks *Keystore) write(name string, raw []byte) error {
+	if err := os.MkdirAll(ks.dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(ks.dir, "."+name+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	// Link fails if the name was taken in the meantime, unlike rename.
+	err = os.Link(tmp.Name(), ks.path(name))
+	os.Remove(tmp.Name())
+	if os.IsExist(err) {
+		return ErrExists
+	}
+	return err
+}
+
+// checkCost rejects scrypt parameters which take more memory or CPU than
+// the standard ones.
+func (p kdfParams) checkCost() error {
+	if p.N <= 0 || p.R <= 0 || p.P <= 0 {
+		return errors.New("keystore: invalid scrypt parameters")
+	}
+	if p.N > StandardScryptN || p.R > scryptR || p.P > StandardScryptN/p.N*StandardScryptP {
+		return ErrScryptParams
+	}
+	return nil
+}
+
+func newAEAD(passphrase string, salt []byte, params kdfParams) (cipher.AEAD, error) {
+	if err := params.checkCost(); err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, scryptKeyLen)
+	if err != nil {
+		return nil, errors.Wrap(err, "keystore: invalid scrypt parameters")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,203 @@
+package keystore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cocher/crypto"
+	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/crypto/secp256k1"
+	"github.com/cocher/crypto/sm2"
+)
+
+func newTestKeystore(t *testing.T) (*Keystore, func()) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(dir, ScryptParams(LightScryptN, LightScryptP)), func() { os.RemoveAll(dir) }
+}
+
+func TestStoreLoad(t *testing.T) {
+	t.Parallel()
+
+	ks, cleanup := newTestKeystore(t)
+	defer cleanup()
+
+	for name, sp := range map[string]crypto.SignaturePolicy{
+		"ed25519":   ed25519.New(),
+		"secp256k1": secp256k1.New(),
+		"sm2":       sm2.New(),
+	} {
+		keys := sp.RandomKeyPair()
+		if err := ks.Store(name, sp, keys, "passphrase"); err != nil {
+			t.Fatalf("Store(%s) = %v, want <nil>", name, err)
+		}
+
+		loaded, policy, err := ks.Load(name, "passphrase")
+		if err != nil {
+			t.Fatalf("Load(%s) = %v, want <nil>", name, err)
+		}
+		if !reflect.DeepEqual(loaded, keys) {
+			t.Errorf("Load(%s) = %v, want %v", name, loaded, keys)
+		}
+		if reflect.TypeOf(policy) != reflect.TypeOf(sp) {
+			t.Errorf("Load(%s) policy = %T, want %T", name, policy, sp)
+		}
+
+		recorded, err := ks.PolicyOf(name)
+		if err != nil || recorded != name {
+			t.Errorf("PolicyOf(%s) = %s, %v, want %s, <nil>", name, recorded, err, name)
+		}
+	}
+
+	names, err := ks.List()
+	if err != nil {
+		t.Fatalf("List() = %v, want <nil>", err)
+	}
+	if want := []string{"ed25519", "secp256k1", "sm2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+}
+
+func TestStoreErrors(t *testing.T) {
+	t.Parallel()
+
+	ks, cleanup := newTestKeystore(t)
+	defer cleanup()
+
+	sp := ed25519.New()
+	keys := sp.RandomKeyPair()
+
+	if err := ks.Store("node", sp, keys, "passphrase"); err != nil {
+		t.Fatalf("Store() = %v, want <nil>", err)
+	}
+	if err := ks.Store("node", sp, sp.RandomKeyPair(), "passphrase"); err != ErrExists {
+		t.Errorf("Store() of an existing identity = %v, want %v", err, ErrExists)
+	}
+
+	for _, name := range []string{"", "../node", "a/b", ".hidden"} {
+		if err := ks.Store(name, sp, keys, "passphrase"); err != ErrInvalidName {
+			t.Errorf("Store(%q) = %v, want %v", name, err, ErrInvalidName)
+		}
+	}
+
+	if err := ks.Store("mismatch", secp256k1.New(), keys, "passphrase"); err != ErrKeyPairPolicy {
+		t.Errorf("Store() with keys of another policy = %v, want %v", err, ErrKeyPairPolicy)
+	}
+
+	if err := ks.Store("unknown", &ed25519Wrapper{sp}, keys, "passphrase"); err == nil {
+		t.Errorf("Store() with an unregistered policy = <nil>, want error")
+	}
+}
+
+type ed25519Wrapper struct {
+	*ed25519.Ed25519
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	ks, cleanup := newTestKeystore(t)
+	defer cleanup()
+
+	if _, _, err := ks.Load("missing", "passphrase"); err != ErrNotFound {
+		t.Errorf("Load() of a missing identity = %v, want %v", err, ErrNotFound)
+	}
+
+	sp := ed25519.New()
+	if err := ks.Store("node", sp, sp.RandomKeyPair(), "passphrase"); err != nil {
+		t.Fatalf("Store() = %v, want <nil>", err)
+	}
+
+	if _, _, err := ks.Load("node", "wrong passphrase"); err != ErrDecrypt {
+		t.Errorf("Load() with a wrong passphrase = %v, want %v", err, ErrDecrypt)
+	}
+
+	// the recorded policy is authenticated along with the private key
+	path := filepath.Join(ks.dir, "node"+fileExt)
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := new(keyFile)
+	if err := json.Unmarshal(raw, f); err != nil {
+		t.Fatal(err)
+	}
+	f.Policy = "sm2"
+	raw, _ = json.Marshal(f)
+	if err := ioutil.WriteFile(path, raw, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ks.Load("node", "passphrase"); err != ErrDecrypt {
+		t.Errorf("Load() of a tampered file = %v, want %v", err, ErrDecrypt)
+	}
+
+	// scrypt parameters are bounded before deriving the key
+	for _, params := range [][3]int{{StandardScryptN * 2, scryptR, 1}, {StandardScryptN, scryptR * 2, 1}, {StandardScryptN, scryptR, 2}} {
+		f.KDF.N, f.KDF.R, f.KDF.P = params[0], params[1], params[2]
+		raw, _ = json.Marshal(f)
+		if err := ioutil.WriteFile(path, raw, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := ks.Load("node", "passphrase"); err != ErrScryptParams {
+			t.Errorf("Load() with scrypt parameters %v = %v, want %v", params, err, ErrScryptParams)
+		}
+	}
+}
+
+func TestLoadOrCreate(t *testing.T) {
+	t.Parallel()
+
+	ks, cleanup := newTestKeystore(t)
+	defer cleanup()
+
+	created, _, err := ks.LoadOrCreate("node", secp256k1.New(), "passphrase")
+	if err != nil {
+		t.Fatalf("LoadOrCreate() = %v, want <nil>", err)
+	}
+
+	// the stored policy wins over the one given for new identities
+	loaded, policy, err := ks.LoadOrCreate("node", ed25519.New(), "passphrase")
+	if err != nil {
+		t.Fatalf("LoadOrCreate() = %v, want <nil>", err)
+	}
+	if !reflect.DeepEqual(loaded, created) {
+		t.Errorf("LoadOrCreate() = %v, want %v", loaded, created)
+	}
+	if _, ok := policy.(*secp256k1.Secp256k1); !ok {
+		t.Errorf("LoadOrCreate() policy = %T, want *secp256k1.Secp256k1", policy)
+	}
+
+	if _, _, err := ks.LoadOrCreate("node", secp256k1.New(), "wrong passphrase"); err != ErrDecrypt {
+		t.Errorf("LoadOrCreate() with a wrong passphrase = %v, want %v", err, ErrDecrypt)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	ks, cleanup := newTestKeystore(t)
+	defer cleanup()
+
+	if names, err := ks.List(); err != nil || len(names) != 0 {
+		t.Errorf("List() = %v, %v, want [], <nil>", names, err)
+	}
+
+	sp := ed25519.New()
+	if err := ks.Store("node", sp, sp.RandomKeyPair(), "passphrase"); err != nil {
+		t.Fatalf("Store() = %v, want <nil>", err)
+	}
+	if err := ks.Delete("node"); err != nil {
+		t.Errorf("Delete() = %v, want <nil>", err)
+	}
+	if err := ks.Delete("node"); err != ErrNotFound {
+		t.Errorf("Delete() of a missing identity = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,58 @@
+package keystore
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/cocher/crypto"
+	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/crypto/secp256k1"
+	"github.com/cocher/crypto/sm2"
+	"github.com/pkg/errors"
+)
+
+var (
+	policiesMutex sync.RWMutex
+	policies      = map[string]func() crypto.SignaturePolicy{
+		"ed25519":   func() crypto.SignaturePolicy { return ed25519.New() },
+		"secp256k1": func() crypto.SignaturePolicy { return secp256k1.New() },
+		"sm2":       func() crypto.SignaturePolicy { return sm2.New() },
+	}
+)
+
+// RegisterPolicy makes a signature policy known to keystores under the given
+// name, so that identities using it can be stored and loaded. The in-tree
+// ed25519, secp256k1 and sm2 policies are registered by default.
+func RegisterPolicy(name string, newPolicy func() crypto.SignaturePolicy) {
+	policiesMutex.Lock()
+	defer policiesMutex.Unlock()
+
+	policies[name] = newPolicy
+}
+
+// Policy returns a new instance of the signature policy registered under the
+// given name.
+func Policy(name string) (crypto.SignaturePolicy, error) {
+	policiesMutex.RLock()
+	defer policiesMutex.RUnlock()
+
+	newPolicy, ok := policies[name]
+	if !ok {
+		return nil, errors.Errorf("keystore: unknown signature policy %s", name)
+	}
+	return newPolicy(), nil
+}
+
+// PolicyName returns the name a signature policy was registered under.
+func PolicyName(sp crypto.SignaturePolicy) (string, error) {
+	policiesMutex.RLock()
+	defer policiesMutex.RUnlock()
+
+	t := reflect.TypeOf(sp)
+	for name, newPolicy := range policies {
+		if reflect.TypeOf(newPolicy()) == t {
+			return name, nil
+		}
+	}
+	return "", errors.Errorf("keystore: signature policy %s is not registered", t)
+}
@@ -18,6 +18,13 @@ type SignaturePolicy interface {
 	Verify(publicKey []byte, message []byte, signature []byte) bool
 }
 
+// BatchVerifier is an optional interface a SignaturePolicy may implement when it
+// can validate many signatures at once faster than one by one.
+type BatchVerifier interface {
+	// BatchVerify returns true only if every signature was signed using the public key and message at the same index.
+	BatchVerify(publicKeys [][]byte, messages [][]byte, signatures [][]byte) bool
+}
+
 // HashPolicy defines how to create a cryptographic hash.
 type HashPolicy interface {
 	HashBytes(b []byte) []byte
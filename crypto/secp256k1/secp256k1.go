@@ -0,0 +1,191 @@
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/cocher/crypto"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PrivateKeySize is the size, in bytes, of private keys as used in this package.
+	PrivateKeySize = 32
+	// PublicKeySize is the size, in bytes, of compressed public keys as used in this package.
+	PublicKeySize = btcec.PubKeyBytesLenCompressed
+	// SignatureSize is the size, in bytes, of recoverable signatures ([R || S || V]) generated by this package.
+	SignatureSize = 65
+	// DigestSize is the size, in bytes, of a message digest that is signed as-is.
+	DigestSize = 32
+)
+
+var (
+	// ErrInvalidSignature returns if a signature is malformed or does not recover a public key.
+	ErrInvalidSignature = errors.New("secp256k1: invalid signature")
+
+	halfOrder = new(big.Int).Rsh(btcec.S256().N, 1)
+)
+
+// Secp256k1 represents the secp256k1 ECDSA signature scheme.
+type Secp256k1 struct {
+}
+
+var (
+	_ crypto.SignaturePolicy = (*Secp256k1)(nil)
+)
+
+// New returns a Secp256k1 structure.
+func New() *Secp256k1 {
+	return &Secp256k1{}
+}
+
+// GenerateKeys generates a private and public key using the secp256k1 signature scheme.
+func (p *Secp256k1) GenerateKeys() ([]byte, []byte, error) {
+	privateKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		return nil, nil, err
+	}
+	return serializePrivateKey(privateKey), privateKey.PubKey().SerializeCompressed(), nil
+}
+
+// PrivateKeySize returns the private key length.
+func (p *Secp256k1) PrivateKeySize() int {
+	return PrivateKeySize
+}
+
+// PrivateToPublic returns the compressed public key given the private key.
+func (p *Secp256k1) PrivateToPublic(privateKey []byte) ([]byte, error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, crypto.PrivateKeySizeErr
+	}
+	_, publicKey := btcec.PrivKeyFromBytes(btcec.S256(), privateKey)
+	return publicKey.SerializeCompressed(), nil
+}
+
+// PublicKeySize returns the public key length.
+func (p *Secp256k1) PublicKeySize() int {
+	return PublicKeySize
+}
+
+// RandomKeyPair generates a randomly seeded secp256k1 key pair.
+func (p *Secp256k1) RandomKeyPair() *crypto.KeyPair {
+	return RandomKeyPair()
+}
+
+// Sign returns a recoverable secp256k1 signature given a private key and message.
+func (p *Secp256k1) Sign(privateKey []byte, message []byte) []byte {
+	if len(privateKey) != PrivateKeySize {
+		return make([]byte, 0)
+	}
+	return Sign(privateKey, message)
+}
+
+// Verify returns true if the signature was signed using the given public key and message.
+func (p *Secp256k1) Verify(publicKey []byte, message []byte, signature []byte) bool {
+	if len(publicKey) != PublicKeySize {
+		return false
+	}
+	return Verify(publicKey, message, signature)
+}
+
+// RecoverPublicKey returns the compressed public key that produced a recoverable signature over message.
+func (p *Secp256k1) RecoverPublicKey(message []byte, signature []byte) ([]byte, error) {
+	return RecoverPublicKey(message, signature)
+}
+
+// RandomKeyPair generates a randomly seeded secp256k1 key pair.
+func RandomKeyPair() *crypto.KeyPair {
+	privateKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		panic(err)
+	}
+	return &crypto.KeyPair{
+		PublicKey:  privateKey.PubKey().SerializeCompressed(),
+		PrivateKey: serializePrivateKey(privateKey),
+	}
+}
+
+// Sign signs the digest of message with privateKey using deterministic (RFC6979) nonces.
+// The signature is returned in the 65-byte [R || S || V] format, where V is the recovery id.
+// Messages that are not 32 bytes long are hashed with SHA-256 before signing.
+func Sign(privateKey []byte, message []byte) []byte {
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), privateKey)
+
+	compact, err := btcec.SignCompact(btcec.S256(), key, digest(message), true)
+	if err != nil {
+		return make([]byte, 0)
+	}
+
+	// Move the recovery header from the front of the compact signature to the back.
+	signature := make([]byte, SignatureSize)
+	copy(signature, compact[1:])
+	signature[SignatureSize-1] = (compact[0] - 27) & 3
+
+	return signature
+}
+
+// Verify reports whether signature is a valid signature of message by publicKey.
+// Both 64-byte [R || S] and 65-byte [R || S || V] signatures are accepted. Signatures
+// with a high S value are rejected to prevent malleability.
+func Verify(publicKey []byte, message []byte, signature []byte) bool {
+	if len(signature) != SignatureSize && len(signature) != SignatureSize-1 {
+		return false
+	}
+
+	key, err := btcec.ParsePubKey(publicKey, btcec.S256())
+	if err != nil {
+		return false
+	}
+
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	if r.Sign() == 0 || s.Sign() == 0 || r.Cmp(btcec.S256().N) >= 0 || s.Cmp(halfOrder) > 0 {
+		return false
+	}
+
+	sig := &btcec.Signature{R: r, S: s}
+	return sig.Verify(digest(message), key)
+}
+
+// RecoverPublicKey returns the compressed public key that produced a 65-byte [R || S || V]
+// signature over message.
+func RecoverPublicKey(message []byte, signature []byte) ([]byte, error) {
+	if len(signature) != SignatureSize || signature[SignatureSize-1] > 3 {
+		return nil, ErrInvalidSignature
+	}
+
+	s := new(big.Int).SetBytes(signature[32:64])
+	if s.Cmp(halfOrder) > 0 {
+		return nil, ErrInvalidSignature
+	}
+
+	compact := make([]byte, SignatureSize)
+	compact[0] = 27 + 4 + signature[SignatureSize-1]
+	copy(compact[1:], signature[:SignatureSize-1])
+
+	key, _, err := btcec.RecoverCompact(btcec.S256(), compact, digest(message))
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+
+	return key.SerializeCompressed(), nil
+}
+
+// digest returns message unchanged if it is already a 32-byte digest, or its SHA-256 hash otherwise.
+func digest(message []byte) []byte {
+	if len(message) == DigestSize {
+		return message
+	}
+	hashed := sha256.Sum256(message)
+	return hashed[:]
+}
+
+// serializePrivateKey returns the private key scalar left-padded to PrivateKeySize bytes.
+func serializePrivateKey(key *btcec.PrivateKey) []byte {
+	raw := key.D.Bytes()
+	out := make([]byte, PrivateKeySize)
+	copy(out[PrivateKeySize-len(raw):], raw)
+	return out
+}
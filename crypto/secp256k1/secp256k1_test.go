@@ -0,0 +1,280 @@
+package secp256k1
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+func BenchmarkSign(b *testing.B) {
+	p := New()
+	privateKey, _, err := p.GenerateKeys()
+	if err != nil {
+		panic(err)
+	}
+
+	message := make([]byte, 32)
+	_, err = rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sig := p.Sign(privateKey, message)
+		if len(sig) == 0 {
+			panic("signing failed")
+		}
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	p := New()
+	privateKey, publicKey, err := p.GenerateKeys()
+	if err != nil {
+		panic(err)
+	}
+
+	message := make([]byte, 32)
+	_, err = rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	sig := p.Sign(privateKey, message)
+
+	for i := 0; i < b.N; i++ {
+		ok := p.Verify(publicKey, message, sig)
+		if !ok {
+			panic("verification failed")
+		}
+	}
+}
+
+func BenchmarkRecoverPublicKey(b *testing.B) {
+	p := New()
+	privateKey, _, err := p.GenerateKeys()
+	if err != nil {
+		panic(err)
+	}
+
+	message := make([]byte, 32)
+	_, err = rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	sig := p.Sign(privateKey, message)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := p.RecoverPublicKey(message, sig); err != nil {
+			panic("recovery failed")
+		}
+	}
+}
+
+func TestSecp256k1(t *testing.T) {
+	t.Parallel()
+	p := New()
+
+	privateKey, publicKey, err := p.GenerateKeys()
+	if err != nil {
+		t.Errorf("GenerateKeys() = %v, want <nil>", err)
+	}
+	if len(privateKey) != p.PrivateKeySize() {
+		t.Errorf("PrivateKeySize() = %d, want %d", len(privateKey), p.PrivateKeySize())
+	}
+	if len(publicKey) != p.PublicKeySize() {
+		t.Errorf("PublicKeySize() = %d, want %d", len(publicKey), p.PublicKeySize())
+	}
+
+	message := []byte("test message")
+	// sign with a bad key should have yield signature with 0 length
+	sig := p.Sign([]byte("bad key"), message)
+	if len(sig) != 0 {
+		t.Errorf("Sign(%s) message length should be 0", message)
+	}
+
+	// length of signature should not be 0
+	sig = p.Sign(privateKey, message)
+	if len(sig) != SignatureSize {
+		t.Errorf("Sign(%s) message length is %d, want %d", message, len(sig), SignatureSize)
+	}
+
+	// correct message should pass verify check
+	if verify := p.Verify(publicKey, message, sig); !verify {
+		t.Errorf("Verify(%s, %b) = %v, want true", message, sig, verify)
+	}
+
+	// signature without the recovery id should pass verify check
+	if verify := p.Verify(publicKey, message, sig[:SignatureSize-1]); !verify {
+		t.Errorf("Verify(%s, %b) = %v, want true", message, sig[:SignatureSize-1], verify)
+	}
+
+	// wrong public key should fail verify check
+	if verify := p.Verify([]byte("bad key"), message, sig); verify {
+		t.Errorf("Verify(%s, %b) = %v, want false", message, sig, verify)
+	}
+
+	// wrong message should fail verify check
+	wrongMessage := []byte("wrong message")
+	if verify := p.Verify(publicKey, wrongMessage, sig); verify {
+		t.Errorf("Verify(%s, %b) = %v, want false", wrongMessage, sig, verify)
+	}
+
+	publicKeyCheck, err := p.PrivateToPublic(privateKey)
+	if err != nil {
+		t.Errorf("privateToPublic() = %v, want <nil>", err)
+	}
+	if !reflect.DeepEqual(publicKeyCheck, publicKey) {
+		t.Errorf("PrivateToPublic() = %v, want %v", publicKeyCheck, publicKey)
+	}
+
+	recovered, err := p.RecoverPublicKey(message, sig)
+	if err != nil {
+		t.Errorf("RecoverPublicKey() = %v, want <nil>", err)
+	}
+	if !bytes.Equal(recovered, publicKey) {
+		t.Errorf("RecoverPublicKey() = %x, want %x", recovered, publicKey)
+	}
+
+	// recovering from the wrong message should yield a different key
+	recovered, err = p.RecoverPublicKey(wrongMessage, sig)
+	if err == nil && bytes.Equal(recovered, publicKey) {
+		t.Errorf("RecoverPublicKey(%s) recovered the signer's key", wrongMessage)
+	}
+}
+
+func TestMalleability(t *testing.T) {
+	t.Parallel()
+	p := New()
+
+	privateKey, publicKey, err := p.GenerateKeys()
+	if err != nil {
+		t.Fatalf("GenerateKeys() = %v, want <nil>", err)
+	}
+
+	message := []byte("test message")
+	sig := p.Sign(privateKey, message)
+
+	// Flip S to N - S: the resulting signature is mathematically valid but must be rejected.
+	malleated := make([]byte, len(sig))
+	copy(malleated, sig)
+	highS := new(big.Int).Sub(btcec.S256().N, new(big.Int).SetBytes(sig[32:64])).Bytes()
+	copy(malleated[64-len(highS):64], highS)
+	malleated[SignatureSize-1] ^= 1
+
+	if p.Verify(publicKey, message, malleated) {
+		t.Errorf("Verify() accepted a high-S signature")
+	}
+	if _, err := p.RecoverPublicKey(message, malleated); err == nil {
+		t.Errorf("RecoverPublicKey() accepted a high-S signature")
+	}
+}
+
+func TestRFC6979(t *testing.T) {
+	t.Parallel()
+
+	// Well-known deterministic signature of "Satoshi Nakamoto" by private key 1.
+	privateKey, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000001")
+	expected, _ := hex.DecodeString("934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8" +
+		"2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5")
+
+	digest := sha256.Sum256([]byte("Satoshi Nakamoto"))
+	sig := Sign(privateKey, digest[:])
+	if !bytes.Equal(sig[:SignatureSize-1], expected) {
+		t.Errorf("Sign() = %x, want %x", sig[:SignatureSize-1], expected)
+	}
+
+	// Messages that are not 32-byte digests are hashed with SHA-256 first.
+	if !bytes.Equal(Sign(privateKey, []byte("Satoshi Nakamoto")), sig) {
+		t.Errorf("Sign() of the raw message does not match the signature of its SHA-256 digest")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	// sign.input.gz holds lines of private key, compressed public key, 32-byte
+	// digest and [R || S || V] signature, cross-checked against an independent
+	// RFC6979 secp256k1 implementation.
+	testDataZ, err := os.Open("testdata/sign.input.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDataZ.Close()
+	testData, err := gzip.NewReader(testDataZ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testData.Close()
+
+	scanner := bufio.NewScanner(testData)
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+
+		line := scanner.Text()
+		parts := strings.Split(line, ":")
+		if len(parts) != 4 {
+			t.Fatalf("bad number of parts on line %d", lineNo)
+		}
+
+		privateKey, _ := hex.DecodeString(parts[0])
+		publicKey, _ := hex.DecodeString(parts[1])
+		msg, _ := hex.DecodeString(parts[2])
+		expectedSig, _ := hex.DecodeString(parts[3])
+
+		publicKeyCheck, err := New().PrivateToPublic(privateKey)
+		if err != nil {
+			t.Fatalf("PrivateToPublic() = %v on line %d", err, lineNo)
+		}
+		if !bytes.Equal(publicKeyCheck, publicKey) {
+			t.Errorf("different public key on line %d: got %x, expected %x", lineNo, publicKeyCheck, publicKey)
+		}
+
+		sig := Sign(privateKey, msg)
+		if !bytes.Equal(sig, expectedSig) {
+			t.Errorf("different signature result on line %d: %x vs %x", lineNo, sig, expectedSig)
+		}
+
+		if !Verify(publicKey, msg, sig) {
+			t.Errorf("signature failed to verify on line %d", lineNo)
+		}
+
+		recovered, err := RecoverPublicKey(msg, sig)
+		if err != nil || !bytes.Equal(recovered, publicKey) {
+			t.Errorf("failed to recover public key on line %d: %x, %v", lineNo, recovered, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("error reading test data: %s", err)
+	}
+}
+
+func TestRandomKeyPair(t *testing.T) {
+	t.Parallel()
+
+	kp := New().RandomKeyPair()
+	if len(kp.PrivateKey) != PrivateKeySize {
+		t.Errorf("private key length should be %d", PrivateKeySize)
+	}
+	if len(kp.PublicKey) != PublicKeySize {
+		t.Errorf("public key length should be %d", PublicKeySize)
+	}
+}
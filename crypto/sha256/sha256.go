@@ -0,0 +1,25 @@
+package sha256
+
+import (
+	sha256lib "crypto/sha256"
+
+	"github.com/cocher/crypto"
+)
+
+// SHA256 represents the SHA-256 (FIPS 180-4) cryptographic hash algorithm.
+type SHA256 struct{}
+
+var (
+	_ crypto.HashPolicy = (*SHA256)(nil)
+)
+
+// New returns a SHA-256 hash policy.
+func New() *SHA256 {
+	return &SHA256{}
+}
+
+// HashBytes hashes the given bytes using the SHA-256 hash algorithm.
+func (p *SHA256) HashBytes(bytes []byte) []byte {
+	result := sha256lib.Sum256(bytes)
+	return result[:]
+}
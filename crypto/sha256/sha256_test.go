@@ -0,0 +1,65 @@
+package sha256
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/cocher/crypto"
+)
+
+func BenchmarkHash(b *testing.B) {
+	hp := New()
+
+	message := make([]byte, 64)
+	_, err := rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		hp.HashBytes(message)
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+	hp := New()
+
+	r := crypto.Hash(hp, big.NewInt(123))
+
+	n := new(big.Int)
+	n, ok := n.SetString("960651239372262155457909151379123018551187498045146330307848435673183387030", 10)
+	if !ok {
+		t.Errorf("big int error")
+	}
+	if n.String() != r.String() {
+		t.Errorf("String() n = %v, want %v", n, r)
+	}
+}
+
+func TestHashBytes(t *testing.T) {
+	t.Parallel()
+	hp := New()
+
+	// Examples from FIPS 180-4 and NIST CSRC example values.
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+
+	for _, tt := range testCases {
+		expected, _ := hex.DecodeString(tt.expected)
+		r := hp.HashBytes([]byte(tt.input))
+		if !bytes.Equal(expected, r) {
+			t.Errorf("HashBytes(%q) = %x, want %x", tt.input, r, expected)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package sha3
+
+import (
+	"github.com/cocher/crypto"
+
+	sha3lib "golang.org/x/crypto/sha3"
+)
+
+// SHA3 represents the SHA3-256 (FIPS 202) cryptographic hash algorithm.
+type SHA3 struct{}
+
+var (
+	_ crypto.HashPolicy = (*SHA3)(nil)
+)
+
+// New returns a SHA3-256 hash policy.
+func New() *SHA3 {
+	return &SHA3{}
+}
+
+// HashBytes hashes the given bytes using the SHA3-256 hash algorithm.
+func (p *SHA3) HashBytes(bytes []byte) []byte {
+	result := sha3lib.Sum256(bytes)
+	return result[:]
+}
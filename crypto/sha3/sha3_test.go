@@ -0,0 +1,65 @@
+package sha3
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/cocher/crypto"
+)
+
+func BenchmarkHash(b *testing.B) {
+	hp := New()
+
+	message := make([]byte, 64)
+	_, err := rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		hp.HashBytes(message)
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+	hp := New()
+
+	r := crypto.Hash(hp, big.NewInt(123))
+
+	n := new(big.Int)
+	n, ok := n.SetString("114799824452767007864378841586722540595178560009474170954719857124825518105385", 10)
+	if !ok {
+		t.Errorf("big int error")
+	}
+	if n.String() != r.String() {
+		t.Errorf("String() n = %v, want %v", n, r)
+	}
+}
+
+func TestHashBytes(t *testing.T) {
+	t.Parallel()
+	hp := New()
+
+	// Examples from the NIST CSRC SHA3-256 example values.
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"", "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"},
+		{"abc", "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+	}
+
+	for _, tt := range testCases {
+		expected, _ := hex.DecodeString(tt.expected)
+		r := hp.HashBytes([]byte(tt.input))
+		if !bytes.Equal(expected, r) {
+			t.Errorf("HashBytes(%q) = %x, want %x", tt.input, r, expected)
+		}
+	}
+}
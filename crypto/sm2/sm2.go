@@ -0,0 +1,148 @@
+package sm2
+
+import (
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/cocher/crypto"
+
+	gmsm2 "github.com/tjfoc/gmsm/sm2"
+)
+
+const (
+	// PrivateKeySize is the size, in bytes, of private keys as used in this package.
+	PrivateKeySize = 32
+	// PublicKeySize is the size, in bytes, of uncompressed public keys (0x04 || X || Y) as used in this package.
+	PublicKeySize = 65
+	// SignatureSize is the size, in bytes, of signatures ([R || S]) generated by this package.
+	SignatureSize = 64
+)
+
+// SM2 represents the SM2 (GB/T 32918) cryptographic signature scheme.
+type SM2 struct {
+	// UID is the distinguishing identifier hashed into every signature. Defaults to "1234567812345678".
+	UID []byte
+}
+
+var (
+	_ crypto.SignaturePolicy = (*SM2)(nil)
+)
+
+// New returns a SM2 structure using the default distinguishing identifier.
+func New() *SM2 {
+	return &SM2{}
+}
+
+// GenerateKeys generates a private and public key using the SM2 signature scheme.
+func (p *SM2) GenerateKeys() ([]byte, []byte, error) {
+	key, err := gmsm2.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return marshalPrivateKey(key), marshalPublicKey(&key.PublicKey), nil
+}
+
+// PrivateKeySize returns the private key length.
+func (p *SM2) PrivateKeySize() int {
+	return PrivateKeySize
+}
+
+// PrivateToPublic returns the public key given the private key.
+func (p *SM2) PrivateToPublic(privateKey []byte) ([]byte, error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, crypto.PrivateKeySizeErr
+	}
+	return marshalPublicKey(&unmarshalPrivateKey(privateKey).PublicKey), nil
+}
+
+// PublicKeySize returns the public key length.
+func (p *SM2) PublicKeySize() int {
+	return PublicKeySize
+}
+
+// RandomKeyPair generates a randomly seeded SM2 key pair.
+func (p *SM2) RandomKeyPair() *crypto.KeyPair {
+	return RandomKeyPair()
+}
+
+// Sign returns an SM2-signed message given a private key and message.
+func (p *SM2) Sign(privateKey []byte, message []byte) []byte {
+	if len(privateKey) != PrivateKeySize {
+		return make([]byte, 0)
+	}
+
+	r, s, err := gmsm2.Sm2Sign(unmarshalPrivateKey(privateKey), message, p.UID)
+	if err != nil {
+		return make([]byte, 0)
+	}
+
+	signature := make([]byte, 0, SignatureSize)
+	signature = append(signature, paddedBytes(r, SignatureSize/2)...)
+	signature = append(signature, paddedBytes(s, SignatureSize/2)...)
+	return signature
+}
+
+// Verify returns true if the signature was signed using the given public key and message.
+func (p *SM2) Verify(publicKey []byte, message []byte, signature []byte) bool {
+	if len(publicKey) != PublicKeySize || len(signature) != SignatureSize {
+		return false
+	}
+
+	key := unmarshalPublicKey(publicKey)
+	if key == nil {
+		return false
+	}
+
+	r := new(big.Int).SetBytes(signature[:SignatureSize/2])
+	s := new(big.Int).SetBytes(signature[SignatureSize/2:])
+	return gmsm2.Sm2Verify(key, message, p.UID, r, s)
+}
+
+// RandomKeyPair generates a randomly seeded SM2 key pair.
+func RandomKeyPair() *crypto.KeyPair {
+	key, err := gmsm2.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	return &crypto.KeyPair{
+		PublicKey:  marshalPublicKey(&key.PublicKey),
+		PrivateKey: marshalPrivateKey(key),
+	}
+}
+
+func marshalPrivateKey(key *gmsm2.PrivateKey) []byte {
+	return paddedBytes(key.D, PrivateKeySize)
+}
+
+func unmarshalPrivateKey(privateKey []byte) *gmsm2.PrivateKey {
+	curve := gmsm2.P256Sm2()
+
+	key := new(gmsm2.PrivateKey)
+	key.PublicKey.Curve = curve
+	key.D = new(big.Int).SetBytes(privateKey)
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(privateKey)
+	return key
+}
+
+func marshalPublicKey(key *gmsm2.PublicKey) []byte {
+	return elliptic.Marshal(key.Curve, key.X, key.Y)
+}
+
+// unmarshalPublicKey returns nil if publicKey is not a point on the SM2 curve.
+func unmarshalPublicKey(publicKey []byte) *gmsm2.PublicKey {
+	curve := gmsm2.P256Sm2()
+
+	x, y := elliptic.Unmarshal(curve, publicKey)
+	if x == nil {
+		return nil
+	}
+	return &gmsm2.PublicKey{Curve: curve, X: x, Y: y}
+}
+
+// paddedBytes returns the big-endian bytes of n left-padded with zeros to size bytes.
+func paddedBytes(n *big.Int, size int) []byte {
+	raw := n.Bytes()
+	out := make([]byte, size)
+	copy(out[size-len(raw):], raw)
+	return out
+}
@@ -0,0 +1,172 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func BenchmarkSign(b *testing.B) {
+	p := New()
+	privateKey, _, err := p.GenerateKeys()
+	if err != nil {
+		panic(err)
+	}
+
+	message := make([]byte, 32)
+	_, err = rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sig := p.Sign(privateKey, message)
+		if len(sig) == 0 {
+			panic("signing failed")
+		}
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	p := New()
+	privateKey, publicKey, err := p.GenerateKeys()
+	if err != nil {
+		panic(err)
+	}
+
+	message := make([]byte, 32)
+	_, err = rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	sig := p.Sign(privateKey, message)
+
+	for i := 0; i < b.N; i++ {
+		ok := p.Verify(publicKey, message, sig)
+		if !ok {
+			panic("verification failed")
+		}
+	}
+}
+
+func TestSM2(t *testing.T) {
+	t.Parallel()
+	p := New()
+
+	privateKey, publicKey, err := p.GenerateKeys()
+	if err != nil {
+		t.Errorf("GenerateKeys() = %v, want <nil>", err)
+	}
+	if len(privateKey) != p.PrivateKeySize() {
+		t.Errorf("PrivateKeySize() = %d, want %d", len(privateKey), p.PrivateKeySize())
+	}
+	if len(publicKey) != p.PublicKeySize() {
+		t.Errorf("PublicKeySize() = %d, want %d", len(publicKey), p.PublicKeySize())
+	}
+
+	message := []byte("test message")
+	// sign with a bad key should have yield signature with 0 length
+	sig := p.Sign([]byte("bad key"), message)
+	if len(sig) != 0 {
+		t.Errorf("Sign(%s) message length should be 0", message)
+	}
+
+	// length of signature should not be 0
+	sig = p.Sign(privateKey, message)
+	if len(sig) != SignatureSize {
+		t.Errorf("Sign(%s) message length is %d, want %d", message, len(sig), SignatureSize)
+	}
+
+	// correct message should pass verify check
+	if verify := p.Verify(publicKey, message, sig); !verify {
+		t.Errorf("Verify(%s, %b) = %v, want true", message, sig, verify)
+	}
+
+	// wrong public key should fail verify check
+	if verify := p.Verify([]byte("bad key"), message, sig); verify {
+		t.Errorf("Verify(%s, %b) = %v, want false", message, sig, verify)
+	}
+
+	// public key that is not on the curve should fail verify check
+	offCurve := make([]byte, PublicKeySize)
+	copy(offCurve, publicKey)
+	offCurve[PublicKeySize-1] ^= 1
+	if verify := p.Verify(offCurve, message, sig); verify {
+		t.Errorf("Verify(%s, %b) = %v, want false", message, sig, verify)
+	}
+
+	// wrong message should fail verify check
+	wrongMessage := []byte("wrong message")
+	if verify := p.Verify(publicKey, wrongMessage, sig); verify {
+		t.Errorf("Verify(%s, %b) = %v, want false", wrongMessage, sig, verify)
+	}
+
+	// a different distinguishing identifier should fail verify check
+	other := &SM2{UID: []byte("ALICE123@YAHOO.COM")}
+	if verify := other.Verify(publicKey, message, sig); verify {
+		t.Errorf("Verify(%s, %b) with a different UID = %v, want false", message, sig, verify)
+	}
+
+	publicKeyCheck, err := p.PrivateToPublic(privateKey)
+	if err != nil {
+		t.Errorf("privateToPublic() = %v, want <nil>", err)
+	}
+	if !reflect.DeepEqual(publicKeyCheck, publicKey) {
+		t.Errorf("PrivateToPublic() = %v, want %v", publicKeyCheck, publicKey)
+	}
+}
+
+func TestKnownAnswer(t *testing.T) {
+	t.Parallel()
+	p := New()
+
+	// Example from GM/T 0003.5-2012 appendix A.2, signed with the default
+	// distinguishing identifier "1234567812345678".
+	privateKey, _ := hex.DecodeString("3945208f7b2144b13f36e38ac6d39f95889393692860b51a42fb81ef4df7c5b8")
+	publicKey, _ := hex.DecodeString("04" +
+		"09f9df311e5421a150dd7d161e4bc5c672179fad1833fc076bb08ff356f35020" +
+		"ccea490ce26775a52dc6ea718cc1aa600aed05fbf35e084a6632f6072da9ad13")
+	signature, _ := hex.DecodeString(
+		"f5a03b0648d2c4630eeac513e1bb81a15944da3827d5b74143ac7eaceee720b3" +
+			"b1b6aa29df212fd8763182bc0d421ca1bb9038fd1f7f42d4840b69c485bbc1aa")
+	message := []byte("message digest")
+
+	publicKeyCheck, err := p.PrivateToPublic(privateKey)
+	if err != nil {
+		t.Errorf("PrivateToPublic() = %v, want <nil>", err)
+	}
+	if !reflect.DeepEqual(publicKeyCheck, publicKey) {
+		t.Errorf("PrivateToPublic() = %x, want %x", publicKeyCheck, publicKey)
+	}
+
+	if verify := p.Verify(publicKey, message, signature); !verify {
+		t.Errorf("Verify(%s, %x) = %v, want true", message, signature, verify)
+	}
+
+	if verify := p.Verify(publicKey, []byte("message digesT"), signature); verify {
+		t.Errorf("Verify() of a modified message = %v, want false", verify)
+	}
+
+	// SM2 signatures are randomized, but a fresh one must still verify.
+	if verify := p.Verify(publicKey, message, p.Sign(privateKey, message)); !verify {
+		t.Errorf("Verify() of a fresh signature = %v, want true", verify)
+	}
+}
+
+func TestRandomKeyPair(t *testing.T) {
+	t.Parallel()
+
+	kp := New().RandomKeyPair()
+	if len(kp.PrivateKey) != PrivateKeySize {
+		t.Errorf("private key length should be %d", PrivateKeySize)
+	}
+	if len(kp.PublicKey) != PublicKeySize {
+		t.Errorf("public key length should be %d", PublicKeySize)
+	}
+}
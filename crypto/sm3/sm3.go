@@ -0,0 +1,24 @@
+package sm3
+
+import (
+	"github.com/cocher/crypto"
+
+	gmsm3 "github.com/tjfoc/gmsm/sm3"
+)
+
+// SM3 represents the SM3 (GB/T 32905) cryptographic hash algorithm.
+type SM3 struct{}
+
+var (
+	_ crypto.HashPolicy = (*SM3)(nil)
+)
+
+// New returns a SM3 hash policy.
+func New() *SM3 {
+	return &SM3{}
+}
+
+// HashBytes hashes the given bytes using the SM3 hash algorithm.
+func (p *SM3) HashBytes(bytes []byte) []byte {
+	return gmsm3.Sm3Sum(bytes)
+}
@@ -0,0 +1,66 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cocher/crypto"
+)
+
+func BenchmarkHash(b *testing.B) {
+	hp := New()
+
+	message := make([]byte, 64)
+	_, err := rand.Read(message)
+	if err != nil {
+		panic(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		hp.HashBytes(message)
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+	hp := New()
+
+	r := crypto.Hash(hp, big.NewInt(123))
+
+	n := new(big.Int)
+	n, ok := n.SetString("21183833147372056635558899943764269866433051771225427499130993547824879555396", 10)
+	if !ok {
+		t.Errorf("big int error")
+	}
+	if n.String() != r.String() {
+		t.Errorf("String() n = %v, want %v", n, r)
+	}
+}
+
+func TestHashBytes(t *testing.T) {
+	t.Parallel()
+	hp := New()
+
+	// Examples from GB/T 32905-2016 appendix A.
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"abc", "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+		{strings.Repeat("abcd", 16), "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+	}
+
+	for _, tt := range testCases {
+		expected, _ := hex.DecodeString(tt.expected)
+		r := hp.HashBytes([]byte(tt.input))
+		if !bytes.Equal(expected, r) {
+			t.Errorf("HashBytes(%s) = %x, want %x", tt.input, r, expected)
+		}
+	}
+}
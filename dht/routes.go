@@ -13,10 +13,11 @@ const BucketSize = 16
 
 // RoutingTable contains one bucket list for lookups.
 type RoutingTable struct {
-	// Current node's ID.
-	self peer.ID
-
+	// Current node's ID and the buckets of peers around it, both replaced
+	// when the node rotates its keys.
+	self    peer.ID
 	buckets []*Bucket
+	mutex   sync.RWMutex
 }
 
 // Bucket holds a list of contacts of this node.
@@ -50,17 +51,29 @@ func CreateRoutingTable(id peer.ID) *RoutingTable {
 
 // Self returns the ID of the node hosting the current routing table instance.
 func (t *RoutingTable) Self() peer.ID {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
 	return t.self
 }
 
+// state returns the ID hosting the routing table along with its buckets.
+func (t *RoutingTable) state() (peer.ID, []*Bucket) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.self, t.buckets
+}
+
 // Update moves a peer to the front of a bucket in the routing table.
 func (t *RoutingTable) Update(target peer.ID) {
-	if len(t.self.Id) != len(target.Id) {
+	self, buckets := t.state()
+	if len(self.Id) != len(target.Id) {
 		return
 	}
 
-	bucketID := target.XorID(t.self).PrefixLen()
-	bucket := t.Bucket(bucketID)
+	bucketID := target.XorID(self).PrefixLen()
+	bucket := buckets[bucketID]
 
 	var element *list.Element
 
@@ -86,12 +99,90 @@ func (t *RoutingTable) Update(target peer.ID) {
 	bucket.mutex.Unlock()
 }
 
+// Replace swaps a peer that rotated its keys for its successor, keeping its
+// position in its bucket if both IDs fall into the same one. If previous is the
+// ID hosting the routing table, every peer is moved to the bucket matching its
+// distance to the successor. Returns false if previous was not found.
+func (t *RoutingTable) Replace(previous peer.ID, successor peer.ID) bool {
+	if len(previous.Id) != len(successor.Id) {
+		return false
+	}
+
+	self, buckets := t.state()
+	if self.Equals(previous) {
+		return t.replaceSelf(previous, successor)
+	}
+
+	bucketID := previous.XorID(self).PrefixLen()
+	bucket := buckets[bucketID]
+
+	bucket.mutex.Lock()
+
+	var element *list.Element
+	for e := bucket.Front(); e != nil; e = e.Next() {
+		if e.Value.(peer.ID).Equals(previous) {
+			element = e
+			break
+		}
+	}
+
+	if element == nil {
+		bucket.mutex.Unlock()
+		return false
+	}
+
+	if successor.XorID(self).PrefixLen() == bucketID {
+		element.Value = successor
+		bucket.mutex.Unlock()
+		return true
+	}
+
+	bucket.Remove(element)
+	bucket.mutex.Unlock()
+
+	t.Update(successor)
+	return true
+}
+
+// replaceSelf re-buckets all peers around the new ID hosting the routing
+// table. The new buckets are filled before they are swapped in, so that
+// lookups meanwhile still see every peer. Returns false if the ID hosting the
+// routing table is not previous any more.
+func (t *RoutingTable) replaceSelf(previous peer.ID, self peer.ID) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.self.Equals(previous) {
+		return false
+	}
+
+	next := CreateRoutingTable(self)
+
+	for _, bucket := range t.buckets {
+		bucket.mutex.RLock()
+
+		// Walk from the back so that the most recently seen peers end up in front again.
+		for e := bucket.Back(); e != nil; e = e.Prev() {
+			if id := e.Value.(peer.ID); !id.Equals(previous) {
+				next.Update(id)
+			}
+		}
+
+		bucket.mutex.RUnlock()
+	}
+
+	t.self, t.buckets = self, next.buckets
+	return true
+}
+
 // GetPeers returns a randomly-ordered, unique list of all peers within the routing network (excluding itself).
 func (t *RoutingTable) GetPeers() (peers []peer.ID) {
+	self, buckets := t.state()
+
 	visited := make(map[string]struct{})
-	visited[t.self.PublicKeyHex()] = struct{}{}
+	visited[self.PublicKeyHex()] = struct{}{}
 
-	for _, bucket := range t.buckets {
+	for _, bucket := range buckets {
 		bucket.mutex.RLock()
 
 		for e := bucket.Front(); e != nil; e = e.Next() {
@@ -110,10 +201,12 @@ func (t *RoutingTable) GetPeers() (peers []peer.ID) {
 
 // GetPeerAddresses returns a unique list of all peer addresses within the routing network.
 func (t *RoutingTable) GetPeerAddresses() (peers []string) {
+	self, buckets := t.state()
+
 	visited := make(map[string]struct{})
-	visited[t.self.PublicKeyHex()] = struct{}{}
+	visited[self.PublicKeyHex()] = struct{}{}
 
-	for _, bucket := range t.buckets {
+	for _, bucket := range buckets {
 		bucket.mutex.RLock()
 
 		for e := bucket.Front(); e != nil; e = e.Next() {
@@ -132,8 +225,9 @@ func (t *RoutingTable) GetPeerAddresses() (peers []string) {
 
 // RemovePeer removes a peer from the routing table with O(bucket_size) time complexity.
 func (t *RoutingTable) RemovePeer(target peer.ID) bool {
-	bucketID := target.XorID(t.self).PrefixLen()
-	bucket := t.Bucket(bucketID)
+	self, buckets := t.state()
+	bucketID := target.XorID(self).PrefixLen()
+	bucket := buckets[bucketID]
 
 	bucket.mutex.Lock()
 
@@ -153,8 +247,9 @@ func (t *RoutingTable) RemovePeer(target peer.ID) bool {
 
 // PeerExists checks if a peer exists in the routing table with O(bucket_size) time complexity.
 func (t *RoutingTable) PeerExists(target peer.ID) bool {
-	bucketID := target.XorID(t.self).PrefixLen()
-	bucket := t.Bucket(bucketID)
+	self, buckets := t.state()
+	bucketID := target.XorID(self).PrefixLen()
+	bucket := buckets[bucketID]
 
 	bucket.mutex.Lock()
 
@@ -171,12 +266,13 @@ func (t *RoutingTable) PeerExists(target peer.ID) bool {
 
 // FindClosestPeers returns a list of k(count) peers with smallest XorID distance.
 func (t *RoutingTable) FindClosestPeers(target peer.ID, count int) (peers []peer.ID) {
-	if len(t.self.Id) != len(target.Id) {
+	self, buckets := t.state()
+	if len(self.Id) != len(target.Id) {
 		return []peer.ID{}
 	}
 
-	bucketID := target.XorID(t.self).PrefixLen()
-	bucket := t.Bucket(bucketID)
+	bucketID := target.XorID(self).PrefixLen()
+	bucket := buckets[bucketID]
 
 	bucket.mutex.RLock()
 
@@ -186,9 +282,9 @@ func (t *RoutingTable) FindClosestPeers(target peer.ID, count int) (peers []peer
 
 	bucket.mutex.RUnlock()
 
-	for i := 1; len(peers) < count && (bucketID-i >= 0 || bucketID+i < len(t.self.Id)*8); i++ {
+	for i := 1; len(peers) < count && (bucketID-i >= 0 || bucketID+i < len(self.Id)*8); i++ {
 		if bucketID-i >= 0 {
-			other := t.Bucket(bucketID - i)
+			other := buckets[bucketID-i]
 			other.mutex.RLock()
 			for e := other.Front(); e != nil; e = e.Next() {
 				peers = append(peers, e.Value.(peer.ID))
@@ -196,8 +292,8 @@ func (t *RoutingTable) FindClosestPeers(target peer.ID, count int) (peers []peer
 			other.mutex.RUnlock()
 		}
 
-		if bucketID+i < len(t.self.Id)*8 {
-			other := t.Bucket(bucketID + i)
+		if bucketID+i < len(self.Id)*8 {
+			other := buckets[bucketID+i]
 			other.mutex.RLock()
 			for e := other.Front(); e != nil; e = e.Next() {
 				peers = append(peers, e.Value.(peer.ID))
@@ -222,8 +318,9 @@ func (t *RoutingTable) FindClosestPeers(target peer.ID, count int) (peers []peer
 
 // Bucket returns a specific Bucket by ID.
 func (t *RoutingTable) Bucket(id int) *Bucket {
-	if id >= 0 && id < len(t.buckets) {
-		return t.buckets[id]
+	_, buckets := t.state()
+	if id >= 0 && id < len(buckets) {
+		return buckets[id]
 	}
 	return nil
 }
@@ -123,6 +123,101 @@ func TestRemovePeer(t *testing.T) {
 
 }
 
+func TestReplace(t *testing.T) {
+	t.Parallel()
+
+	routingTable := CreateRoutingTable(id1)
+	routingTable.Update(id2)
+	routingTable.Update(id3)
+
+	successor := peer.CreateID("0001", MustReadRand(32))
+	if !routingTable.Replace(id2, successor) {
+		t.Fatal("replace() of a known peer failed")
+	}
+	if routingTable.PeerExists(id2) {
+		t.Errorf("replace() kept the previous ID %v", id2)
+	}
+	if !routingTable.PeerExists(successor) {
+		t.Errorf("replace() did not add the successor ID %v", successor)
+	}
+
+	testee := routingTable.GetPeerAddresses()
+	sort.Strings(testee)
+	tester := []string{"0001", "0002"}
+	if !reflect.DeepEqual(tester, testee) {
+		t.Errorf("replace() got: %v, expected : %v", testee, tester)
+	}
+
+	if routingTable.Replace(id2, successor) {
+		t.Errorf("replace() of an unknown peer succeeded")
+	}
+}
+
+func TestReplaceSelf(t *testing.T) {
+	t.Parallel()
+
+	// Few enough peers that no bucket overflows when they are re-bucketed.
+	routingTable := CreateRoutingTable(id1)
+	for i := 0; i < BucketSize; i++ {
+		routingTable.Update(peer.CreateID(hex.EncodeToString(MustReadRand(2)), MustReadRand(32)))
+	}
+	before := routingTable.GetPeers()
+
+	self := peer.CreateID("0000", MustReadRand(32))
+	if !routingTable.Replace(id1, self) {
+		t.Fatal("replace() of self failed")
+	}
+	if !routingTable.Self().Equals(self) {
+		t.Errorf("Self() = %v, expected %v", routingTable.Self(), self)
+	}
+	if routingTable.PeerExists(id1) {
+		t.Errorf("replace() kept the previous self ID %v", id1)
+	}
+
+	// every peer must be found in the bucket matching its distance to the new self
+	for _, node := range before {
+		if !routingTable.PeerExists(node) {
+			t.Errorf("peer %v was lost when replacing self", node)
+		}
+	}
+	if len(routingTable.GetPeers()) != len(before) {
+		t.Errorf("len(peers) = %d, expected %d", len(routingTable.GetPeers()), len(before))
+	}
+}
+
+func TestReplaceSelfConcurrently(t *testing.T) {
+	t.Parallel()
+
+	routingTable := CreateRoutingTable(id1)
+	for i := 0; i < BucketSize; i++ {
+		routingTable.Update(peer.CreateID(hex.EncodeToString(MustReadRand(2)), MustReadRand(32)))
+	}
+	count := len(routingTable.GetPeers())
+
+	// Lookups during rotations see every peer, never a table being refilled.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		self := id1
+		for i := 0; i < 100; i++ {
+			successor := peer.CreateID("0000", MustReadRand(32))
+			routingTable.Replace(self, successor)
+			self = successor
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if peers := routingTable.GetPeers(); len(peers) != count {
+			t.Fatalf("len(peers) = %d during replace(), expected %d", len(peers), count)
+		}
+	}
+}
+
 func TestFindClosestPeers(t *testing.T) {
 	t.Parallel()
 
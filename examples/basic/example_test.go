@@ -42,6 +42,10 @@ func ExampleBasicComponent() {
 	host := "localhost"
 	startPort := 5000
 
+	// Message types are registered before nodes connect, as they advertise
+	// the message types they support to their peers.
+	opcode.RegisterMessageType(opcode.Opcode(1000), &messages.BasicMessage{})
+
 	var nodes []*network.Network
 	var Components []*BasicComponent
 
@@ -77,8 +81,6 @@ func ExampleBasicComponent() {
 		}
 	}
 
-	opcode.RegisterMessageType(opcode.Opcode(1000), &messages.BasicMessage{})
-
 	// Wait for all nodes to finish discovering other peers.
 	time.Sleep(1 * time.Second)
 
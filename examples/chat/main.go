@@ -8,7 +8,7 @@ import (
 	"strings"
 
 	"github.com/cocher/utils/log"
-	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/crypto/keystore"
 	"github.com/cocher/examples/chat/messages"
 	"github.com/cocher/network"
 	"github.com/cocher/network/discovery"
@@ -21,7 +21,7 @@ type ChatComponent struct{ *network.Component }
 func (state *ChatComponent) Receive(ctx *network.ComponentContext) error {
 	switch msg := ctx.Message().(type) {
 	case *messages.ChatMessage:
-		log.Infof("<%s> %s", ctx.Client().ID.Address, msg.Message)
+		log.Infof("<%s> %s", ctx.Client().ID().Address, msg.Message)
 	}
 
 	return nil
@@ -36,6 +36,9 @@ func main() {
 	hostFlag := flag.String("host", "localhost", "host to listen to")
 	protocolFlag := flag.String("protocol", "tcp", "protocol to use (kcp/tcp)")
 	peersFlag := flag.String("peers", "", "peers to connect to")
+	keystoreFlag := flag.String("keystore", "", "directory to keep the node identity in (random identity if empty)")
+	identityFlag := flag.String("identity", "chat", "name of the node identity in the keystore")
+	passphraseFlag := flag.String("passphrase", "", "passphrase the node identity is encrypted with")
 	flag.Parse()
 
 	port := uint16(*portFlag)
@@ -43,14 +46,14 @@ func main() {
 	protocol := *protocolFlag
 	peers := strings.Split(*peersFlag, ",")
 
-	keys := ed25519.RandomKeyPair()
-
-	log.Infof("Private Key: %s", keys.PrivateKeyHex())
-	log.Infof("Public Key: %s", keys.PublicKeyHex())
-
-	opcode.RegisterMessageType(opcode.Opcode(1000), &messages.ChatMessage{})
+	opcode.RegisterMessage(&messages.ChatMessage{})
 	builder := network.NewBuilder()
-	builder.SetKeys(keys)
+	if len(*keystoreFlag) > 0 {
+		if err := builder.LoadOrCreateKeys(keystore.New(*keystoreFlag), *identityFlag, *passphraseFlag); err != nil {
+			log.Fatal(err)
+			return
+		}
+	}
 	builder.SetAddress(network.FormatAddress(protocol, host, port))
 
 	// Add keepalive Component
@@ -74,6 +77,8 @@ func main() {
 		return
 	}
 
+	log.Infof("Public Key: %s", net.GetKeys().PublicKeyHex())
+
 	go net.Listen()
 
 	if len(peers) > 0 {
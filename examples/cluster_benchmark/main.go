@@ -100,7 +100,7 @@ func main() {
 	log.Infof("Private Key: %s", keys.PrivateKeyHex())
 	log.Infof("Public Key: %s", keys.PublicKeyHex())
 
-	opcode.RegisterMessageType(opcode.Opcode(1000), &messages.Empty{})
+	opcode.RegisterMessage(&messages.Empty{})
 	builder := network.NewBuilder()
 	builder.SetKeys(keys)
 	builder.SetAddress(network.FormatAddress(protocol, host, port))
@@ -51,7 +51,7 @@ func main() {
 	flag.Parse()
 	protocol := *protocolFlag
 	runtime.GOMAXPROCS(runtime.NumCPU())
-	opcode.RegisterMessageType(opcode.Opcode(1000), &messages.BasicMessage{})
+	opcode.RegisterMessage(&messages.BasicMessage{})
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
@@ -42,7 +42,7 @@ func main() {
 	protocol := *protocolFlag
 
 	runtime.GOMAXPROCS(runtime.NumCPU())
-	opcode.RegisterMessageType(opcode.Opcode(1000), &messages.BasicMessage{})
+	opcode.RegisterMessage(&messages.BasicMessage{})
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
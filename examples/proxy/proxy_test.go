@@ -33,6 +33,11 @@ func (n *ProxyComponent) Startup(net *network.Network) {
 	n.Mailbox = make(chan *messages.ProxyMessage, 1)
 }
 
+// Dependencies implements the network interface callback
+func (n *ProxyComponent) Dependencies() []interface{} {
+	return []interface{}{discovery.ComponentID}
+}
+
 // Handle implements the network interface callback
 func (n *ProxyComponent) Receive(ctx *network.ComponentContext) error {
 	// Handle the proxy message.
@@ -43,7 +48,7 @@ func (n *ProxyComponent) Receive(ctx *network.ComponentContext) error {
 		//fmt.Fprintf(os.Stderr, "Node %d received a message from node %d.\n", ids[ctx.Network().Address], ids[ctx.Sender().Address])
 
 		if err := n.ProxyBroadcast(ctx.Network(), ctx.Sender(), msg); err != nil {
-			panic(err)
+			return err
 		}
 	}
 	return nil
@@ -57,15 +62,12 @@ func (n *ProxyComponent) ProxyBroadcast(node *network.Network, sender peer.ID, m
 	}
 
 	// Check if we are the target.
-	if node.ID.Equals(targetID) {
+	if node.GetID().Equals(targetID) {
 		return nil
 	}
 
-	Component, registered := node.Component(discovery.ComponentID)
-	if !registered {
-		return errors.New("discovery Component not registered")
-	}
-
+	// The discovery Component is a dependency, and thus always registered.
+	Component, _ := node.Component(discovery.ComponentID)
 	routes := Component.(*discovery.Component).Routes
 
 	// If the target is in our routing table, directly proxy the message to them.
@@ -161,11 +163,11 @@ func ExampleProxyComponent() {
 	expected := &messages.ProxyMessage{
 		Message: fmt.Sprintf("This is a proxy message from Node %d", sender),
 		Destination: &messages.ID{
-			Address: nodes[target].ID.Address,
-			Id:      nodes[target].ID.Id,
+			Address: nodes[target].GetID().Address,
+			Id:      nodes[target].GetID().Id,
 		},
 	}
-	Components[sender].ProxyBroadcast(nodes[sender], nodes[sender].ID, expected)
+	Components[sender].ProxyBroadcast(nodes[sender], nodes[sender].GetID(), expected)
 
 	fmt.Printf("Node %d sent out a message targeting for node %d.\n", sender, target)
 
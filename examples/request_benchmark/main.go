@@ -14,7 +14,6 @@ import (
 	"github.com/cocher/examples/request_benchmark/messages"
 	"github.com/cocher/network"
 	"github.com/cocher/network/discovery"
-	"github.com/cocher/types/opcode"
 	"github.com/pkg/errors"
 )
 
@@ -25,11 +24,6 @@ const (
 	startPort            = 23000
 )
 
-func init() {
-	opcode.RegisterMessageType(opcode.Opcode(1000), &messages.LoadRequest{})
-	opcode.RegisterMessageType(opcode.Opcode(1001), &messages.LoadReply{})
-}
-
 func main() {
 	// send glog to the terminal instead of a file
 	flag.Set("logtostderr", "true")
@@ -49,7 +43,7 @@ func run() string {
 	numNodes := *numNodesFlag
 	numReqPerNode := *numReqPerNodeFlag
 
-	nets := setupNetworks(host, startPort, numNodes)
+	loads := setupNetworks(host, startPort, numNodes)
 	expectedTotalResp := numReqPerNode * numNodes * (numNodes - 1)
 	var totalPos uint32
 
@@ -59,13 +53,13 @@ func run() string {
 
 	// sending to all nodes concurrently
 	for r := 0; r < numReqPerNode; r++ {
-		for n, nt := range nets {
+		for n, load := range loads {
 			wg.Add(1)
-			go func(net *network.Network, idx int) {
+			go func(load *loadTestComponent, idx int) {
 				defer wg.Done()
-				positive := sendMsg(net, idx)
+				positive := load.sendMsg(idx)
 				atomic.AddUint32(&totalPos, positive)
-			}(nt, n+numNodes*r)
+			}(load, n+numNodes*r)
 		}
 	}
 	wg.Wait()
@@ -77,16 +71,18 @@ func run() string {
 		totalTime, numNodes, totalPos, expectedTotalResp, reqPerSec)
 }
 
-func setupNetworks(host string, startPort int, numNodes int) []*network.Network {
+func setupNetworks(host string, startPort int, numNodes int) []*loadTestComponent {
 	var nodes []*network.Network
+	var loads []*loadTestComponent
 
 	for i := 0; i < numNodes; i++ {
 		builder := network.NewBuilder()
 		builder.SetKeys(ed25519.RandomKeyPair())
 		builder.SetAddress(network.FormatAddress("tcp", host, uint16(startPort+i)))
 
+		load := new(loadTestComponent)
 		builder.AddComponent(new(discovery.Component))
-		builder.AddComponent(new(loadTestComponent))
+		builder.AddComponent(load)
 
 		node, err := builder.Build()
 		if err != nil {
@@ -96,6 +92,7 @@ func setupNetworks(host string, startPort int, numNodes int) []*network.Network
 		go node.Listen()
 
 		nodes = append(nodes, node)
+		loads = append(loads, load)
 	}
 
 	// Make sure all nodes are listening for incoming peers.
@@ -113,20 +110,14 @@ func setupNetworks(host string, startPort int, numNodes int) []*network.Network
 	// Wait for all nodes to finish discovering other peers.
 	time.Sleep(1 * time.Second)
 
-	return nodes
+	return loads
 }
 
-func sendMsg(net *network.Network, idx int) uint32 {
+func (p *loadTestComponent) sendMsg(idx int) uint32 {
 	var positiveResponses uint32
 
-	Component, registered := net.Component(discovery.ComponentID)
-	if !registered {
-		return 0
-	}
-
-	routes := Component.(*discovery.Component).Routes
-
-	addresses := routes.GetPeerAddresses()
+	net := p.net
+	addresses := p.discovery.Routes.GetPeerAddresses()
 
 	errs := make(chan error, len(addresses))
 
@@ -148,20 +139,16 @@ func sendMsg(net *network.Network, idx int) uint32 {
 
 			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 			defer cancel()
-			response, err := client.Request(ctx, &messages.LoadRequest{Id: expectedID})
+			reply, err := messages.NewLoadClient(client).Load(ctx, &messages.LoadRequest{Id: expectedID})
 			if err != nil {
 				errs <- errors.Wrapf(err, "request error for req id %s", expectedID)
 				return
 			}
 
-			if reply, ok := response.(*messages.LoadReply); ok {
-				if reply.Id == expectedID {
-					atomic.AddUint32(&positiveResponses, 1)
-				} else {
-					errs <- errors.Errorf("expected ID=%s got %s\n", expectedID, reply.Id)
-				}
+			if reply.Id == expectedID {
+				atomic.AddUint32(&positiveResponses, 1)
 			} else {
-				errs <- errors.Errorf("expected messages.LoadReply but got %v\n", response)
+				errs <- errors.Errorf("expected ID=%s got %s\n", expectedID, reply.Id)
 			}
 
 		}(address)
@@ -180,15 +167,32 @@ func sendMsg(net *network.Network, idx int) uint32 {
 
 type loadTestComponent struct {
 	*network.Component
+
+	net       *network.Network
+	discovery *discovery.Component
 }
 
-// Receive takes in *messages.ProxyMessage and replies with *messages.ID
-func (p *loadTestComponent) Receive(ctx *network.ComponentContext) error {
-	switch msg := ctx.Message().(type) {
-	case *messages.LoadRequest:
-		response := &messages.LoadReply{Id: msg.Id}
-		ctx.Reply(context.Background(), response)
-	}
+// Dependencies implements the network interface callback
+func (p *loadTestComponent) Dependencies() []interface{} {
+	return []interface{}{discovery.ComponentID}
+}
+
+// Startup looks up the discovery Component, which is registered and started
+// before this one as it is a dependency.
+func (p *loadTestComponent) Startup(net *network.Network) {
+	p.net = net
+
+	component, _ := net.Component(discovery.ComponentID)
+	p.discovery = component.(*discovery.Component)
+}
+
+// RegisterHandlers serves the Load service.
+func (p *loadTestComponent) RegisterHandlers(router *network.Router) error {
+	return messages.RegisterLoadServer(router, p)
+}
 
-	return nil
+// Load replies to a *messages.LoadRequest with a *messages.LoadReply of the
+// same ID.
+func (p *loadTestComponent) Load(ctx *network.ComponentContext, req *messages.LoadRequest) (*messages.LoadReply, error) {
+	return &messages.LoadReply{Id: req.Id}, nil
 }
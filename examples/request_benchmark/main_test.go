@@ -2,6 +2,12 @@ package main
 
 import (
 	"testing"
+
+	"github.com/cocher/examples/request_benchmark/messages"
+	"github.com/cocher/network"
+	"github.com/cocher/network/discovery"
+	"github.com/cocher/types/opcode"
+	"github.com/stretchr/testify/assert"
 )
 
 // Usage:
@@ -10,3 +16,23 @@ func TestClient(t *testing.T) {
 	t.Parallel()
 	t.Log(run())
 }
+
+func TestMessageRegistry(t *testing.T) {
+	t.Parallel()
+
+	// Networks with a registry of their own learn the messages of the
+	// services they serve.
+	registry := opcode.NewRegistry()
+
+	builder := network.NewBuilderWithOptions(network.MessageRegistry(registry))
+	builder.AddComponent(new(discovery.Component))
+	builder.AddComponent(new(loadTestComponent))
+
+	_, err := builder.Build()
+	assert.Nil(t, err)
+
+	_, err = registry.GetOpcodeByName("messages.LoadRequest")
+	assert.Nil(t, err)
+	_, err = registry.GetOpcode(&messages.LoadReply{})
+	assert.Nil(t, err)
+}
@@ -31,7 +31,7 @@ type LoadRequest struct {
 func (m *LoadRequest) Reset()      { *m = LoadRequest{} }
 func (*LoadRequest) ProtoMessage() {}
 func (*LoadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_load_f535355e4ce34d68, []int{0}
+	return fileDescriptor_load_b90905d78a5129e2, []int{0}
 }
 func (m *LoadRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -74,7 +74,7 @@ type LoadReply struct {
 func (m *LoadReply) Reset()      { *m = LoadReply{} }
 func (*LoadReply) ProtoMessage() {}
 func (*LoadReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_load_f535355e4ce34d68, []int{1}
+	return fileDescriptor_load_b90905d78a5129e2, []int{1}
 }
 func (m *LoadReply) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -726,11 +726,11 @@ var (
 )
 
 func init() {
-	proto.RegisterFile("examples/request_benchmark/messages/load.proto", fileDescriptor_load_f535355e4ce34d68)
+	proto.RegisterFile("examples/request_benchmark/messages/load.proto", fileDescriptor_load_b90905d78a5129e2)
 }
 
-var fileDescriptor_load_f535355e4ce34d68 = []byte{
-	// 196 bytes of a gzipped FileDescriptorProto
+var fileDescriptor_load_b90905d78a5129e2 = []byte{
+	// 217 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xd2, 0x4b, 0xad, 0x48, 0xcc,
 	0x2d, 0xc8, 0x49, 0x2d, 0xd6, 0x2f, 0x4a, 0x2d, 0x2c, 0x4d, 0x2d, 0x2e, 0x89, 0x4f, 0x4a, 0xcd,
 	0x4b, 0xce, 0xc8, 0x4d, 0x2c, 0xca, 0xd6, 0xcf, 0x4d, 0x2d, 0x2e, 0x4e, 0x4c, 0x4f, 0x2d, 0xd6,
@@ -738,10 +738,11 @@ var fileDescriptor_load_f535355e4ce34d68 = []byte{
 	0xa5, 0xe7, 0xa7, 0xe7, 0xeb, 0x83, 0x45, 0x93, 0x4a, 0xd3, 0xf4, 0x41, 0x3c, 0x30, 0x07, 0xcc,
 	0x82, 0xa8, 0x56, 0x92, 0xe5, 0xe2, 0xf6, 0xc9, 0x4f, 0x4c, 0x09, 0x82, 0x18, 0x2d, 0xc4, 0xc7,
 	0xc5, 0x94, 0x99, 0x22, 0xc1, 0xa8, 0xc0, 0xa8, 0xc1, 0x19, 0xc4, 0x94, 0x99, 0xa2, 0x24, 0xcd,
-	0xc5, 0x09, 0x91, 0x2e, 0xc8, 0xa9, 0x44, 0x97, 0x74, 0x32, 0xb9, 0xf1, 0x50, 0x8e, 0xe1, 0xc1,
-	0x43, 0x39, 0xc6, 0x0f, 0x0f, 0xe5, 0x18, 0x7f, 0x3c, 0x94, 0x63, 0x6c, 0x78, 0x24, 0xc7, 0xb8,
-	0xe2, 0x91, 0x1c, 0xe3, 0x8e, 0x47, 0x72, 0x8c, 0x27, 0x1e, 0xc9, 0x31, 0x5e, 0x78, 0x24, 0xc7,
-	0xf8, 0xe0, 0x91, 0x1c, 0xe3, 0x84, 0xc7, 0x72, 0x0c, 0x17, 0x1e, 0xcb, 0x31, 0xdc, 0x78, 0x2c,
-	0xc7, 0x90, 0xc4, 0x06, 0xb6, 0xd8, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0x1c, 0xb6, 0x58, 0x55,
-	0xd8, 0x00, 0x00, 0x00,
+	0xc5, 0x09, 0x91, 0x2e, 0xc8, 0xa9, 0x44, 0x97, 0x34, 0xb2, 0xe2, 0x62, 0x01, 0x49, 0x0a, 0x19,
+	0x41, 0x69, 0x51, 0x3d, 0x98, 0xd5, 0x7a, 0x48, 0x66, 0x4a, 0x09, 0xa3, 0x0b, 0x17, 0xe4, 0x54,
+	0x3a, 0x99, 0xdc, 0x78, 0x28, 0xc7, 0xf0, 0xe0, 0xa1, 0x1c, 0xe3, 0x87, 0x87, 0x72, 0x8c, 0x3f,
+	0x1e, 0xca, 0x31, 0x36, 0x3c, 0x92, 0x63, 0x5c, 0xf1, 0x48, 0x8e, 0x71, 0xc7, 0x23, 0x39, 0xc6,
+	0x13, 0x8f, 0xe4, 0x18, 0x2f, 0x3c, 0x92, 0x63, 0x7c, 0xf0, 0x48, 0x8e, 0x71, 0xc2, 0x63, 0x39,
+	0x86, 0x0b, 0x8f, 0xe5, 0x18, 0x6e, 0x3c, 0x96, 0x63, 0x48, 0x62, 0x03, 0x3b, 0xda, 0x18, 0x30,
+	0x00, 0xb3, 0xba, 0x7b, 0x0a, 0x14, 0x01, 0x00, 0x00,
 }
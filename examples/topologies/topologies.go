@@ -184,7 +184,7 @@ func setupNodes(ports []int) ([]*network.Network, []*MockComponent, error) {
 	var nodes []*network.Network
 	var Components []*MockComponent
 
-	opcode.RegisterMessageType(opcode.Opcode(1000), &messages.BasicMessage{})
+	opcode.RegisterMessage(&messages.BasicMessage{})
 	for i, port := range ports {
 		builder := network.NewBuilder()
 		builder.SetKeys(ed25519.RandomKeyPair())
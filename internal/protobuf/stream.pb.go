@@ -12,6 +12,7 @@ import bytes "bytes"
 
 import strings "strings"
 import reflect "reflect"
+import github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
 
 import io "io"
 
@@ -41,7 +42,7 @@ type ID struct {
 func (m *ID) Reset()      { *m = ID{} }
 func (*ID) ProtoMessage() {}
 func (*ID) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{0}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{0}
 }
 func (m *ID) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -106,7 +107,13 @@ type Message struct {
 	// opcode specifies the message type
 	Opcode uint32 `protobuf:"varint,7,opt,name=opcode,proto3" json:"opcode,omitempty"`
 	// dial_address is the outgoing address for a udp connection
-	DialAddress          string   `protobuf:"bytes,8,opt,name=dial_address,json=dialAddress,proto3" json:"dial_address,omitempty"`
+	DialAddress string `protobuf:"bytes,8,opt,name=dial_address,json=dialAddress,proto3" json:"dial_address,omitempty"`
+	// codec is the name of the codec the message is encoded with, empty for protobuf
+	Codec string `protobuf:"bytes,9,opt,name=codec,proto3" json:"codec,omitempty"`
+	// stream_window is the number of replies the requester of a streaming request can buffer, zero if the request is not streaming
+	StreamWindow uint32 `protobuf:"varint,10,opt,name=stream_window,json=streamWindow,proto3" json:"stream_window,omitempty"`
+	// timeout is the time in nanoseconds left until the deadline of a request, zero if it has none
+	Timeout              int64    `protobuf:"varint,11,opt,name=timeout,proto3" json:"timeout,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -115,7 +122,7 @@ type Message struct {
 func (m *Message) Reset()      { *m = Message{} }
 func (*Message) ProtoMessage() {}
 func (*Message) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{1}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{1}
 }
 func (m *Message) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -200,6 +207,27 @@ func (m *Message) GetDialAddress() string {
 	return ""
 }
 
+func (m *Message) GetCodec() string {
+	if m != nil {
+		return m.Codec
+	}
+	return ""
+}
+
+func (m *Message) GetStreamWindow() uint32 {
+	if m != nil {
+		return m.StreamWindow
+	}
+	return 0
+}
+
+func (m *Message) GetTimeout() int64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
 type Ping struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -209,7 +237,7 @@ type Ping struct {
 func (m *Ping) Reset()      { *m = Ping{} }
 func (*Ping) ProtoMessage() {}
 func (*Ping) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{2}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{2}
 }
 func (m *Ping) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -247,7 +275,7 @@ type Pong struct {
 func (m *Pong) Reset()      { *m = Pong{} }
 func (*Pong) ProtoMessage() {}
 func (*Pong) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{3}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{3}
 }
 func (m *Pong) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -286,7 +314,7 @@ type LookupNodeRequest struct {
 func (m *LookupNodeRequest) Reset()      { *m = LookupNodeRequest{} }
 func (*LookupNodeRequest) ProtoMessage() {}
 func (*LookupNodeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{4}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{4}
 }
 func (m *LookupNodeRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -332,7 +360,7 @@ type LookupNodeResponse struct {
 func (m *LookupNodeResponse) Reset()      { *m = LookupNodeResponse{} }
 func (*LookupNodeResponse) ProtoMessage() {}
 func (*LookupNodeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{5}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{5}
 }
 func (m *LookupNodeResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -378,7 +406,7 @@ type Bytes struct {
 func (m *Bytes) Reset()      { *m = Bytes{} }
 func (*Bytes) ProtoMessage() {}
 func (*Bytes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{6}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{6}
 }
 func (m *Bytes) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -423,7 +451,7 @@ type Keepalive struct {
 func (m *Keepalive) Reset()      { *m = Keepalive{} }
 func (*Keepalive) ProtoMessage() {}
 func (*Keepalive) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{7}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{7}
 }
 func (m *Keepalive) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -461,7 +489,7 @@ type KeepaliveResponse struct {
 func (m *KeepaliveResponse) Reset()      { *m = KeepaliveResponse{} }
 func (*KeepaliveResponse) ProtoMessage() {}
 func (*KeepaliveResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{8}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{8}
 }
 func (m *KeepaliveResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -499,7 +527,7 @@ type Disconnect struct {
 func (m *Disconnect) Reset()      { *m = Disconnect{} }
 func (*Disconnect) ProtoMessage() {}
 func (*Disconnect) Descriptor() ([]byte, []int) {
-	return fileDescriptor_stream_654c54e9f51da163, []int{9}
+	return fileDescriptor_stream_c27a36122cd0c435, []int{9}
 }
 func (m *Disconnect) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -528,193 +556,577 @@ func (m *Disconnect) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Disconnect proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterType((*ID)(nil), "protobuf.ID")
-	proto.RegisterType((*Message)(nil), "protobuf.Message")
-	proto.RegisterType((*Ping)(nil), "protobuf.Ping")
-	proto.RegisterType((*Pong)(nil), "protobuf.Pong")
-	proto.RegisterType((*LookupNodeRequest)(nil), "protobuf.LookupNodeRequest")
-	proto.RegisterType((*LookupNodeResponse)(nil), "protobuf.LookupNodeResponse")
-	proto.RegisterType((*Bytes)(nil), "protobuf.Bytes")
-	proto.RegisterType((*Keepalive)(nil), "protobuf.Keepalive")
-	proto.RegisterType((*KeepaliveResponse)(nil), "protobuf.KeepaliveResponse")
-	proto.RegisterType((*Disconnect)(nil), "protobuf.Disconnect")
+type KeySuccession struct {
+	// previous identity of the node, whose key is being retired
+	Previous *ID `protobuf:"bytes,1,opt,name=previous" json:"previous,omitempty"`
+	// successor identity of the node, replacing the previous one
+	Successor *ID `protobuf:"bytes,2,opt,name=successor" json:"successor,omitempty"`
+	// timestamp is the unix time in nanoseconds at which the record was created
+	Timestamp int64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// signature of the record by the previous key
+	Signature []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	// successor_signature of the record by the successor key, proving possession of it
+	SuccessorSignature   []byte   `protobuf:"bytes,5,opt,name=successor_signature,json=successorSignature,proto3" json:"successor_signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
-func (this *ID) VerboseEqual(that interface{}) error {
-	if that == nil {
-		if this == nil {
-			return nil
-		}
-		return fmt.Errorf("that == nil && this != nil")
-	}
 
-	that1, ok := that.(*ID)
-	if !ok {
-		that2, ok := that.(ID)
-		if ok {
-			that1 = &that2
-		} else {
-			return fmt.Errorf("that is not of type *ID")
-		}
-	}
-	if that1 == nil {
-		if this == nil {
-			return nil
+func (m *KeySuccession) Reset()      { *m = KeySuccession{} }
+func (*KeySuccession) ProtoMessage() {}
+func (*KeySuccession) Descriptor() ([]byte, []int) {
+	return fileDescriptor_stream_c27a36122cd0c435, []int{10}
+}
+func (m *KeySuccession) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *KeySuccession) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_KeySuccession.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("that is type *ID but is nil && this != nil")
-	} else if this == nil {
-		return fmt.Errorf("that is type *ID but is not nil && this == nil")
+		return b[:n], nil
 	}
-	if !bytes.Equal(this.NetKey, that1.NetKey) {
-		return fmt.Errorf("NetKey this(%v) Not Equal that(%v)", this.NetKey, that1.NetKey)
+}
+func (dst *KeySuccession) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeySuccession.Merge(dst, src)
+}
+func (m *KeySuccession) XXX_Size() int {
+	return m.Size()
+}
+func (m *KeySuccession) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeySuccession.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeySuccession proto.InternalMessageInfo
+
+func (m *KeySuccession) GetPrevious() *ID {
+	if m != nil {
+		return m.Previous
 	}
-	if this.Address != that1.Address {
-		return fmt.Errorf("Address this(%v) Not Equal that(%v)", this.Address, that1.Address)
+	return nil
+}
+
+func (m *KeySuccession) GetSuccessor() *ID {
+	if m != nil {
+		return m.Successor
 	}
-	if !bytes.Equal(this.Id, that1.Id) {
-		return fmt.Errorf("Id this(%v) Not Equal that(%v)", this.Id, that1.Id)
+	return nil
+}
+
+func (m *KeySuccession) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
 	}
-	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
-		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	return 0
+}
+
+func (m *KeySuccession) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
 	}
 	return nil
 }
-func (this *ID) Equal(that interface{}) bool {
-	if that == nil {
-		return this == nil
+
+func (m *KeySuccession) GetSuccessorSignature() []byte {
+	if m != nil {
+		return m.SuccessorSignature
 	}
+	return nil
+}
 
-	that1, ok := that.(*ID)
-	if !ok {
-		that2, ok := that.(ID)
-		if ok {
-			that1 = &that2
-		} else {
-			return false
+type SignedAddress struct {
+	// address is a network address the peer can be reached on
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// timestamp is the unix time in nanoseconds at which the address was signed
+	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// signature of the address and timestamp by the key of the peer
+	Signature            []byte   `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignedAddress) Reset()      { *m = SignedAddress{} }
+func (*SignedAddress) ProtoMessage() {}
+func (*SignedAddress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_stream_c27a36122cd0c435, []int{11}
+}
+func (m *SignedAddress) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SignedAddress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SignedAddress.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if that1 == nil {
-		return this == nil
-	} else if this == nil {
-		return false
-	}
-	if !bytes.Equal(this.NetKey, that1.NetKey) {
-		return false
-	}
-	if this.Address != that1.Address {
-		return false
-	}
-	if !bytes.Equal(this.Id, that1.Id) {
-		return false
-	}
-	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
-		return false
+}
+func (dst *SignedAddress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignedAddress.Merge(dst, src)
+}
+func (m *SignedAddress) XXX_Size() int {
+	return m.Size()
+}
+func (m *SignedAddress) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignedAddress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignedAddress proto.InternalMessageInfo
+
+func (m *SignedAddress) GetAddress() string {
+	if m != nil {
+		return m.Address
 	}
-	return true
+	return ""
 }
-func (this *Message) VerboseEqual(that interface{}) error {
-	if that == nil {
-		if this == nil {
-			return nil
-		}
-		return fmt.Errorf("that == nil && this != nil")
+
+func (m *SignedAddress) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
 	}
+	return 0
+}
 
-	that1, ok := that.(*Message)
-	if !ok {
-		that2, ok := that.(Message)
-		if ok {
-			that1 = &that2
-		} else {
-			return fmt.Errorf("that is not of type *Message")
-		}
+func (m *SignedAddress) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
 	}
-	if that1 == nil {
-		if this == nil {
-			return nil
+	return nil
+}
+
+type PeerRecord struct {
+	// id of the peer the addresses belong to
+	Id *ID `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// addresses of the peer in order of preference
+	Addresses            []*SignedAddress `protobuf:"bytes,2,rep,name=addresses" json:"addresses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PeerRecord) Reset()      { *m = PeerRecord{} }
+func (*PeerRecord) ProtoMessage() {}
+func (*PeerRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_stream_c27a36122cd0c435, []int{12}
+}
+func (m *PeerRecord) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PeerRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PeerRecord.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("that is type *Message but is nil && this != nil")
-	} else if this == nil {
-		return fmt.Errorf("that is type *Message but is not nil && this == nil")
-	}
-	if !bytes.Equal(this.Message, that1.Message) {
-		return fmt.Errorf("Message this(%v) Not Equal that(%v)", this.Message, that1.Message)
-	}
-	if !this.Sender.Equal(that1.Sender) {
-		return fmt.Errorf("Sender this(%v) Not Equal that(%v)", this.Sender, that1.Sender)
-	}
-	if !bytes.Equal(this.Signature, that1.Signature) {
-		return fmt.Errorf("Signature this(%v) Not Equal that(%v)", this.Signature, that1.Signature)
+		return b[:n], nil
 	}
-	if this.RequestNonce != that1.RequestNonce {
-		return fmt.Errorf("RequestNonce this(%v) Not Equal that(%v)", this.RequestNonce, that1.RequestNonce)
+}
+func (dst *PeerRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerRecord.Merge(dst, src)
+}
+func (m *PeerRecord) XXX_Size() int {
+	return m.Size()
+}
+func (m *PeerRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerRecord proto.InternalMessageInfo
+
+func (m *PeerRecord) GetId() *ID {
+	if m != nil {
+		return m.Id
 	}
-	if this.MessageNonce != that1.MessageNonce {
-		return fmt.Errorf("MessageNonce this(%v) Not Equal that(%v)", this.MessageNonce, that1.MessageNonce)
+	return nil
+}
+
+func (m *PeerRecord) GetAddresses() []*SignedAddress {
+	if m != nil {
+		return m.Addresses
 	}
-	if this.ReplyFlag != that1.ReplyFlag {
-		return fmt.Errorf("ReplyFlag this(%v) Not Equal that(%v)", this.ReplyFlag, that1.ReplyFlag)
+	return nil
+}
+
+type Identify struct {
+	// agent_version is the name and version of the software the node runs
+	AgentVersion string `protobuf:"bytes,1,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
+	// protocols the node speaks, as names with versions
+	Protocols []string `protobuf:"bytes,2,rep,name=protocols" json:"protocols,omitempty"`
+	// opcodes of the message types the node has registered
+	Opcodes []uint32 `protobuf:"varint,3,rep,packed,name=opcodes" json:"opcodes,omitempty"`
+	// listen_addresses the node is reachable on, in order of preference
+	ListenAddresses []string `protobuf:"bytes,4,rep,name=listen_addresses,json=listenAddresses" json:"listen_addresses,omitempty"`
+	// observed_address is the address the receiver was seen connecting from
+	ObservedAddress      string   `protobuf:"bytes,5,opt,name=observed_address,json=observedAddress,proto3" json:"observed_address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Identify) Reset()      { *m = Identify{} }
+func (*Identify) ProtoMessage() {}
+func (*Identify) Descriptor() ([]byte, []int) {
+	return fileDescriptor_stream_c27a36122cd0c435, []int{13}
+}
+func (m *Identify) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Identify) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Identify.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if this.Opcode != that1.Opcode {
-		return fmt.Errorf("Opcode this(%v) Not Equal that(%v)", this.Opcode, that1.Opcode)
+}
+func (dst *Identify) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Identify.Merge(dst, src)
+}
+func (m *Identify) XXX_Size() int {
+	return m.Size()
+}
+func (m *Identify) XXX_DiscardUnknown() {
+	xxx_messageInfo_Identify.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Identify proto.InternalMessageInfo
+
+func (m *Identify) GetAgentVersion() string {
+	if m != nil {
+		return m.AgentVersion
 	}
-	if this.DialAddress != that1.DialAddress {
-		return fmt.Errorf("DialAddress this(%v) Not Equal that(%v)", this.DialAddress, that1.DialAddress)
+	return ""
+}
+
+func (m *Identify) GetProtocols() []string {
+	if m != nil {
+		return m.Protocols
 	}
-	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
-		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	return nil
+}
+
+func (m *Identify) GetOpcodes() []uint32 {
+	if m != nil {
+		return m.Opcodes
 	}
 	return nil
 }
-func (this *Message) Equal(that interface{}) bool {
-	if that == nil {
-		return this == nil
+
+func (m *Identify) GetListenAddresses() []string {
+	if m != nil {
+		return m.ListenAddresses
 	}
+	return nil
+}
 
-	that1, ok := that.(*Message)
-	if !ok {
-		that2, ok := that.(Message)
-		if ok {
-			that1 = &that2
-		} else {
-			return false
+func (m *Identify) GetObservedAddress() string {
+	if m != nil {
+		return m.ObservedAddress
+	}
+	return ""
+}
+
+type Capabilities struct {
+	// opcodes of the message types the node has registered
+	Opcodes []uint32 `protobuf:"varint,1,rep,packed,name=opcodes" json:"opcodes,omitempty"`
+	// protocols the node speaks, as IDs followed by their versions
+	Protocols []string `protobuf:"bytes,2,rep,name=protocols" json:"protocols,omitempty"`
+	// message_types the node has registered, by name, to detect peers using
+	// the same opcode for different messages
+	MessageTypes []*MessageType `protobuf:"bytes,3,rep,name=message_types,json=messageTypes" json:"message_types,omitempty"`
+	// codecs the node can decode messages with, starting with the one it
+	// encodes envelopes with
+	Codecs               []string `protobuf:"bytes,4,rep,name=codecs" json:"codecs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Capabilities) Reset()      { *m = Capabilities{} }
+func (*Capabilities) ProtoMessage() {}
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return fileDescriptor_stream_c27a36122cd0c435, []int{14}
+}
+func (m *Capabilities) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Capabilities) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Capabilities.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if that1 == nil {
-		return this == nil
-	} else if this == nil {
-		return false
+}
+func (dst *Capabilities) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Capabilities.Merge(dst, src)
+}
+func (m *Capabilities) XXX_Size() int {
+	return m.Size()
+}
+func (m *Capabilities) XXX_DiscardUnknown() {
+	xxx_messageInfo_Capabilities.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Capabilities proto.InternalMessageInfo
+
+func (m *Capabilities) GetOpcodes() []uint32 {
+	if m != nil {
+		return m.Opcodes
 	}
-	if !bytes.Equal(this.Message, that1.Message) {
-		return false
+	return nil
+}
+
+func (m *Capabilities) GetProtocols() []string {
+	if m != nil {
+		return m.Protocols
 	}
-	if !this.Sender.Equal(that1.Sender) {
-		return false
+	return nil
+}
+
+func (m *Capabilities) GetMessageTypes() []*MessageType {
+	if m != nil {
+		return m.MessageTypes
 	}
-	if !bytes.Equal(this.Signature, that1.Signature) {
-		return false
+	return nil
+}
+
+func (m *Capabilities) GetCodecs() []string {
+	if m != nil {
+		return m.Codecs
 	}
-	if this.RequestNonce != that1.RequestNonce {
-		return false
+	return nil
+}
+
+type MessageType struct {
+	// opcode the message type is registered to
+	Opcode uint32 `protobuf:"varint,1,opt,name=opcode,proto3" json:"opcode,omitempty"`
+	// name is the fully qualified protobuf name of the message type
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MessageType) Reset()      { *m = MessageType{} }
+func (*MessageType) ProtoMessage() {}
+func (*MessageType) Descriptor() ([]byte, []int) {
+	return fileDescriptor_stream_c27a36122cd0c435, []int{15}
+}
+func (m *MessageType) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MessageType) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MessageType.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if this.MessageNonce != that1.MessageNonce {
-		return false
+}
+func (dst *MessageType) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MessageType.Merge(dst, src)
+}
+func (m *MessageType) XXX_Size() int {
+	return m.Size()
+}
+func (m *MessageType) XXX_DiscardUnknown() {
+	xxx_messageInfo_MessageType.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MessageType proto.InternalMessageInfo
+
+func (m *MessageType) GetOpcode() uint32 {
+	if m != nil {
+		return m.Opcode
 	}
-	if this.ReplyFlag != that1.ReplyFlag {
-		return false
+	return 0
+}
+
+func (m *MessageType) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	if this.Opcode != that1.Opcode {
-		return false
+	return ""
+}
+
+type ErrorReply struct {
+	// code classifies the error, as one of the codes of the network package or a code of the application
+	Code uint32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	// message describes the error
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// details of the error, keyed by name
+	Details              map[string]string `protobuf:"bytes,3,rep,name=details" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ErrorReply) Reset()      { *m = ErrorReply{} }
+func (*ErrorReply) ProtoMessage() {}
+func (*ErrorReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_stream_c27a36122cd0c435, []int{16}
+}
+func (m *ErrorReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ErrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ErrorReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if this.DialAddress != that1.DialAddress {
-		return false
+}
+func (dst *ErrorReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ErrorReply.Merge(dst, src)
+}
+func (m *ErrorReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *ErrorReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ErrorReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ErrorReply proto.InternalMessageInfo
+
+func (m *ErrorReply) GetCode() uint32 {
+	if m != nil {
+		return m.Code
 	}
-	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
-		return false
+	return 0
+}
+
+func (m *ErrorReply) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	return true
+	return ""
 }
-func (this *Ping) VerboseEqual(that interface{}) error {
+
+func (m *ErrorReply) GetDetails() map[string]string {
+	if m != nil {
+		return m.Details
+	}
+	return nil
+}
+
+type StreamControl struct {
+	// credits are the number of replies to a streaming request the requester can buffer besides the ones it could already
+	Credits uint32 `protobuf:"varint,1,opt,name=credits,proto3" json:"credits,omitempty"`
+	// end marks the end of the replies to a streaming request
+	End bool `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	// cancel asks the responder to stop handling a request, and replying to it if it is streaming
+	Cancel               bool     `protobuf:"varint,3,opt,name=cancel,proto3" json:"cancel,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamControl) Reset()      { *m = StreamControl{} }
+func (*StreamControl) ProtoMessage() {}
+func (*StreamControl) Descriptor() ([]byte, []int) {
+	return fileDescriptor_stream_c27a36122cd0c435, []int{17}
+}
+func (m *StreamControl) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *StreamControl) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StreamControl.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (dst *StreamControl) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamControl.Merge(dst, src)
+}
+func (m *StreamControl) XXX_Size() int {
+	return m.Size()
+}
+func (m *StreamControl) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamControl.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamControl proto.InternalMessageInfo
+
+func (m *StreamControl) GetCredits() uint32 {
+	if m != nil {
+		return m.Credits
+	}
+	return 0
+}
+
+func (m *StreamControl) GetEnd() bool {
+	if m != nil {
+		return m.End
+	}
+	return false
+}
+
+func (m *StreamControl) GetCancel() bool {
+	if m != nil {
+		return m.Cancel
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*ID)(nil), "protobuf.ID")
+	proto.RegisterType((*Message)(nil), "protobuf.Message")
+	proto.RegisterType((*Ping)(nil), "protobuf.Ping")
+	proto.RegisterType((*Pong)(nil), "protobuf.Pong")
+	proto.RegisterType((*LookupNodeRequest)(nil), "protobuf.LookupNodeRequest")
+	proto.RegisterType((*LookupNodeResponse)(nil), "protobuf.LookupNodeResponse")
+	proto.RegisterType((*Bytes)(nil), "protobuf.Bytes")
+	proto.RegisterType((*Keepalive)(nil), "protobuf.Keepalive")
+	proto.RegisterType((*KeepaliveResponse)(nil), "protobuf.KeepaliveResponse")
+	proto.RegisterType((*Disconnect)(nil), "protobuf.Disconnect")
+	proto.RegisterType((*KeySuccession)(nil), "protobuf.KeySuccession")
+	proto.RegisterType((*SignedAddress)(nil), "protobuf.SignedAddress")
+	proto.RegisterType((*PeerRecord)(nil), "protobuf.PeerRecord")
+	proto.RegisterType((*Identify)(nil), "protobuf.Identify")
+	proto.RegisterType((*Capabilities)(nil), "protobuf.Capabilities")
+	proto.RegisterType((*MessageType)(nil), "protobuf.MessageType")
+	proto.RegisterType((*ErrorReply)(nil), "protobuf.ErrorReply")
+	proto.RegisterMapType((map[string]string)(nil), "protobuf.ErrorReply.DetailsEntry")
+	proto.RegisterType((*StreamControl)(nil), "protobuf.StreamControl")
+}
+func (this *ID) VerboseEqual(that interface{}) error {
 	if that == nil {
 		if this == nil {
 			return nil
@@ -722,36 +1134,45 @@ func (this *Ping) VerboseEqual(that interface{}) error {
 		return fmt.Errorf("that == nil && this != nil")
 	}
 
-	that1, ok := that.(*Ping)
+	that1, ok := that.(*ID)
 	if !ok {
-		that2, ok := that.(Ping)
+		that2, ok := that.(ID)
 		if ok {
 			that1 = &that2
 		} else {
-			return fmt.Errorf("that is not of type *Ping")
+			return fmt.Errorf("that is not of type *ID")
 		}
 	}
 	if that1 == nil {
 		if this == nil {
 			return nil
 		}
-		return fmt.Errorf("that is type *Ping but is nil && this != nil")
+		return fmt.Errorf("that is type *ID but is nil && this != nil")
 	} else if this == nil {
-		return fmt.Errorf("that is type *Ping but is not nil && this == nil")
+		return fmt.Errorf("that is type *ID but is not nil && this == nil")
+	}
+	if !bytes.Equal(this.NetKey, that1.NetKey) {
+		return fmt.Errorf("NetKey this(%v) Not Equal that(%v)", this.NetKey, that1.NetKey)
+	}
+	if this.Address != that1.Address {
+		return fmt.Errorf("Address this(%v) Not Equal that(%v)", this.Address, that1.Address)
+	}
+	if !bytes.Equal(this.Id, that1.Id) {
+		return fmt.Errorf("Id this(%v) Not Equal that(%v)", this.Id, that1.Id)
 	}
 	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
 		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
 	}
 	return nil
 }
-func (this *Ping) Equal(that interface{}) bool {
+func (this *ID) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
 	}
 
-	that1, ok := that.(*Ping)
+	that1, ok := that.(*ID)
 	if !ok {
-		that2, ok := that.(Ping)
+		that2, ok := that.(ID)
 		if ok {
 			that1 = &that2
 		} else {
@@ -763,12 +1184,21 @@ func (this *Ping) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
+	if !bytes.Equal(this.NetKey, that1.NetKey) {
+		return false
+	}
+	if this.Address != that1.Address {
+		return false
+	}
+	if !bytes.Equal(this.Id, that1.Id) {
+		return false
+	}
 	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
 		return false
 	}
 	return true
 }
-func (this *Pong) VerboseEqual(that interface{}) error {
+func (this *Message) VerboseEqual(that interface{}) error {
 	if that == nil {
 		if this == nil {
 			return nil
@@ -776,33 +1206,207 @@ func (this *Pong) VerboseEqual(that interface{}) error {
 		return fmt.Errorf("that == nil && this != nil")
 	}
 
-	that1, ok := that.(*Pong)
+	that1, ok := that.(*Message)
 	if !ok {
-		that2, ok := that.(Pong)
+		that2, ok := that.(Message)
 		if ok {
 			that1 = &that2
 		} else {
-			return fmt.Errorf("that is not of type *Pong")
+			return fmt.Errorf("that is not of type *Message")
 		}
 	}
 	if that1 == nil {
 		if this == nil {
 			return nil
 		}
-		return fmt.Errorf("that is type *Pong but is nil && this != nil")
+		return fmt.Errorf("that is type *Message but is nil && this != nil")
 	} else if this == nil {
-		return fmt.Errorf("that is type *Pong but is not nil && this == nil")
+		return fmt.Errorf("that is type *Message but is not nil && this == nil")
 	}
-	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
-		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	if !bytes.Equal(this.Message, that1.Message) {
+		return fmt.Errorf("Message this(%v) Not Equal that(%v)", this.Message, that1.Message)
 	}
-	return nil
-}
-func (this *Pong) Equal(that interface{}) bool {
-	if that == nil {
-		return this == nil
+	if !this.Sender.Equal(that1.Sender) {
+		return fmt.Errorf("Sender this(%v) Not Equal that(%v)", this.Sender, that1.Sender)
 	}
-
+	if !bytes.Equal(this.Signature, that1.Signature) {
+		return fmt.Errorf("Signature this(%v) Not Equal that(%v)", this.Signature, that1.Signature)
+	}
+	if this.RequestNonce != that1.RequestNonce {
+		return fmt.Errorf("RequestNonce this(%v) Not Equal that(%v)", this.RequestNonce, that1.RequestNonce)
+	}
+	if this.MessageNonce != that1.MessageNonce {
+		return fmt.Errorf("MessageNonce this(%v) Not Equal that(%v)", this.MessageNonce, that1.MessageNonce)
+	}
+	if this.ReplyFlag != that1.ReplyFlag {
+		return fmt.Errorf("ReplyFlag this(%v) Not Equal that(%v)", this.ReplyFlag, that1.ReplyFlag)
+	}
+	if this.Opcode != that1.Opcode {
+		return fmt.Errorf("Opcode this(%v) Not Equal that(%v)", this.Opcode, that1.Opcode)
+	}
+	if this.DialAddress != that1.DialAddress {
+		return fmt.Errorf("DialAddress this(%v) Not Equal that(%v)", this.DialAddress, that1.DialAddress)
+	}
+	if this.Codec != that1.Codec {
+		return fmt.Errorf("Codec this(%v) Not Equal that(%v)", this.Codec, that1.Codec)
+	}
+	if this.StreamWindow != that1.StreamWindow {
+		return fmt.Errorf("StreamWindow this(%v) Not Equal that(%v)", this.StreamWindow, that1.StreamWindow)
+	}
+	if this.Timeout != that1.Timeout {
+		return fmt.Errorf("Timeout this(%v) Not Equal that(%v)", this.Timeout, that1.Timeout)
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	}
+	return nil
+}
+func (this *Message) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Message)
+	if !ok {
+		that2, ok := that.(Message)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.Message, that1.Message) {
+		return false
+	}
+	if !this.Sender.Equal(that1.Sender) {
+		return false
+	}
+	if !bytes.Equal(this.Signature, that1.Signature) {
+		return false
+	}
+	if this.RequestNonce != that1.RequestNonce {
+		return false
+	}
+	if this.MessageNonce != that1.MessageNonce {
+		return false
+	}
+	if this.ReplyFlag != that1.ReplyFlag {
+		return false
+	}
+	if this.Opcode != that1.Opcode {
+		return false
+	}
+	if this.DialAddress != that1.DialAddress {
+		return false
+	}
+	if this.Codec != that1.Codec {
+		return false
+	}
+	if this.StreamWindow != that1.StreamWindow {
+		return false
+	}
+	if this.Timeout != that1.Timeout {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *Ping) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*Ping)
+	if !ok {
+		that2, ok := that.(Ping)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *Ping")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *Ping but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *Ping but is not nil && this == nil")
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	}
+	return nil
+}
+func (this *Ping) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Ping)
+	if !ok {
+		that2, ok := that.(Ping)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *Pong) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*Pong)
+	if !ok {
+		that2, ok := that.(Pong)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *Pong")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *Pong but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *Pong but is not nil && this == nil")
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	}
+	return nil
+}
+func (this *Pong) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
 	that1, ok := that.(*Pong)
 	if !ok {
 		that2, ok := that.(Pong)
@@ -1174,752 +1778,3513 @@ func (this *Disconnect) Equal(that interface{}) bool {
 	}
 	return true
 }
-func (this *ID) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *KeySuccession) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
 	}
-	s := make([]string, 0, 7)
-	s = append(s, "&protobuf.ID{")
-	s = append(s, "NetKey: "+fmt.Sprintf("%#v", this.NetKey)+",\n")
-	s = append(s, "Address: "+fmt.Sprintf("%#v", this.Address)+",\n")
-	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+
+	that1, ok := that.(*KeySuccession)
+	if !ok {
+		that2, ok := that.(KeySuccession)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *KeySuccession")
+		}
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *Message) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *KeySuccession but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *KeySuccession but is not nil && this == nil")
 	}
-	s := make([]string, 0, 12)
-	s = append(s, "&protobuf.Message{")
-	s = append(s, "Message: "+fmt.Sprintf("%#v", this.Message)+",\n")
-	if this.Sender != nil {
-		s = append(s, "Sender: "+fmt.Sprintf("%#v", this.Sender)+",\n")
+	if !this.Previous.Equal(that1.Previous) {
+		return fmt.Errorf("Previous this(%v) Not Equal that(%v)", this.Previous, that1.Previous)
 	}
-	s = append(s, "Signature: "+fmt.Sprintf("%#v", this.Signature)+",\n")
-	s = append(s, "RequestNonce: "+fmt.Sprintf("%#v", this.RequestNonce)+",\n")
-	s = append(s, "MessageNonce: "+fmt.Sprintf("%#v", this.MessageNonce)+",\n")
-	s = append(s, "ReplyFlag: "+fmt.Sprintf("%#v", this.ReplyFlag)+",\n")
-	s = append(s, "Opcode: "+fmt.Sprintf("%#v", this.Opcode)+",\n")
-	s = append(s, "DialAddress: "+fmt.Sprintf("%#v", this.DialAddress)+",\n")
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	if !this.Successor.Equal(that1.Successor) {
+		return fmt.Errorf("Successor this(%v) Not Equal that(%v)", this.Successor, that1.Successor)
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *Ping) GoString() string {
-	if this == nil {
-		return "nil"
+	if this.Timestamp != that1.Timestamp {
+		return fmt.Errorf("Timestamp this(%v) Not Equal that(%v)", this.Timestamp, that1.Timestamp)
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&protobuf.Ping{")
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	if !bytes.Equal(this.Signature, that1.Signature) {
+		return fmt.Errorf("Signature this(%v) Not Equal that(%v)", this.Signature, that1.Signature)
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *Pong) GoString() string {
-	if this == nil {
-		return "nil"
+	if !bytes.Equal(this.SuccessorSignature, that1.SuccessorSignature) {
+		return fmt.Errorf("SuccessorSignature this(%v) Not Equal that(%v)", this.SuccessorSignature, that1.SuccessorSignature)
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&protobuf.Pong{")
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
+	return nil
 }
-func (this *LookupNodeRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *KeySuccession) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&protobuf.LookupNodeRequest{")
-	if this.Target != nil {
-		s = append(s, "Target: "+fmt.Sprintf("%#v", this.Target)+",\n")
+
+	that1, ok := that.(*KeySuccession)
+	if !ok {
+		that2, ok := that.(KeySuccession)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *LookupNodeResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if !this.Previous.Equal(that1.Previous) {
+		return false
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&protobuf.LookupNodeResponse{")
-	if this.Peers != nil {
-		s = append(s, "Peers: "+fmt.Sprintf("%#v", this.Peers)+",\n")
+	if !this.Successor.Equal(that1.Successor) {
+		return false
 	}
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	if this.Timestamp != that1.Timestamp {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *Bytes) GoString() string {
-	if this == nil {
-		return "nil"
+	if !bytes.Equal(this.Signature, that1.Signature) {
+		return false
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&protobuf.Bytes{")
-	s = append(s, "Data: "+fmt.Sprintf("%#v", this.Data)+",\n")
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	if !bytes.Equal(this.SuccessorSignature, that1.SuccessorSignature) {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
 }
-func (this *Keepalive) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *SignedAddress) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&protobuf.Keepalive{")
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+
+	that1, ok := that.(*SignedAddress)
+	if !ok {
+		that2, ok := that.(SignedAddress)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *SignedAddress")
+		}
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *KeepaliveResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *SignedAddress but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *SignedAddress but is not nil && this == nil")
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&protobuf.KeepaliveResponse{")
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	if this.Address != that1.Address {
+		return fmt.Errorf("Address this(%v) Not Equal that(%v)", this.Address, that1.Address)
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *Disconnect) GoString() string {
-	if this == nil {
-		return "nil"
+	if this.Timestamp != that1.Timestamp {
+		return fmt.Errorf("Timestamp this(%v) Not Equal that(%v)", this.Timestamp, that1.Timestamp)
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&protobuf.Disconnect{")
-	if this.XXX_unrecognized != nil {
-		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	if !bytes.Equal(this.Signature, that1.Signature) {
+		return fmt.Errorf("Signature this(%v) Not Equal that(%v)", this.Signature, that1.Signature)
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func valueToGoStringStream(v interface{}, typ string) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
-		return "nil"
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+	return nil
 }
-func (m *ID) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *SignedAddress) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	return dAtA[:n], nil
-}
 
-func (m *ID) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.NetKey) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(len(m.NetKey)))
-		i += copy(dAtA[i:], m.NetKey)
+	that1, ok := that.(*SignedAddress)
+	if !ok {
+		that2, ok := that.(SignedAddress)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if len(m.Address) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(len(m.Address)))
-		i += copy(dAtA[i:], m.Address)
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	if len(m.Id) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(len(m.Id)))
-		i += copy(dAtA[i:], m.Id)
+	if this.Address != that1.Address {
+		return false
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	if this.Timestamp != that1.Timestamp {
+		return false
 	}
-	return i, nil
-}
-
-func (m *Message) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if !bytes.Equal(this.Signature, that1.Signature) {
+		return false
 	}
-	return dAtA[:n], nil
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
 }
+func (this *PeerRecord) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
 
-func (m *Message) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Message) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(len(m.Message)))
-		i += copy(dAtA[i:], m.Message)
+	that1, ok := that.(*PeerRecord)
+	if !ok {
+		that2, ok := that.(PeerRecord)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *PeerRecord")
+		}
 	}
-	if m.Sender != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(m.Sender.Size()))
-		n1, err := m.Sender.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
+	if that1 == nil {
+		if this == nil {
+			return nil
 		}
-		i += n1
+		return fmt.Errorf("that is type *PeerRecord but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *PeerRecord but is not nil && this == nil")
 	}
-	if len(m.Signature) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(len(m.Signature)))
-		i += copy(dAtA[i:], m.Signature)
+	if !this.Id.Equal(that1.Id) {
+		return fmt.Errorf("Id this(%v) Not Equal that(%v)", this.Id, that1.Id)
 	}
-	if m.RequestNonce != 0 {
-		dAtA[i] = 0x20
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(m.RequestNonce))
+	if len(this.Addresses) != len(that1.Addresses) {
+		return fmt.Errorf("Addresses this(%v) Not Equal that(%v)", len(this.Addresses), len(that1.Addresses))
 	}
-	if m.MessageNonce != 0 {
-		dAtA[i] = 0x28
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(m.MessageNonce))
+	for i := range this.Addresses {
+		if !this.Addresses[i].Equal(that1.Addresses[i]) {
+			return fmt.Errorf("Addresses this[%v](%v) Not Equal that[%v](%v)", i, this.Addresses[i], i, that1.Addresses[i])
+		}
 	}
-	if m.ReplyFlag {
-		dAtA[i] = 0x30
-		i++
-		if m.ReplyFlag {
-			dAtA[i] = 1
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	}
+	return nil
+}
+func (this *PeerRecord) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*PeerRecord)
+	if !ok {
+		that2, ok := that.(PeerRecord)
+		if ok {
+			that1 = &that2
 		} else {
-			dAtA[i] = 0
+			return false
 		}
-		i++
 	}
-	if m.Opcode != 0 {
-		dAtA[i] = 0x38
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(m.Opcode))
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	if len(m.DialAddress) > 0 {
-		dAtA[i] = 0x42
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(len(m.DialAddress)))
-		i += copy(dAtA[i:], m.DialAddress)
+	if !this.Id.Equal(that1.Id) {
+		return false
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	if len(this.Addresses) != len(that1.Addresses) {
+		return false
 	}
-	return i, nil
+	for i := range this.Addresses {
+		if !this.Addresses[i].Equal(that1.Addresses[i]) {
+			return false
+		}
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
 }
-
-func (m *Ping) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *Identify) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
 	}
-	return dAtA[:n], nil
-}
 
-func (m *Ping) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	that1, ok := that.(*Identify)
+	if !ok {
+		that2, ok := that.(Identify)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *Identify")
+		}
 	}
-	return i, nil
-}
-
-func (m *Pong) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *Identify but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *Identify but is not nil && this == nil")
 	}
-	return dAtA[:n], nil
-}
-
-func (m *Pong) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	if this.AgentVersion != that1.AgentVersion {
+		return fmt.Errorf("AgentVersion this(%v) Not Equal that(%v)", this.AgentVersion, that1.AgentVersion)
 	}
-	return i, nil
-}
-
-func (m *LookupNodeRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if len(this.Protocols) != len(that1.Protocols) {
+		return fmt.Errorf("Protocols this(%v) Not Equal that(%v)", len(this.Protocols), len(that1.Protocols))
 	}
-	return dAtA[:n], nil
-}
-
-func (m *LookupNodeRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Target != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(m.Target.Size()))
-		n2, err := m.Target.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
+	for i := range this.Protocols {
+		if this.Protocols[i] != that1.Protocols[i] {
+			return fmt.Errorf("Protocols this[%v](%v) Not Equal that[%v](%v)", i, this.Protocols[i], i, that1.Protocols[i])
 		}
-		i += n2
-	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return i, nil
-}
-
-func (m *LookupNodeResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if len(this.Opcodes) != len(that1.Opcodes) {
+		return fmt.Errorf("Opcodes this(%v) Not Equal that(%v)", len(this.Opcodes), len(that1.Opcodes))
 	}
-	return dAtA[:n], nil
-}
-
-func (m *LookupNodeResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Peers) > 0 {
-		for _, msg := range m.Peers {
-			dAtA[i] = 0xa
-			i++
-			i = encodeVarintStream(dAtA, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(dAtA[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
+	for i := range this.Opcodes {
+		if this.Opcodes[i] != that1.Opcodes[i] {
+			return fmt.Errorf("Opcodes this[%v](%v) Not Equal that[%v](%v)", i, this.Opcodes[i], i, that1.Opcodes[i])
 		}
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	if len(this.ListenAddresses) != len(that1.ListenAddresses) {
+		return fmt.Errorf("ListenAddresses this(%v) Not Equal that(%v)", len(this.ListenAddresses), len(that1.ListenAddresses))
 	}
-	return i, nil
-}
-
-func (m *Bytes) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	for i := range this.ListenAddresses {
+		if this.ListenAddresses[i] != that1.ListenAddresses[i] {
+			return fmt.Errorf("ListenAddresses this[%v](%v) Not Equal that[%v](%v)", i, this.ListenAddresses[i], i, that1.ListenAddresses[i])
+		}
 	}
-	return dAtA[:n], nil
-}
-
-func (m *Bytes) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Data) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintStream(dAtA, i, uint64(len(m.Data)))
-		i += copy(dAtA[i:], m.Data)
+	if this.ObservedAddress != that1.ObservedAddress {
+		return fmt.Errorf("ObservedAddress this(%v) Not Equal that(%v)", this.ObservedAddress, that1.ObservedAddress)
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
 	}
-	return i, nil
+	return nil
 }
-
-func (m *Keepalive) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *Identify) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	return dAtA[:n], nil
-}
 
-func (m *Keepalive) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	that1, ok := that.(*Identify)
+	if !ok {
+		that2, ok := that.(Identify)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	return i, nil
-}
-
-func (m *KeepaliveResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	return dAtA[:n], nil
-}
-
-func (m *KeepaliveResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	if this.AgentVersion != that1.AgentVersion {
+		return false
 	}
-	return i, nil
-}
-
-func (m *Disconnect) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if len(this.Protocols) != len(that1.Protocols) {
+		return false
 	}
-	return dAtA[:n], nil
-}
-
-func (m *Disconnect) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	for i := range this.Protocols {
+		if this.Protocols[i] != that1.Protocols[i] {
+			return false
+		}
 	}
-	return i, nil
-}
-
-func encodeVarintStream(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+	if len(this.Opcodes) != len(that1.Opcodes) {
+		return false
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
-}
-func (m *ID) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.NetKey)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+	for i := range this.Opcodes {
+		if this.Opcodes[i] != that1.Opcodes[i] {
+			return false
+		}
 	}
-	l = len(m.Address)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+	if len(this.ListenAddresses) != len(that1.ListenAddresses) {
+		return false
 	}
-	l = len(m.Id)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+	for i := range this.ListenAddresses {
+		if this.ListenAddresses[i] != that1.ListenAddresses[i] {
+			return false
+		}
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if this.ObservedAddress != that1.ObservedAddress {
+		return false
 	}
-	return n
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
 }
-
-func (m *Message) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Message)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+func (this *Capabilities) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
 	}
-	if m.Sender != nil {
-		l = m.Sender.Size()
-		n += 1 + l + sovStream(uint64(l))
+
+	that1, ok := that.(*Capabilities)
+	if !ok {
+		that2, ok := that.(Capabilities)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *Capabilities")
+		}
 	}
-	l = len(m.Signature)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *Capabilities but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *Capabilities but is not nil && this == nil")
 	}
-	if m.RequestNonce != 0 {
-		n += 1 + sovStream(uint64(m.RequestNonce))
+	if len(this.Opcodes) != len(that1.Opcodes) {
+		return fmt.Errorf("Opcodes this(%v) Not Equal that(%v)", len(this.Opcodes), len(that1.Opcodes))
 	}
-	if m.MessageNonce != 0 {
-		n += 1 + sovStream(uint64(m.MessageNonce))
+	for i := range this.Opcodes {
+		if this.Opcodes[i] != that1.Opcodes[i] {
+			return fmt.Errorf("Opcodes this[%v](%v) Not Equal that[%v](%v)", i, this.Opcodes[i], i, that1.Opcodes[i])
+		}
 	}
-	if m.ReplyFlag {
-		n += 2
+	if len(this.Protocols) != len(that1.Protocols) {
+		return fmt.Errorf("Protocols this(%v) Not Equal that(%v)", len(this.Protocols), len(that1.Protocols))
 	}
-	if m.Opcode != 0 {
-		n += 1 + sovStream(uint64(m.Opcode))
+	for i := range this.Protocols {
+		if this.Protocols[i] != that1.Protocols[i] {
+			return fmt.Errorf("Protocols this[%v](%v) Not Equal that[%v](%v)", i, this.Protocols[i], i, that1.Protocols[i])
+		}
 	}
-	l = len(m.DialAddress)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+	if len(this.MessageTypes) != len(that1.MessageTypes) {
+		return fmt.Errorf("MessageTypes this(%v) Not Equal that(%v)", len(this.MessageTypes), len(that1.MessageTypes))
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	for i := range this.MessageTypes {
+		if !this.MessageTypes[i].Equal(that1.MessageTypes[i]) {
+			return fmt.Errorf("MessageTypes this[%v](%v) Not Equal that[%v](%v)", i, this.MessageTypes[i], i, that1.MessageTypes[i])
+		}
 	}
-	return n
-}
-
-func (m *Ping) Size() (n int) {
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(this.Codecs) != len(that1.Codecs) {
+		return fmt.Errorf("Codecs this(%v) Not Equal that(%v)", len(this.Codecs), len(that1.Codecs))
 	}
-	return n
-}
-
-func (m *Pong) Size() (n int) {
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	for i := range this.Codecs {
+		if this.Codecs[i] != that1.Codecs[i] {
+			return fmt.Errorf("Codecs this[%v](%v) Not Equal that[%v](%v)", i, this.Codecs[i], i, that1.Codecs[i])
+		}
 	}
-	return n
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	}
+	return nil
 }
+func (this *Capabilities) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
 
-func (m *LookupNodeRequest) Size() (n int) {
-	var l int
-	_ = l
-	if m.Target != nil {
-		l = m.Target.Size()
-		n += 1 + l + sovStream(uint64(l))
+	that1, ok := that.(*Capabilities)
+	if !ok {
+		that2, ok := that.(Capabilities)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	return n
-}
-
-func (m *LookupNodeResponse) Size() (n int) {
-	var l int
-	_ = l
-	if len(m.Peers) > 0 {
-		for _, e := range m.Peers {
-			l = e.Size()
-			n += 1 + l + sovStream(uint64(l))
+	if len(this.Opcodes) != len(that1.Opcodes) {
+		return false
+	}
+	for i := range this.Opcodes {
+		if this.Opcodes[i] != that1.Opcodes[i] {
+			return false
 		}
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(this.Protocols) != len(that1.Protocols) {
+		return false
 	}
-	return n
-}
-
-func (m *Bytes) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Data)
-	if l > 0 {
-		n += 1 + l + sovStream(uint64(l))
+	for i := range this.Protocols {
+		if this.Protocols[i] != that1.Protocols[i] {
+			return false
+		}
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(this.MessageTypes) != len(that1.MessageTypes) {
+		return false
 	}
-	return n
-}
-
-func (m *Keepalive) Size() (n int) {
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	for i := range this.MessageTypes {
+		if !this.MessageTypes[i].Equal(that1.MessageTypes[i]) {
+			return false
+		}
 	}
-	return n
-}
-
-func (m *KeepaliveResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(this.Codecs) != len(that1.Codecs) {
+		return false
 	}
-	return n
-}
-
-func (m *Disconnect) Size() (n int) {
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	for i := range this.Codecs {
+		if this.Codecs[i] != that1.Codecs[i] {
+			return false
+		}
 	}
-	return n
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
 }
+func (this *MessageType) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
 
-func sovStream(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
+	that1, ok := that.(*MessageType)
+	if !ok {
+		that2, ok := that.(MessageType)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *MessageType")
 		}
 	}
-	return n
-}
-func sozStream(x uint64) (n int) {
-	return sovStream(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (this *ID) String() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *MessageType but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *MessageType but is not nil && this == nil")
 	}
-	s := strings.Join([]string{`&ID{`,
-		`NetKey:` + fmt.Sprintf("%v", this.NetKey) + `,`,
-		`Address:` + fmt.Sprintf("%v", this.Address) + `,`,
-		`Id:` + fmt.Sprintf("%v", this.Id) + `,`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *Message) String() string {
-	if this == nil {
-		return "nil"
+	if this.Opcode != that1.Opcode {
+		return fmt.Errorf("Opcode this(%v) Not Equal that(%v)", this.Opcode, that1.Opcode)
 	}
-	s := strings.Join([]string{`&Message{`,
-		`Message:` + fmt.Sprintf("%v", this.Message) + `,`,
-		`Sender:` + strings.Replace(fmt.Sprintf("%v", this.Sender), "ID", "ID", 1) + `,`,
-		`Signature:` + fmt.Sprintf("%v", this.Signature) + `,`,
-		`RequestNonce:` + fmt.Sprintf("%v", this.RequestNonce) + `,`,
-		`MessageNonce:` + fmt.Sprintf("%v", this.MessageNonce) + `,`,
-		`ReplyFlag:` + fmt.Sprintf("%v", this.ReplyFlag) + `,`,
-		`Opcode:` + fmt.Sprintf("%v", this.Opcode) + `,`,
-		`DialAddress:` + fmt.Sprintf("%v", this.DialAddress) + `,`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *Ping) String() string {
-	if this == nil {
-		return "nil"
+	if this.Name != that1.Name {
+		return fmt.Errorf("Name this(%v) Not Equal that(%v)", this.Name, that1.Name)
 	}
-	s := strings.Join([]string{`&Ping{`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *Pong) String() string {
-	if this == nil {
-		return "nil"
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
 	}
-	s := strings.Join([]string{`&Pong{`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
+	return nil
 }
-func (this *LookupNodeRequest) String() string {
-	if this == nil {
-		return "nil"
+func (this *MessageType) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s := strings.Join([]string{`&LookupNodeRequest{`,
-		`Target:` + strings.Replace(fmt.Sprintf("%v", this.Target), "ID", "ID", 1) + `,`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *LookupNodeResponse) String() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*MessageType)
+	if !ok {
+		that2, ok := that.(MessageType)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := strings.Join([]string{`&LookupNodeResponse{`,
-		`Peers:` + strings.Replace(fmt.Sprintf("%v", this.Peers), "ID", "ID", 1) + `,`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *Bytes) String() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	s := strings.Join([]string{`&Bytes{`,
-		`Data:` + fmt.Sprintf("%v", this.Data) + `,`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
+	if this.Opcode != that1.Opcode {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
 }
-func (this *Keepalive) String() string {
+func (this *ErrorReply) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*ErrorReply)
+	if !ok {
+		that2, ok := that.(ErrorReply)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *ErrorReply")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *ErrorReply but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *ErrorReply but is not nil && this == nil")
+	}
+	if this.Code != that1.Code {
+		return fmt.Errorf("Code this(%v) Not Equal that(%v)", this.Code, that1.Code)
+	}
+	if this.Message != that1.Message {
+		return fmt.Errorf("Message this(%v) Not Equal that(%v)", this.Message, that1.Message)
+	}
+	if len(this.Details) != len(that1.Details) {
+		return fmt.Errorf("Details this(%v) Not Equal that(%v)", len(this.Details), len(that1.Details))
+	}
+	for i := range this.Details {
+		if this.Details[i] != that1.Details[i] {
+			return fmt.Errorf("Details this[%v](%v) Not Equal that[%v](%v)", i, this.Details[i], i, that1.Details[i])
+		}
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	}
+	return nil
+}
+func (this *ErrorReply) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ErrorReply)
+	if !ok {
+		that2, ok := that.(ErrorReply)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Code != that1.Code {
+		return false
+	}
+	if this.Message != that1.Message {
+		return false
+	}
+	if len(this.Details) != len(that1.Details) {
+		return false
+	}
+	for i := range this.Details {
+		if this.Details[i] != that1.Details[i] {
+			return false
+		}
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *StreamControl) VerboseEqual(that interface{}) error {
+	if that == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that == nil && this != nil")
+	}
+
+	that1, ok := that.(*StreamControl)
+	if !ok {
+		that2, ok := that.(StreamControl)
+		if ok {
+			that1 = &that2
+		} else {
+			return fmt.Errorf("that is not of type *StreamControl")
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return nil
+		}
+		return fmt.Errorf("that is type *StreamControl but is nil && this != nil")
+	} else if this == nil {
+		return fmt.Errorf("that is type *StreamControl but is not nil && this == nil")
+	}
+	if this.Credits != that1.Credits {
+		return fmt.Errorf("Credits this(%v) Not Equal that(%v)", this.Credits, that1.Credits)
+	}
+	if this.End != that1.End {
+		return fmt.Errorf("End this(%v) Not Equal that(%v)", this.End, that1.End)
+	}
+	if this.Cancel != that1.Cancel {
+		return fmt.Errorf("Cancel this(%v) Not Equal that(%v)", this.Cancel, that1.Cancel)
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return fmt.Errorf("XXX_unrecognized this(%v) Not Equal that(%v)", this.XXX_unrecognized, that1.XXX_unrecognized)
+	}
+	return nil
+}
+func (this *StreamControl) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*StreamControl)
+	if !ok {
+		that2, ok := that.(StreamControl)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Credits != that1.Credits {
+		return false
+	}
+	if this.End != that1.End {
+		return false
+	}
+	if this.Cancel != that1.Cancel {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *ID) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&Keepalive{`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 7)
+	s = append(s, "&protobuf.ID{")
+	s = append(s, "NetKey: "+fmt.Sprintf("%#v", this.NetKey)+",\n")
+	s = append(s, "Address: "+fmt.Sprintf("%#v", this.Address)+",\n")
+	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *KeepaliveResponse) String() string {
+func (this *Message) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&KeepaliveResponse{`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 15)
+	s = append(s, "&protobuf.Message{")
+	s = append(s, "Message: "+fmt.Sprintf("%#v", this.Message)+",\n")
+	if this.Sender != nil {
+		s = append(s, "Sender: "+fmt.Sprintf("%#v", this.Sender)+",\n")
+	}
+	s = append(s, "Signature: "+fmt.Sprintf("%#v", this.Signature)+",\n")
+	s = append(s, "RequestNonce: "+fmt.Sprintf("%#v", this.RequestNonce)+",\n")
+	s = append(s, "MessageNonce: "+fmt.Sprintf("%#v", this.MessageNonce)+",\n")
+	s = append(s, "ReplyFlag: "+fmt.Sprintf("%#v", this.ReplyFlag)+",\n")
+	s = append(s, "Opcode: "+fmt.Sprintf("%#v", this.Opcode)+",\n")
+	s = append(s, "DialAddress: "+fmt.Sprintf("%#v", this.DialAddress)+",\n")
+	s = append(s, "Codec: "+fmt.Sprintf("%#v", this.Codec)+",\n")
+	s = append(s, "StreamWindow: "+fmt.Sprintf("%#v", this.StreamWindow)+",\n")
+	s = append(s, "Timeout: "+fmt.Sprintf("%#v", this.Timeout)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *Disconnect) String() string {
+func (this *Ping) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&protobuf.Ping{")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Pong) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&protobuf.Pong{")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *LookupNodeRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&protobuf.LookupNodeRequest{")
+	if this.Target != nil {
+		s = append(s, "Target: "+fmt.Sprintf("%#v", this.Target)+",\n")
+	}
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *LookupNodeResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&protobuf.LookupNodeResponse{")
+	if this.Peers != nil {
+		s = append(s, "Peers: "+fmt.Sprintf("%#v", this.Peers)+",\n")
+	}
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Bytes) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&protobuf.Bytes{")
+	s = append(s, "Data: "+fmt.Sprintf("%#v", this.Data)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Keepalive) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&protobuf.Keepalive{")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *KeepaliveResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&protobuf.KeepaliveResponse{")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Disconnect) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&Disconnect{`,
-		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func valueToStringStream(v interface{}) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
-		return "nil"
+	s := make([]string, 0, 4)
+	s = append(s, "&protobuf.Disconnect{")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *KeySuccession) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&protobuf.KeySuccession{")
+	if this.Previous != nil {
+		s = append(s, "Previous: "+fmt.Sprintf("%#v", this.Previous)+",\n")
+	}
+	if this.Successor != nil {
+		s = append(s, "Successor: "+fmt.Sprintf("%#v", this.Successor)+",\n")
+	}
+	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
+	s = append(s, "Signature: "+fmt.Sprintf("%#v", this.Signature)+",\n")
+	s = append(s, "SuccessorSignature: "+fmt.Sprintf("%#v", this.SuccessorSignature)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *SignedAddress) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&protobuf.SignedAddress{")
+	s = append(s, "Address: "+fmt.Sprintf("%#v", this.Address)+",\n")
+	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
+	s = append(s, "Signature: "+fmt.Sprintf("%#v", this.Signature)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *PeerRecord) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&protobuf.PeerRecord{")
+	if this.Id != nil {
+		s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
+	}
+	if this.Addresses != nil {
+		s = append(s, "Addresses: "+fmt.Sprintf("%#v", this.Addresses)+",\n")
+	}
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Identify) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&protobuf.Identify{")
+	s = append(s, "AgentVersion: "+fmt.Sprintf("%#v", this.AgentVersion)+",\n")
+	s = append(s, "Protocols: "+fmt.Sprintf("%#v", this.Protocols)+",\n")
+	s = append(s, "Opcodes: "+fmt.Sprintf("%#v", this.Opcodes)+",\n")
+	s = append(s, "ListenAddresses: "+fmt.Sprintf("%#v", this.ListenAddresses)+",\n")
+	s = append(s, "ObservedAddress: "+fmt.Sprintf("%#v", this.ObservedAddress)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Capabilities) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&protobuf.Capabilities{")
+	s = append(s, "Opcodes: "+fmt.Sprintf("%#v", this.Opcodes)+",\n")
+	s = append(s, "Protocols: "+fmt.Sprintf("%#v", this.Protocols)+",\n")
+	if this.MessageTypes != nil {
+		s = append(s, "MessageTypes: "+fmt.Sprintf("%#v", this.MessageTypes)+",\n")
+	}
+	s = append(s, "Codecs: "+fmt.Sprintf("%#v", this.Codecs)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *MessageType) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&protobuf.MessageType{")
+	s = append(s, "Opcode: "+fmt.Sprintf("%#v", this.Opcode)+",\n")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ErrorReply) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&protobuf.ErrorReply{")
+	s = append(s, "Code: "+fmt.Sprintf("%#v", this.Code)+",\n")
+	s = append(s, "Message: "+fmt.Sprintf("%#v", this.Message)+",\n")
+	keysForDetails := make([]string, 0, len(this.Details))
+	for k, _ := range this.Details {
+		keysForDetails = append(keysForDetails, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForDetails)
+	mapStringForDetails := "map[string]string{"
+	for _, k := range keysForDetails {
+		mapStringForDetails += fmt.Sprintf("%#v: %#v,", k, this.Details[k])
+	}
+	mapStringForDetails += "}"
+	if this.Details != nil {
+		s = append(s, "Details: "+mapStringForDetails+",\n")
+	}
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *StreamControl) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&protobuf.StreamControl{")
+	s = append(s, "Credits: "+fmt.Sprintf("%#v", this.Credits)+",\n")
+	s = append(s, "End: "+fmt.Sprintf("%#v", this.End)+",\n")
+	s = append(s, "Cancel: "+fmt.Sprintf("%#v", this.Cancel)+",\n")
+	if this.XXX_unrecognized != nil {
+		s = append(s, "XXX_unrecognized:"+fmt.Sprintf("%#v", this.XXX_unrecognized)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringStream(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+func (m *ID) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ID) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.NetKey) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.NetKey)))
+		i += copy(dAtA[i:], m.NetKey)
+	}
+	if len(m.Address) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Address)))
+		i += copy(dAtA[i:], m.Address)
+	}
+	if len(m.Id) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Id)))
+		i += copy(dAtA[i:], m.Id)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Message) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Message) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Message) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if m.Sender != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Sender.Size()))
+		n1, err := m.Sender.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
+	}
+	if m.RequestNonce != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.RequestNonce))
+	}
+	if m.MessageNonce != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.MessageNonce))
+	}
+	if m.ReplyFlag {
+		dAtA[i] = 0x30
+		i++
+		if m.ReplyFlag {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Opcode != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Opcode))
+	}
+	if len(m.DialAddress) > 0 {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.DialAddress)))
+		i += copy(dAtA[i:], m.DialAddress)
+	}
+	if len(m.Codec) > 0 {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Codec)))
+		i += copy(dAtA[i:], m.Codec)
+	}
+	if m.StreamWindow != 0 {
+		dAtA[i] = 0x50
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.StreamWindow))
+	}
+	if m.Timeout != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Timeout))
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Ping) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Ping) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Pong) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Pong) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *LookupNodeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LookupNodeRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Target != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Target.Size()))
+		n2, err := m.Target.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *LookupNodeResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LookupNodeResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Peers) > 0 {
+		for _, msg := range m.Peers {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintStream(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Bytes) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Bytes) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Data) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Data)))
+		i += copy(dAtA[i:], m.Data)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Keepalive) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Keepalive) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *KeepaliveResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeepaliveResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Disconnect) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Disconnect) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *KeySuccession) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeySuccession) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Previous != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Previous.Size()))
+		n3, err := m.Previous.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	if m.Successor != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Successor.Size()))
+		n4, err := m.Successor.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	if m.Timestamp != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Timestamp))
+	}
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
+	}
+	if len(m.SuccessorSignature) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.SuccessorSignature)))
+		i += copy(dAtA[i:], m.SuccessorSignature)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *SignedAddress) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SignedAddress) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Address) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Address)))
+		i += copy(dAtA[i:], m.Address)
+	}
+	if m.Timestamp != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Timestamp))
+	}
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *PeerRecord) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PeerRecord) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Id != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Id.Size()))
+		n5, err := m.Id.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n5
+	}
+	if len(m.Addresses) > 0 {
+		for _, msg := range m.Addresses {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintStream(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Identify) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Identify) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.AgentVersion) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.AgentVersion)))
+		i += copy(dAtA[i:], m.AgentVersion)
+	}
+	if len(m.Protocols) > 0 {
+		for _, s := range m.Protocols {
+			dAtA[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Opcodes) > 0 {
+		dAtA7 := make([]byte, len(m.Opcodes)*10)
+		var j6 int
+		for _, num := range m.Opcodes {
+			for num >= 1<<7 {
+				dAtA7[j6] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j6++
+			}
+			dAtA7[j6] = uint8(num)
+			j6++
+		}
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(j6))
+		i += copy(dAtA[i:], dAtA7[:j6])
+	}
+	if len(m.ListenAddresses) > 0 {
+		for _, s := range m.ListenAddresses {
+			dAtA[i] = 0x22
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.ObservedAddress) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.ObservedAddress)))
+		i += copy(dAtA[i:], m.ObservedAddress)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *Capabilities) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Capabilities) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Opcodes) > 0 {
+		dAtA9 := make([]byte, len(m.Opcodes)*10)
+		var j8 int
+		for _, num := range m.Opcodes {
+			for num >= 1<<7 {
+				dAtA9[j8] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j8++
+			}
+			dAtA9[j8] = uint8(num)
+			j8++
+		}
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(j8))
+		i += copy(dAtA[i:], dAtA9[:j8])
+	}
+	if len(m.Protocols) > 0 {
+		for _, s := range m.Protocols {
+			dAtA[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.MessageTypes) > 0 {
+		for _, msg := range m.MessageTypes {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintStream(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Codecs) > 0 {
+		for _, s := range m.Codecs {
+			dAtA[i] = 0x22
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *MessageType) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MessageType) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Opcode != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Opcode))
+	}
+	if len(m.Name) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ErrorReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ErrorReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Code != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Code))
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if len(m.Details) > 0 {
+		for k, _ := range m.Details {
+			dAtA[i] = 0x1a
+			i++
+			v := m.Details[k]
+			mapSize := 1 + len(k) + sovStream(uint64(len(k))) + 1 + len(v) + sovStream(uint64(len(v)))
+			i = encodeVarintStream(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintStream(dAtA, i, uint64(len(k)))
+			i += copy(dAtA[i:], k)
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintStream(dAtA, i, uint64(len(v)))
+			i += copy(dAtA[i:], v)
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *StreamControl) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StreamControl) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Credits != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintStream(dAtA, i, uint64(m.Credits))
+	}
+	if m.End {
+		dAtA[i] = 0x10
+		i++
+		if m.End {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Cancel {
+		dAtA[i] = 0x18
+		i++
+		if m.Cancel {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func encodeVarintStream(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *ID) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.NetKey)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Message) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.Sender != nil {
+		l = m.Sender.Size()
+		n += 1 + l + sovStream(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.RequestNonce != 0 {
+		n += 1 + sovStream(uint64(m.RequestNonce))
+	}
+	if m.MessageNonce != 0 {
+		n += 1 + sovStream(uint64(m.MessageNonce))
+	}
+	if m.ReplyFlag {
+		n += 2
+	}
+	if m.Opcode != 0 {
+		n += 1 + sovStream(uint64(m.Opcode))
+	}
+	l = len(m.DialAddress)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	l = len(m.Codec)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.StreamWindow != 0 {
+		n += 1 + sovStream(uint64(m.StreamWindow))
+	}
+	if m.Timeout != 0 {
+		n += 1 + sovStream(uint64(m.Timeout))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Ping) Size() (n int) {
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Pong) Size() (n int) {
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LookupNodeRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Target != nil {
+		l = m.Target.Size()
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LookupNodeResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Peers) > 0 {
+		for _, e := range m.Peers {
+			l = e.Size()
+			n += 1 + l + sovStream(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Bytes) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Keepalive) Size() (n int) {
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *KeepaliveResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Disconnect) Size() (n int) {
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *KeySuccession) Size() (n int) {
+	var l int
+	_ = l
+	if m.Previous != nil {
+		l = m.Previous.Size()
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.Successor != nil {
+		l = m.Successor.Size()
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovStream(uint64(m.Timestamp))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	l = len(m.SuccessorSignature)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SignedAddress) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovStream(uint64(m.Timestamp))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *PeerRecord) Size() (n int) {
+	var l int
+	_ = l
+	if m.Id != nil {
+		l = m.Id.Size()
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if len(m.Addresses) > 0 {
+		for _, e := range m.Addresses {
+			l = e.Size()
+			n += 1 + l + sovStream(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Identify) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.AgentVersion)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if len(m.Protocols) > 0 {
+		for _, s := range m.Protocols {
+			l = len(s)
+			n += 1 + l + sovStream(uint64(l))
+		}
+	}
+	if len(m.Opcodes) > 0 {
+		l = 0
+		for _, e := range m.Opcodes {
+			l += sovStream(uint64(e))
+		}
+		n += 1 + sovStream(uint64(l)) + l
+	}
+	if len(m.ListenAddresses) > 0 {
+		for _, s := range m.ListenAddresses {
+			l = len(s)
+			n += 1 + l + sovStream(uint64(l))
+		}
+	}
+	l = len(m.ObservedAddress)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Capabilities) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Opcodes) > 0 {
+		l = 0
+		for _, e := range m.Opcodes {
+			l += sovStream(uint64(e))
+		}
+		n += 1 + sovStream(uint64(l)) + l
+	}
+	if len(m.Protocols) > 0 {
+		for _, s := range m.Protocols {
+			l = len(s)
+			n += 1 + l + sovStream(uint64(l))
+		}
+	}
+	if len(m.MessageTypes) > 0 {
+		for _, e := range m.MessageTypes {
+			l = e.Size()
+			n += 1 + l + sovStream(uint64(l))
+		}
+	}
+	if len(m.Codecs) > 0 {
+		for _, s := range m.Codecs {
+			l = len(s)
+			n += 1 + l + sovStream(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *MessageType) Size() (n int) {
+	var l int
+	_ = l
+	if m.Opcode != 0 {
+		n += 1 + sovStream(uint64(m.Opcode))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ErrorReply) Size() (n int) {
+	var l int
+	_ = l
+	if m.Code != 0 {
+		n += 1 + sovStream(uint64(m.Code))
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovStream(uint64(l))
+	}
+	if len(m.Details) > 0 {
+		for k, v := range m.Details {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovStream(uint64(len(k))) + 1 + len(v) + sovStream(uint64(len(v)))
+			n += mapEntrySize + 1 + sovStream(uint64(mapEntrySize))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StreamControl) Size() (n int) {
+	var l int
+	_ = l
+	if m.Credits != 0 {
+		n += 1 + sovStream(uint64(m.Credits))
+	}
+	if m.End {
+		n += 2
+	}
+	if m.Cancel {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovStream(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozStream(x uint64) (n int) {
+	return sovStream(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *ID) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ID{`,
+		`NetKey:` + fmt.Sprintf("%v", this.NetKey) + `,`,
+		`Address:` + fmt.Sprintf("%v", this.Address) + `,`,
+		`Id:` + fmt.Sprintf("%v", this.Id) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Message) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Message{`,
+		`Message:` + fmt.Sprintf("%v", this.Message) + `,`,
+		`Sender:` + strings.Replace(fmt.Sprintf("%v", this.Sender), "ID", "ID", 1) + `,`,
+		`Signature:` + fmt.Sprintf("%v", this.Signature) + `,`,
+		`RequestNonce:` + fmt.Sprintf("%v", this.RequestNonce) + `,`,
+		`MessageNonce:` + fmt.Sprintf("%v", this.MessageNonce) + `,`,
+		`ReplyFlag:` + fmt.Sprintf("%v", this.ReplyFlag) + `,`,
+		`Opcode:` + fmt.Sprintf("%v", this.Opcode) + `,`,
+		`DialAddress:` + fmt.Sprintf("%v", this.DialAddress) + `,`,
+		`Codec:` + fmt.Sprintf("%v", this.Codec) + `,`,
+		`StreamWindow:` + fmt.Sprintf("%v", this.StreamWindow) + `,`,
+		`Timeout:` + fmt.Sprintf("%v", this.Timeout) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Ping) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Ping{`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Pong) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Pong{`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LookupNodeRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LookupNodeRequest{`,
+		`Target:` + strings.Replace(fmt.Sprintf("%v", this.Target), "ID", "ID", 1) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LookupNodeResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LookupNodeResponse{`,
+		`Peers:` + strings.Replace(fmt.Sprintf("%v", this.Peers), "ID", "ID", 1) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Bytes) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Bytes{`,
+		`Data:` + fmt.Sprintf("%v", this.Data) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Keepalive) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Keepalive{`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *KeepaliveResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&KeepaliveResponse{`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Disconnect) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Disconnect{`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *KeySuccession) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&KeySuccession{`,
+		`Previous:` + strings.Replace(fmt.Sprintf("%v", this.Previous), "ID", "ID", 1) + `,`,
+		`Successor:` + strings.Replace(fmt.Sprintf("%v", this.Successor), "ID", "ID", 1) + `,`,
+		`Timestamp:` + fmt.Sprintf("%v", this.Timestamp) + `,`,
+		`Signature:` + fmt.Sprintf("%v", this.Signature) + `,`,
+		`SuccessorSignature:` + fmt.Sprintf("%v", this.SuccessorSignature) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *SignedAddress) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&SignedAddress{`,
+		`Address:` + fmt.Sprintf("%v", this.Address) + `,`,
+		`Timestamp:` + fmt.Sprintf("%v", this.Timestamp) + `,`,
+		`Signature:` + fmt.Sprintf("%v", this.Signature) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *PeerRecord) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&PeerRecord{`,
+		`Id:` + strings.Replace(fmt.Sprintf("%v", this.Id), "ID", "ID", 1) + `,`,
+		`Addresses:` + strings.Replace(fmt.Sprintf("%v", this.Addresses), "SignedAddress", "SignedAddress", 1) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Identify) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Identify{`,
+		`AgentVersion:` + fmt.Sprintf("%v", this.AgentVersion) + `,`,
+		`Protocols:` + fmt.Sprintf("%v", this.Protocols) + `,`,
+		`Opcodes:` + fmt.Sprintf("%v", this.Opcodes) + `,`,
+		`ListenAddresses:` + fmt.Sprintf("%v", this.ListenAddresses) + `,`,
+		`ObservedAddress:` + fmt.Sprintf("%v", this.ObservedAddress) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Capabilities) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Capabilities{`,
+		`Opcodes:` + fmt.Sprintf("%v", this.Opcodes) + `,`,
+		`Protocols:` + fmt.Sprintf("%v", this.Protocols) + `,`,
+		`MessageTypes:` + strings.Replace(fmt.Sprintf("%v", this.MessageTypes), "MessageType", "MessageType", 1) + `,`,
+		`Codecs:` + fmt.Sprintf("%v", this.Codecs) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MessageType) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MessageType{`,
+		`Opcode:` + fmt.Sprintf("%v", this.Opcode) + `,`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ErrorReply) String() string {
+	if this == nil {
+		return "nil"
+	}
+	keysForDetails := make([]string, 0, len(this.Details))
+	for k, _ := range this.Details {
+		keysForDetails = append(keysForDetails, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForDetails)
+	mapStringForDetails := "map[string]string{"
+	for _, k := range keysForDetails {
+		mapStringForDetails += fmt.Sprintf("%v: %v,", k, this.Details[k])
+	}
+	mapStringForDetails += "}"
+	s := strings.Join([]string{`&ErrorReply{`,
+		`Code:` + fmt.Sprintf("%v", this.Code) + `,`,
+		`Message:` + fmt.Sprintf("%v", this.Message) + `,`,
+		`Details:` + mapStringForDetails + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *StreamControl) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&StreamControl{`,
+		`Credits:` + fmt.Sprintf("%v", this.Credits) + `,`,
+		`End:` + fmt.Sprintf("%v", this.End) + `,`,
+		`Cancel:` + fmt.Sprintf("%v", this.Cancel) + `,`,
+		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringStream(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *ID) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ID: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ID: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NetKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NetKey = append(m.NetKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.NetKey == nil {
+				m.NetKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Address = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = append(m.Id[:0], dAtA[iNdEx:postIndex]...)
+			if m.Id == nil {
+				m.Id = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Message) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Message: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Message: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = append(m.Message[:0], dAtA[iNdEx:postIndex]...)
+			if m.Message == nil {
+				m.Message = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sender", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Sender == nil {
+				m.Sender = &ID{}
+			}
+			if err := m.Sender.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestNonce", wireType)
+			}
+			m.RequestNonce = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RequestNonce |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MessageNonce", wireType)
+			}
+			m.MessageNonce = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MessageNonce |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReplyFlag", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ReplyFlag = bool(v != 0)
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Opcode", wireType)
+			}
+			m.Opcode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Opcode |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DialAddress", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DialAddress = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Codec", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Codec = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StreamWindow", wireType)
+			}
+			m.StreamWindow = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StreamWindow |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			m.Timeout = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timeout |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Ping) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Ping: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Ping: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Pong) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Pong: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Pong: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LookupNodeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LookupNodeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LookupNodeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Target == nil {
+				m.Target = &ID{}
+			}
+			if err := m.Target.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LookupNodeResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LookupNodeResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LookupNodeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Peers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Peers = append(m.Peers, &ID{})
+			if err := m.Peers[len(m.Peers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Bytes) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Bytes: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Bytes: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Keepalive) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Keepalive: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Keepalive: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeepaliveResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeepaliveResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeepaliveResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Disconnect) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Disconnect: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Disconnect: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *KeySuccession) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeySuccession: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeySuccession: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Previous", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Previous == nil {
+				m.Previous = &ID{}
+			}
+			if err := m.Previous.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Successor", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Successor == nil {
+				m.Successor = &ID{}
+			}
+			if err := m.Successor.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SuccessorSignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SuccessorSignature = append(m.SuccessorSignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.SuccessorSignature == nil {
+				m.SuccessorSignature = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthStream
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("*%v", pv)
+	return nil
 }
-func (m *ID) Unmarshal(dAtA []byte) error {
+func (m *SignedAddress) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1942,17 +5307,17 @@ func (m *ID) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ID: wiretype end group for non-group")
+			return fmt.Errorf("proto: SignedAddress: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ID: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SignedAddress: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field NetKey", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1962,28 +5327,26 @@ func (m *ID) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthStream
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.NetKey = append(m.NetKey[:0], dAtA[iNdEx:postIndex]...)
-			if m.NetKey == nil {
-				m.NetKey = []byte{}
-			}
+			m.Address = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
 			}
-			var stringLen uint64
+			m.Timestamp = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -1993,24 +5356,14 @@ func (m *ID) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.Timestamp |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthStream
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Address = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -2034,9 +5387,9 @@ func (m *ID) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Id = append(m.Id[:0], dAtA[iNdEx:postIndex]...)
-			if m.Id == nil {
-				m.Id = []byte{}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
 			}
 			iNdEx = postIndex
 		default:
@@ -2061,7 +5414,7 @@ func (m *ID) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Message) Unmarshal(dAtA []byte) error {
+func (m *PeerRecord) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2084,17 +5437,17 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Message: wiretype end group for non-group")
+			return fmt.Errorf("proto: PeerRecord: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Message: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PeerRecord: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -2104,26 +5457,28 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthStream
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Message = append(m.Message[:0], dAtA[iNdEx:postIndex]...)
-			if m.Message == nil {
-				m.Message = []byte{}
+			if m.Id == nil {
+				m.Id = &ID{}
+			}
+			if err := m.Id.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Sender", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Addresses", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2147,49 +5502,67 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Sender == nil {
-				m.Sender = &ID{}
-			}
-			if err := m.Sender.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Addresses = append(m.Addresses, &SignedAddress{})
+			if err := m.Addresses[len(m.Addresses)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowStream
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStream(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			if byteLen < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthStream
 			}
-			postIndex := iNdEx + byteLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
-			if m.Signature == nil {
-				m.Signature = []byte{}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Identify) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStream
 			}
-			iNdEx = postIndex
-		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RequestNonce", wireType)
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
 			}
-			m.RequestNonce = 0
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Identify: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Identify: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AgentVersion", wireType)
+			}
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -2199,16 +5572,26 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.RequestNonce |= (uint64(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 5:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field MessageNonce", wireType)
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AgentVersion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocols", wireType)
 			}
-			m.MessageNonce = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -2218,36 +5601,88 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.MessageNonce |= (uint64(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 6:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ReplyFlag", wireType)
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
 			}
-			var v int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowStream
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Protocols = append(m.Protocols, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType == 0 {
+				var v uint32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStream
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= (uint32(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
 				}
-				if iNdEx >= l {
+				m.Opcodes = append(m.Opcodes, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStream
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthStream
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
 					return io.ErrUnexpectedEOF
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				v |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
+				for iNdEx < postIndex {
+					var v uint32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStream
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= (uint32(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Opcodes = append(m.Opcodes, v)
 				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Opcodes", wireType)
 			}
-			m.ReplyFlag = bool(v != 0)
-		case 7:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Opcode", wireType)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ListenAddresses", wireType)
 			}
-			m.Opcode = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -2257,14 +5692,24 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Opcode |= (uint32(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 8:
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ListenAddresses = append(m.ListenAddresses, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DialAddress", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ObservedAddress", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2289,7 +5734,7 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.DialAddress = string(dAtA[iNdEx:postIndex])
+			m.ObservedAddress = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2313,7 +5758,7 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Ping) Unmarshal(dAtA []byte) error {
+func (m *Capabilities) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2336,117 +5781,106 @@ func (m *Ping) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Ping: wiretype end group for non-group")
+			return fmt.Errorf("proto: Capabilities: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Ping: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Capabilities: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		default:
-			iNdEx = preIndex
-			skippy, err := skipStream(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthStream
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *Pong) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowStream
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+		case 1:
+			if wireType == 0 {
+				var v uint32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStream
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= (uint32(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Opcodes = append(m.Opcodes, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStream
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthStream
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStream
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= (uint32(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Opcodes = append(m.Opcodes, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Opcodes", wireType)
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocols", wireType)
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: Pong: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Pong: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		default:
-			iNdEx = preIndex
-			skippy, err := skipStream(dAtA[iNdEx:])
-			if err != nil {
-				return err
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if skippy < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthStream
 			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *LookupNodeRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowStream
-			}
-			if iNdEx >= l {
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: LookupNodeRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LookupNodeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			m.Protocols = append(m.Protocols, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field MessageTypes", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2470,12 +5904,39 @@ func (m *LookupNodeRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Target == nil {
-				m.Target = &ID{}
+			m.MessageTypes = append(m.MessageTypes, &MessageType{})
+			if err := m.MessageTypes[len(m.MessageTypes)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Codecs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStream
 			}
-			if err := m.Target.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
+			m.Codecs = append(m.Codecs, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2499,7 +5960,7 @@ func (m *LookupNodeRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LookupNodeResponse) Unmarshal(dAtA []byte) error {
+func (m *MessageType) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2522,17 +5983,36 @@ func (m *LookupNodeResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LookupNodeResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: MessageType: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LookupNodeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: MessageType: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Opcode", wireType)
+			}
+			m.Opcode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Opcode |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Peers", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -2542,22 +6022,20 @@ func (m *LookupNodeResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthStream
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Peers = append(m.Peers, &ID{})
-			if err := m.Peers[len(m.Peers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2581,7 +6059,7 @@ func (m *LookupNodeResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Bytes) Unmarshal(dAtA []byte) error {
+func (m *ErrorReply) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2604,17 +6082,36 @@ func (m *Bytes) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Bytes: wiretype end group for non-group")
+			return fmt.Errorf("proto: ErrorReply: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Bytes: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ErrorReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
+			}
+			m.Code = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Code |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowStream
@@ -2624,74 +6121,139 @@ func (m *Bytes) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthStream
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
-			if m.Data == nil {
-				m.Data = []byte{}
-			}
+			m.Message = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipStream(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Details", wireType)
 			}
-			if skippy < 0 {
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
 				return ErrInvalidLengthStream
 			}
-			if (iNdEx + skippy) > l {
+			postIndex := iNdEx + msglen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *Keepalive) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowStream
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			if m.Details == nil {
+				m.Details = make(map[string]string)
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStream
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStream
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthStream
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowStream
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthStream
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipStream(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthStream
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: Keepalive: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Keepalive: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
+			m.Details[mapkey] = mapvalue
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStream(dAtA[iNdEx:])
@@ -2714,7 +6276,7 @@ func (m *Keepalive) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *KeepaliveResponse) Unmarshal(dAtA []byte) error {
+func (m *StreamControl) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2737,63 +6299,71 @@ func (m *KeepaliveResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: KeepaliveResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: StreamControl: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: KeepaliveResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: StreamControl: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		default:
-			iNdEx = preIndex
-			skippy, err := skipStream(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Credits", wireType)
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStream
+			m.Credits = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Credits |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *Disconnect) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowStream
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			m.End = bool(v != 0)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cancel", wireType)
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStream
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: Disconnect: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Disconnect: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
+			m.Cancel = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStream(dAtA[iNdEx:])
@@ -2921,37 +6491,65 @@ var (
 	ErrIntOverflowStream   = fmt.Errorf("proto: integer overflow")
 )
 
-func init() { proto.RegisterFile("stream.proto", fileDescriptor_stream_654c54e9f51da163) }
-
-var fileDescriptor_stream_654c54e9f51da163 = []byte{
-	// 457 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x51, 0x4d, 0x6e, 0xd3, 0x40,
-	0x18, 0xed, 0xa4, 0x89, 0x93, 0x7c, 0x71, 0x91, 0x3a, 0x48, 0xc5, 0xe2, 0xc7, 0x32, 0x03, 0x0b,
-	0xaf, 0x5c, 0x09, 0x36, 0xb0, 0x24, 0x8a, 0x40, 0xa5, 0x10, 0x45, 0xbe, 0x40, 0x34, 0x8d, 0xbf,
-	0x8e, 0xac, 0xba, 0x33, 0x66, 0x66, 0x82, 0x94, 0x1d, 0x7b, 0x2e, 0xc0, 0x11, 0x38, 0x0a, 0x4b,
-	0x96, 0x2c, 0x1b, 0x73, 0x01, 0x8e, 0x80, 0x3c, 0x1e, 0x43, 0xa5, 0xae, 0xe6, 0xbd, 0xf7, 0xbd,
-	0xef, 0x47, 0x6f, 0x20, 0x34, 0x56, 0x23, 0xbf, 0xce, 0x6a, 0xad, 0xac, 0xa2, 0x13, 0xf7, 0x5c,
-	0x6c, 0x2f, 0x1f, 0x32, 0xa1, 0x84, 0x3a, 0xed, 0xe9, 0x69, 0xcb, 0x1c, 0x71, 0xa8, 0x73, 0xb3,
-	0x77, 0x30, 0x38, 0x5b, 0xd0, 0x07, 0x30, 0x96, 0x68, 0xd7, 0x57, 0xb8, 0x8b, 0x48, 0x42, 0xd2,
-	0x30, 0x0f, 0x24, 0xda, 0x73, 0xdc, 0xd1, 0x08, 0xc6, 0xbc, 0x28, 0x34, 0x1a, 0x13, 0x0d, 0x12,
-	0x92, 0x4e, 0xf3, 0x9e, 0xd2, 0x7b, 0x30, 0x28, 0x8b, 0xe8, 0xd0, 0xb9, 0x07, 0x65, 0xc1, 0xbe,
-	0x0e, 0x60, 0xfc, 0x11, 0x8d, 0xe1, 0x02, 0xdb, 0xae, 0xeb, 0x0e, 0xfa, 0x71, 0x3d, 0xa5, 0xcf,
-	0x21, 0x30, 0x28, 0x0b, 0xd4, 0x6e, 0xdc, 0xec, 0x45, 0x98, 0xf5, 0xe7, 0x65, 0x67, 0x8b, 0xdc,
-	0xd7, 0xe8, 0x63, 0x98, 0x9a, 0x52, 0x48, 0x6e, 0xb7, 0x1a, 0xfd, 0x8a, 0xff, 0x02, 0x7d, 0x06,
-	0x47, 0x1a, 0x3f, 0x6d, 0xd1, 0xd8, 0xb5, 0x54, 0x72, 0x83, 0xd1, 0x30, 0x21, 0xe9, 0x30, 0x0f,
-	0xbd, 0xb8, 0x6c, 0xb5, 0xd6, 0xe4, 0x77, 0x7a, 0xd3, 0xa8, 0x33, 0x79, 0xb1, 0x33, 0x3d, 0x01,
-	0xd0, 0x58, 0x57, 0xbb, 0xf5, 0x65, 0xc5, 0x45, 0x14, 0x24, 0x24, 0x9d, 0xe4, 0x53, 0xa7, 0xbc,
-	0xad, 0xb8, 0xa0, 0x27, 0x10, 0xa8, 0x7a, 0xa3, 0x0a, 0x8c, 0xc6, 0x09, 0x49, 0x8f, 0x72, 0xcf,
-	0xe8, 0x53, 0x08, 0x8b, 0x92, 0x57, 0xeb, 0x3e, 0x99, 0x89, 0x4b, 0x66, 0xd6, 0x6a, 0x6f, 0x3a,
-	0x89, 0x05, 0x30, 0x5c, 0x95, 0x52, 0xb8, 0x57, 0x49, 0xc1, 0x5e, 0xc3, 0xf1, 0x07, 0xa5, 0xae,
-	0xb6, 0xf5, 0x52, 0x15, 0x98, 0x77, 0x87, 0xb6, 0x61, 0x58, 0xae, 0x05, 0x5a, 0x97, 0xd2, 0x9d,
-	0x30, 0xba, 0x1a, 0x7b, 0x05, 0xf4, 0x76, 0xab, 0xa9, 0x95, 0x34, 0x48, 0x19, 0x8c, 0x6a, 0x44,
-	0x6d, 0x22, 0x92, 0x1c, 0xde, 0x69, 0xed, 0x4a, 0xec, 0x11, 0x8c, 0xe6, 0x3b, 0x8b, 0x86, 0x52,
-	0x18, 0x16, 0xdc, 0x72, 0xff, 0x19, 0x0e, 0xb3, 0x19, 0x4c, 0xcf, 0x11, 0x6b, 0x5e, 0x95, 0x9f,
-	0x91, 0xdd, 0x87, 0xe3, 0x7f, 0xa4, 0x5f, 0xc1, 0x42, 0x80, 0x45, 0x69, 0x36, 0x4a, 0x4a, 0xdc,
-	0xd8, 0xf9, 0xfb, 0x5f, 0xfb, 0xf8, 0xe0, 0x66, 0x1f, 0x93, 0x3f, 0xfb, 0x98, 0x7c, 0x69, 0x62,
-	0xf2, 0xbd, 0x89, 0xc9, 0x8f, 0x26, 0x26, 0x3f, 0x9b, 0x98, 0xdc, 0x34, 0x31, 0xf9, 0xf6, 0x3b,
-	0x3e, 0x80, 0x13, 0xa5, 0x45, 0x56, 0xa3, 0xae, 0x4a, 0x99, 0x49, 0x55, 0x1a, 0xec, 0xee, 0x9a,
-	0xc3, 0xb2, 0x25, 0xab, 0x16, 0xaf, 0xc8, 0x45, 0xe0, 0xc4, 0x97, 0x7f, 0x03, 0x00, 0x00, 0xff,
-	0xff, 0xd7, 0xbf, 0xd7, 0xfc, 0xb9, 0x02, 0x00, 0x00,
+func init() { proto.RegisterFile("stream.proto", fileDescriptor_stream_c27a36122cd0c435) }
+
+var fileDescriptor_stream_c27a36122cd0c435 = []byte{
+	// 903 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x55, 0xcd, 0x8e, 0x1b, 0x45,
+	0x10, 0xa6, 0xfd, 0xef, 0xb2, 0x4d, 0x92, 0x0e, 0x24, 0xa3, 0x10, 0x2c, 0xa7, 0xe1, 0x60, 0x38,
+	0x78, 0xa5, 0x20, 0xa4, 0x64, 0x39, 0x65, 0xb3, 0x09, 0x5a, 0x2d, 0xac, 0x56, 0xb3, 0x08, 0x8e,
+	0x56, 0xef, 0x4c, 0xed, 0xa8, 0x95, 0x71, 0xf7, 0xd0, 0xdd, 0x76, 0xe4, 0x1b, 0xcf, 0xc1, 0x13,
+	0x70, 0xe7, 0xcc, 0x9d, 0x23, 0xe2, 0xc4, 0x71, 0xd7, 0x4f, 0xc0, 0x23, 0xa0, 0xee, 0xe9, 0xf1,
+	0xd8, 0x61, 0x95, 0x93, 0xeb, 0xfb, 0xea, 0xab, 0xea, 0xea, 0x9a, 0xae, 0x32, 0x0c, 0x8d, 0xd5,
+	0xc8, 0x17, 0xb3, 0x42, 0x2b, 0xab, 0x68, 0xcf, 0xff, 0x5c, 0x2e, 0xaf, 0x1e, 0xb1, 0x4c, 0x65,
+	0xea, 0xa0, 0x82, 0x07, 0x0e, 0x79, 0xe0, 0xad, 0x52, 0xcd, 0xbe, 0x85, 0xc6, 0xc9, 0x31, 0x7d,
+	0x08, 0x5d, 0x89, 0x76, 0xfe, 0x06, 0xd7, 0x11, 0x99, 0x90, 0xe9, 0x30, 0xee, 0x48, 0xb4, 0xa7,
+	0xb8, 0xa6, 0x11, 0x74, 0x79, 0x9a, 0x6a, 0x34, 0x26, 0x6a, 0x4c, 0xc8, 0xb4, 0x1f, 0x57, 0x90,
+	0x7e, 0x08, 0x0d, 0x91, 0x46, 0x4d, 0xaf, 0x6e, 0x88, 0x94, 0x5d, 0x37, 0xa0, 0xfb, 0x3d, 0x1a,
+	0xc3, 0x33, 0x74, 0x51, 0x8b, 0xd2, 0x0c, 0xe9, 0x2a, 0x48, 0x3f, 0x87, 0x8e, 0x41, 0x99, 0xa2,
+	0xf6, 0xe9, 0x06, 0x4f, 0x87, 0xb3, 0xaa, 0xbc, 0xd9, 0xc9, 0x71, 0x1c, 0x7c, 0xf4, 0x31, 0xf4,
+	0x8d, 0xc8, 0x24, 0xb7, 0x4b, 0x8d, 0xe1, 0x88, 0x9a, 0xa0, 0x9f, 0xc1, 0x48, 0xe3, 0xcf, 0x4b,
+	0x34, 0x76, 0x2e, 0x95, 0x4c, 0x30, 0x6a, 0x4d, 0xc8, 0xb4, 0x15, 0x0f, 0x03, 0x79, 0xe6, 0x38,
+	0x27, 0x0a, 0x67, 0x06, 0x51, 0xbb, 0x14, 0x05, 0xb2, 0x14, 0x7d, 0x0a, 0xa0, 0xb1, 0xc8, 0xd7,
+	0xf3, 0xab, 0x9c, 0x67, 0x51, 0x67, 0x42, 0xa6, 0xbd, 0xb8, 0xef, 0x99, 0xd7, 0x39, 0xcf, 0xe8,
+	0x03, 0xe8, 0xa8, 0x22, 0x51, 0x29, 0x46, 0xdd, 0x09, 0x99, 0x8e, 0xe2, 0x80, 0xe8, 0x13, 0x18,
+	0xa6, 0x82, 0xe7, 0xf3, 0xaa, 0x33, 0x3d, 0xdf, 0x99, 0x81, 0xe3, 0x5e, 0x84, 0xee, 0x7c, 0x04,
+	0x6d, 0x27, 0x4d, 0xa2, 0xbe, 0xf7, 0x95, 0xc0, 0x15, 0x55, 0x7e, 0xaa, 0xf9, 0x5b, 0x21, 0x53,
+	0xf5, 0x36, 0x02, 0x9f, 0x37, 0x7c, 0xbf, 0x9f, 0x3c, 0xe7, 0x9a, 0x67, 0xc5, 0x02, 0xd5, 0xd2,
+	0x46, 0x83, 0x09, 0x99, 0x36, 0xe3, 0x0a, 0xb2, 0x0e, 0xb4, 0xce, 0x85, 0xcc, 0xfc, 0xaf, 0x92,
+	0x19, 0x7b, 0x0e, 0xf7, 0xbe, 0x53, 0xea, 0xcd, 0xb2, 0x38, 0x53, 0x29, 0xc6, 0xe5, 0xed, 0x5d,
+	0x87, 0x2d, 0xd7, 0x19, 0xda, 0x88, 0xdc, 0xd6, 0xe1, 0xd2, 0xc7, 0x9e, 0x01, 0xdd, 0x0d, 0x35,
+	0x85, 0x92, 0x06, 0x29, 0x83, 0x76, 0x81, 0xa8, 0x4d, 0x44, 0x26, 0xcd, 0xff, 0x85, 0x96, 0x2e,
+	0xf6, 0x09, 0xb4, 0x8f, 0xd6, 0x16, 0x0d, 0xa5, 0xd0, 0x4a, 0xb9, 0xe5, 0xe1, 0x0b, 0x7b, 0x9b,
+	0x0d, 0xa0, 0x7f, 0x8a, 0x58, 0xf0, 0x5c, 0xac, 0x90, 0xdd, 0x87, 0x7b, 0x5b, 0x50, 0x1d, 0xc1,
+	0x86, 0x00, 0xc7, 0xc2, 0x24, 0x4a, 0x4a, 0x4c, 0x2c, 0xfb, 0x9b, 0xc0, 0xe8, 0x14, 0xd7, 0x17,
+	0xcb, 0x24, 0x41, 0x63, 0x84, 0x92, 0x74, 0x0a, 0xbd, 0x42, 0xe3, 0x4a, 0xa8, 0xa5, 0xb9, 0xf5,
+	0x02, 0x5b, 0x2f, 0xfd, 0x12, 0xfa, 0xa6, 0x8c, 0x53, 0xb7, 0xbf, 0xa6, 0xda, 0xed, 0x1e, 0x94,
+	0x6b, 0xa2, 0xb1, 0x7c, 0x51, 0xf8, 0x07, 0xd5, 0x8c, 0x6b, 0x62, 0xff, 0xb9, 0xb5, 0xde, 0x7d,
+	0x6e, 0x07, 0x70, 0x7f, 0x9b, 0x68, 0x5e, 0xeb, 0xda, 0x5e, 0x47, 0xb7, 0xae, 0x8b, 0xca, 0xc3,
+	0x10, 0x46, 0x0e, 0x60, 0x5a, 0x3d, 0x86, 0x9d, 0x21, 0x22, 0xfb, 0x43, 0xb4, 0x57, 0x57, 0xe3,
+	0xbd, 0x75, 0xbd, 0x3b, 0x06, 0x8c, 0x03, 0x9c, 0x23, 0xea, 0x18, 0x13, 0xa5, 0x53, 0xfa, 0xd8,
+	0x8f, 0xe3, 0x6d, 0x1d, 0x6b, 0x88, 0x94, 0x7e, 0x0d, 0xfd, 0x70, 0x24, 0xba, 0x41, 0x76, 0x1f,
+	0xf7, 0x61, 0x2d, 0xda, 0xab, 0x36, 0xae, 0x95, 0xec, 0x0f, 0x02, 0xbd, 0x93, 0x14, 0xa5, 0x15,
+	0x57, 0x6b, 0xf7, 0x78, 0x79, 0x86, 0xd2, 0xce, 0x57, 0xa8, 0xdd, 0xa7, 0x0a, 0x77, 0x19, 0x7a,
+	0xf2, 0xc7, 0x92, 0x73, 0x25, 0xfb, 0xb4, 0x89, 0xca, 0xcb, 0x83, 0xfa, 0x71, 0x4d, 0xb8, 0x46,
+	0x94, 0x23, 0x64, 0xa2, 0xe6, 0xa4, 0x39, 0x1d, 0xc5, 0x15, 0xa4, 0x5f, 0xc0, 0xdd, 0x5c, 0x18,
+	0x8b, 0x72, 0x5e, 0xd7, 0xd9, 0xf2, 0xe1, 0x77, 0x4a, 0xfe, 0x45, 0x45, 0x3b, 0xa9, 0xba, 0x34,
+	0xa8, 0x57, 0x98, 0x6e, 0x27, 0xb0, 0xed, 0x4b, 0xb9, 0x53, 0xf1, 0x41, 0xcc, 0x7e, 0x25, 0x30,
+	0x7c, 0xc9, 0x0b, 0x7e, 0x29, 0x72, 0x61, 0x05, 0xee, 0x15, 0x40, 0xf6, 0x0b, 0x78, 0x7f, 0xe1,
+	0x87, 0xf5, 0x36, 0xb1, 0xeb, 0x22, 0x94, 0x3f, 0x78, 0xfa, 0x71, 0xdd, 0xc3, 0xb0, 0xfa, 0x7e,
+	0x58, 0x17, 0xb8, 0x5d, 0x32, 0x0e, 0x18, 0xb7, 0x45, 0xfc, 0xf4, 0x57, 0x17, 0x0a, 0x88, 0x3d,
+	0x87, 0xc1, 0x4e, 0xd0, 0xce, 0xb2, 0x21, 0x7b, 0xcb, 0x86, 0x42, 0x4b, 0xf2, 0x05, 0x86, 0xf5,
+	0xeb, 0x6d, 0xf6, 0x3b, 0x01, 0x78, 0xa5, 0xb5, 0xd2, 0xb1, 0xdb, 0x55, 0x4e, 0xb2, 0x13, 0xe8,
+	0xed, 0xdd, 0x15, 0x1c, 0x16, 0x77, 0x80, 0xf4, 0x1b, 0xe8, 0xa6, 0x68, 0xb9, 0xc8, 0xab, 0x5b,
+	0x3c, 0xa9, 0x6f, 0x51, 0x27, 0x9d, 0x1d, 0x97, 0x9a, 0x57, 0xd2, 0xea, 0x75, 0x5c, 0x45, 0x3c,
+	0x3a, 0x84, 0xe1, 0xae, 0x83, 0xde, 0x85, 0x66, 0xf5, 0xa7, 0xd1, 0x8f, 0x9d, 0xe9, 0x36, 0xdf,
+	0x8a, 0xe7, 0xcb, 0xea, 0xd8, 0x12, 0x1c, 0x36, 0x9e, 0x11, 0x76, 0x01, 0xa3, 0x0b, 0xbf, 0xe8,
+	0x5e, 0x2a, 0x69, 0xb5, 0xca, 0x5d, 0x8d, 0x89, 0xc6, 0x54, 0x58, 0x13, 0x4a, 0xaf, 0xa0, 0x4b,
+	0x8b, 0x32, 0xf5, 0x29, 0x7a, 0xb1, 0x33, 0x7d, 0x17, 0xb9, 0x4c, 0x30, 0xf7, 0x83, 0xd0, 0x8b,
+	0x03, 0x3a, 0x7a, 0xfd, 0xcf, 0xcd, 0xf8, 0x83, 0xeb, 0x9b, 0x31, 0xf9, 0xf7, 0x66, 0x4c, 0x7e,
+	0xd9, 0x8c, 0xc9, 0x6f, 0x9b, 0x31, 0xf9, 0x73, 0x33, 0x26, 0x7f, 0x6d, 0xc6, 0xe4, 0x7a, 0x33,
+	0x26, 0xf0, 0x40, 0xe9, 0x6c, 0x56, 0xa0, 0xce, 0x85, 0x9c, 0x49, 0x25, 0x0c, 0x96, 0xd7, 0x3d,
+	0x82, 0x33, 0x07, 0xce, 0x9d, 0x7d, 0x4e, 0x2e, 0x3b, 0x9e, 0xfc, 0xea, 0xbf, 0x01, 0x00, 0x01,
+	0xcd, 0xef, 0xa9, 0x4c, 0x07, 0x00, 0x00,
 }
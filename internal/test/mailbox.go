@@ -6,7 +6,8 @@ import (
 )
 
 var (
-	mailboxComponentID = (*MailBoxComponent)(nil)
+	mailboxComponentID                     = (*MailBoxComponent)(nil)
+	_                  network.SendHandler = (*MailBoxComponent)(nil)
 )
 
 // MailBoxComponent buffers all messages into a mailbox for test validation.
@@ -19,14 +20,18 @@ type MailBoxComponent struct {
 // Startup creates a mailbox channel
 func (state *MailBoxComponent) Startup(net *network.Network) {
 	state.RecvMailbox = make(chan *protobuf.TestMessage)
-	state.SendMailbox = make(chan *protobuf.TestMessage)
+	state.SendMailbox = make(chan *protobuf.TestMessage, 100)
 }
 
-// Send puts a sent message into the SendMailbox channel
-func (state *MailBoxComponent) Send(ctx *network.ComponentContext) error {
+// Send puts a sent message into the SendMailbox channel, dropping it if the
+// channel is full so that sending never blocks on an undrained mailbox
+func (state *MailBoxComponent) Send(ctx *network.SendContext) error {
 	switch msg := ctx.Message().(type) {
 	case *protobuf.TestMessage:
-		state.SendMailbox <- msg
+		select {
+		case state.SendMailbox <- msg:
+		default:
+		}
 	}
 	return nil
 }
@@ -12,6 +12,24 @@ import (
 
 var domainLookupCache = lru.NewCache(1000)
 
+// IPFamily is a version of the internet protocol.
+type IPFamily int
+
+const (
+	// IPv4 is the internet protocol version 4.
+	IPv4 IPFamily = iota
+	// IPv6 is the internet protocol version 6.
+	IPv6
+)
+
+// String returns the name of the IP family.
+func (f IPFamily) String() string {
+	if f == IPv6 {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
 // AddressInfo represents a network URL.
 type AddressInfo struct {
 	Protocol string
@@ -47,10 +65,12 @@ func (info *AddressInfo) Network() string {
 
 // String prints out either the URL representation of the address info, or
 // solely just a joined host and port should a network scheme not be defined.
+// IPv6 literals are enclosed in brackets, with the zone of link-local
+// addresses escaped as in `tcp://[fe80::1%25eth0]:3000`.
 func (info *AddressInfo) String() string {
 	address := net.JoinHostPort(info.Host, strconv.Itoa(int(info.Port)))
 	if len(info.Protocol) > 0 {
-		address = info.Protocol + "://" + address
+		address = info.Protocol + "://" + strings.Replace(address, "%", "%25", 1)
 	}
 	return address
 }
@@ -90,39 +110,77 @@ func ParseAddress(address string) (*AddressInfo, error) {
 	}, nil
 }
 
-// ToUnifiedHost resolves a domain host.
-func ToUnifiedHost(host string) (string, error) {
-	unifiedHost, err := domainLookupCache.Get(host, func() (interface{}, error) {
-		if net.ParseIP(host) == nil {
-			// Probably a domain name is provided.
-			addresses, err := net.LookupHost(host)
-			if err != nil {
-				return "", errors.New(ErrStrNoAvailableAddresses)
-			}
-			if len(addresses) == 0 {
-				return "", errors.New(ErrStrNoAvailableAddresses)
-			}
-
-			host = addresses[0]
-
-			// Hacky localhost fix.
-			if host == "::1" {
-				host = "127.0.0.1"
-			}
-		}
+// ResolveHost returns the IP addresses of a host, ordered so that those of the
+// preferred IP family come first. IP literals are returned in their canonical
+// form, keeping the zone of link-local IPv6 addresses.
+func ResolveHost(host string, preferred IPFamily) ([]string, error) {
+	if ip := parseIP(host); ip != nil {
+		return []string{canonicalHost(host, ip)}, nil
+	}
 
-		return host, nil
+	// Probably a domain name is provided.
+	addresses, err := domainLookupCache.Get(host, func() (interface{}, error) {
+		addresses, err := net.LookupHost(host)
+		if err != nil || len(addresses) == 0 {
+			return nil, errors.New(ErrStrNoAvailableAddresses)
+		}
+		return addresses, nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return SortByFamily(addresses.([]string), preferred), nil
+}
+
+// SortByFamily returns the IP addresses ordered for dialing, alternating
+// between IP families starting with the preferred one, as recommended for
+// Happy Eyeballs (RFC 8305). Entries which are not IP addresses are dropped.
+func SortByFamily(addresses []string, preferred IPFamily) []string {
+	var first, second []string
 
-	if unifiedHost == nil {
-		return "", errors.New(ErrStrNoAvailableAddresses)
+	for _, address := range addresses {
+		ip := parseIP(address)
+		if ip == nil {
+			continue
+		}
+		if familyOf(ip) == preferred {
+			first = append(first, canonicalHost(address, ip))
+		} else {
+			second = append(second, canonicalHost(address, ip))
+		}
 	}
 
-	return unifiedHost.(string), err
+	sorted := make([]string, 0, len(first)+len(second))
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			sorted = append(sorted, first[i])
+		}
+		if i < len(second) {
+			sorted = append(sorted, second[i])
+		}
+	}
+	return sorted
+}
+
+// ToUnifiedHost resolves a domain host, preferring IPv4 addresses.
+func ToUnifiedHost(host string) (string, error) {
+	addresses, err := ResolveHost(host, IPv4)
+	if err != nil {
+		return "", err
+	}
+	return addresses[0], nil
 }
 
-// ToUnifiedAddress resolves and normalizes a network address.
+// ToUnifiedAddress resolves and normalizes a network address, preferring IPv4
+// addresses.
 func ToUnifiedAddress(address string) (string, error) {
+	return ToUnifiedAddressWithFamily(address, IPv4)
+}
+
+// ToUnifiedAddressWithFamily resolves and normalizes a network address,
+// preferring addresses of the given IP family.
+func ToUnifiedAddressWithFamily(address string, preferred IPFamily) (string, error) {
 	address = strings.TrimSpace(address)
 	if len(address) == 0 {
 		return "", errors.New(ErrStrAddressEmpty)
@@ -133,10 +191,34 @@ func ToUnifiedAddress(address string) (string, error) {
 		return "", err
 	}
 
-	info.Host, err = ToUnifiedHost(info.Host)
+	addresses, err := ResolveHost(info.Host, preferred)
 	if err != nil {
 		return "", err
 	}
+	info.Host = addresses[0]
 
 	return info.String(), nil
 }
+
+// parseIP parses an IP address, ignoring the zone of IPv6 addresses.
+func parseIP(host string) net.IP {
+	if i := strings.LastIndex(host, "%"); i >= 0 {
+		host = host[:i]
+	}
+	return net.ParseIP(host)
+}
+
+// canonicalHost formats an IP address canonically, keeping its zone.
+func canonicalHost(host string, ip net.IP) string {
+	if i := strings.LastIndex(host, "%"); i >= 0 && ip.To4() == nil {
+		return ip.String() + host[i:]
+	}
+	return ip.String()
+}
+
+func familyOf(ip net.IP) IPFamily {
+	if ip.To4() != nil {
+		return IPv4
+	}
+	return IPv6
+}
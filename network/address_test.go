@@ -30,6 +30,38 @@ func TestFormatAddress(t *testing.T) {
 	}
 }
 
+func TestAddressRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		host     string
+		expected string
+	}{
+		{"127.0.0.1", "tcp://127.0.0.1:3000"},
+		{"localhost", "tcp://localhost:3000"},
+		{"::1", "tcp://[::1]:3000"},
+		{"2001:db8::8a2e:370:7334", "tcp://[2001:db8::8a2e:370:7334]:3000"},
+		{"fe80::1%eth0", "tcp://[fe80::1%25eth0]:3000"},
+	}
+	for _, tt := range testCases {
+		address := FormatAddress("tcp", tt.host, 3000)
+		if address != tt.expected {
+			t.Errorf("FormatAddress() = %s, expected %s", address, tt.expected)
+		}
+
+		info, err := ParseAddress(address)
+		if err != nil {
+			t.Fatalf("ParseAddress(%s) = %+v, expected <nil>", address, err)
+		}
+		if info.Protocol != "tcp" || info.Host != tt.host || info.Port != 3000 {
+			t.Errorf("ParseAddress(%s) = %+v, expected tcp %s 3000", address, info, tt.host)
+		}
+		if info.String() != address {
+			t.Errorf("String() = %s, expected %s", info.String(), address)
+		}
+	}
+}
+
 func TestNetworkName(t *testing.T) {
 	t.Parallel()
 
@@ -93,6 +125,9 @@ func TestToUnifiedHost(t *testing.T) {
 	}{
 		{"tcp://asdf:1000", errors.New(ErrStrNoAvailableAddresses), "", "LookupHost fails to resolve"},
 		{"localhost", nil, "127.0.0.1", "should resolve localhost to 127.0.0.1"},
+		{"::1", nil, "::1", "should keep IPv6 loopback"},
+		{"0:0:0:0:0:0:0:1", nil, "::1", "should canonicalize IPv6 literals"},
+		{"FE80::0:1%eth0", nil, "fe80::1%eth0", "should keep the zone of IPv6 literals"},
 	}
 	for _, tt := range testCases {
 		address, err := ToUnifiedHost(tt.address)
@@ -108,6 +143,50 @@ func TestToUnifiedHost(t *testing.T) {
 	}
 }
 
+func TestToUnifiedAddressWithFamily(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		address  string
+		family   IPFamily
+		expected string
+	}{
+		{"tcp://[::1]:1000", IPv4, "tcp://[::1]:1000"},
+		{"tcp://[0::1]:1000", IPv6, "tcp://[::1]:1000"},
+		{"kcp://127.0.0.1:1000", IPv6, "kcp://127.0.0.1:1000"},
+		{"udp://[::ffff:127.0.0.1]:1000", IPv6, "udp://127.0.0.1:1000"},
+	}
+	for _, tt := range testCases {
+		address, err := ToUnifiedAddressWithFamily(tt.address, tt.family)
+		if err != nil {
+			t.Errorf("ToUnifiedAddressWithFamily(%s) = %+v, expected <nil>", tt.address, err)
+		}
+		if address != tt.expected {
+			t.Errorf("ToUnifiedAddressWithFamily(%s) = %s, expected %s", tt.address, address, tt.expected)
+		}
+	}
+}
+
+func TestSortByFamily(t *testing.T) {
+	t.Parallel()
+
+	addresses := []string{"::1", "2001:db8::1", "127.0.0.1", "example", "192.0.2.1", "192.0.2.2"}
+
+	testCases := []struct {
+		family   IPFamily
+		expected []string
+	}{
+		{IPv4, []string{"127.0.0.1", "::1", "192.0.2.1", "2001:db8::1", "192.0.2.2"}},
+		{IPv6, []string{"::1", "127.0.0.1", "2001:db8::1", "192.0.2.1", "192.0.2.2"}},
+	}
+	for _, tt := range testCases {
+		sorted := SortByFamily(addresses, tt.family)
+		if fmt.Sprint(sorted) != fmt.Sprint(tt.expected) {
+			t.Errorf("SortByFamily(%s) = %v, expected %v", tt.family, sorted, tt.expected)
+		}
+	}
+}
+
 func TestParseAddress(t *testing.T) {
 	t.Parallel()
 
@@ -118,6 +197,7 @@ func TestParseAddress(t *testing.T) {
 		{"https://[2b01:e34:ef40:7730:8e70:5aff:fefe:edac]:foo/foo", "url.Parse fails"},
 		{"tcp://", "empty url error not triggered"},
 		{"tcp://host:k", "port url error not triggered"},
+		{"tcp://::1:3000", "unbracketed IPv6 literal not rejected"},
 	}
 	for _, tt := range testCases {
 		_, err := ParseAddress(tt.address)
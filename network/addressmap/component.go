@@ -3,7 +3,6 @@ package addressmap
 import (
 	"github.com/cocher/utils/log"
 	"github.com/cocher/network"
-	"github.com/cocher/peer"
 )
 
 type Component struct {
@@ -35,6 +34,5 @@ func (p *Component) Startup(n *network.Network) {
 
 	log.Infof("update mapping address from %s to %s", info.String(), mapInfo.String())
 
-	n.Address = mapInfo.String()
-	n.ID = peer.CreateID(n.Address, n.GetKeys().PublicKey)
+	n.SetAddress(mapInfo.String())
 }
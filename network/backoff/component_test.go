@@ -1,4 +1,4 @@
-package backoff
+package backoff_test
 
 import (
 	"context"
@@ -10,15 +10,17 @@ import (
 	"github.com/cocher/crypto/ed25519"
 	"github.com/cocher/examples/basic/messages"
 	"github.com/cocher/network"
+	"github.com/cocher/network/backoff"
 	"github.com/cocher/network/discovery"
 	"github.com/cocher/types/opcode"
 	"github.com/pkg/errors"
 )
 
 const (
-	numNodes = 2
-	protocol = "tcp"
-	host     = "127.0.0.1"
+	numNodes     = 2
+	initialDelay = 5 * time.Second
+	protocol     = "tcp"
+	host         = "127.0.0.1"
 )
 
 var (
@@ -91,7 +93,7 @@ func newNode(i int, addDiscoveryComponent bool, addBackoffComponent bool) (*netw
 		builder.AddComponent(new(discovery.Component))
 	}
 	if addBackoffComponent {
-		builder.AddComponent(New())
+		builder.AddComponent(backoff.New(backoff.WithInitialDelay(initialDelay)))
 	}
 
 	Component := new(mockComponent)
@@ -148,7 +150,7 @@ func TestComponent(t *testing.T) {
 	nodes[1].Close()
 
 	// wait until about the middle of the backoff period
-	time.Sleep(defaultComponentInitialDelay + defaultMinInterval*2)
+	time.Sleep(initialDelay + backoff.DefaultBackoff().MinInterval*2)
 
 	// tests that broadcasting fails
 	if err := broadcastAndCheck(nodes, Components); err == nil {
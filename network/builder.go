@@ -8,8 +8,10 @@ import (
 	"github.com/cocher/crypto"
 	"github.com/cocher/crypto/blake2b"
 	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/network/codec"
 	"github.com/cocher/network/transport"
 	"github.com/cocher/peer"
+	"github.com/cocher/types/opcode"
 	"github.com/pkg/errors"
 )
 
@@ -25,14 +27,33 @@ var (
 	ErrStrNoAddress = "builder: network requires public server IP for peers to connect to"
 	// ErrStrNoKeyPair returns if no keypair was given to the builder
 	ErrStrNoKeyPair = "builder: cryptography keys not provided to Network; cannot create node ID"
+	// ErrStrKeyPairPolicy returns if the keypair does not belong to the configured signature policy
+	ErrStrKeyPairPolicy = "builder: cryptography keys do not match the signature policy of the Network"
+	// ErrStrKeysPolicy returns if the keys set belong to another signature policy
+	ErrStrKeysPolicy = "builder: keys of signature policy %T do not match the signature policy %T of the Network"
+	// ErrStrDuplicateAddress returns if the same address was given to the builder more than once
+	ErrStrDuplicateAddress = "builder: address %s is already used by the Network"
+	// ErrStrNoTransport returns if no transport layer is registered for the protocol of an address
+	ErrStrNoTransport = "builder: no transport layer registered for address %s"
+	// ErrStrInvalidProtocol returns if a Component declares a malformed protocol
+	ErrStrInvalidProtocol = "builder: protocol %s of a Component is invalid: %v"
+	// ErrStrDuplicateProtocol returns if more than one Component declares the same protocol
+	ErrStrDuplicateProtocol = "builder: protocol %s is declared by more than one Component"
+	// ErrStrProtocolOpcode returns if an opcode is declared by more than one protocol
+	ErrStrProtocolOpcode = "builder: opcode %d belongs to both protocols %s and %s"
+	// ErrStrMissingDependency returns if a Component depends on a Component which is not registered
+	ErrStrMissingDependency = "builder: Component %s depends on Component %s, which is not registered"
+	// ErrStrDependencyCycle returns if Components depend on each other
+	ErrStrDependencyCycle = "builder: Components depend on each other in a cycle: %s"
 )
 
 // Builder is a Address->processors struct
 type Builder struct {
 	opts options
 
-	keys    *crypto.KeyPair
-	address string
+	keys           *crypto.KeyPair
+	address        string
+	extraAddresses []string
 
 	Components     *ComponentList
 	ComponentCount int
@@ -41,15 +62,24 @@ type Builder struct {
 }
 
 var defaultBuilderOptions = options{
-	connectionTimeout: defaultConnectionTimeout,
-	signaturePolicy:   ed25519.New(),
-	hashPolicy:        blake2b.New(),
-	recvWindowSize:    defaultReceiveWindowSize,
-	sendWindowSize:    defaultSendWindowSize,
-	recvBufferSize:    defaultRecvBufferSize,
-	writeBufferSize:   defaultWriteBufferSize,
-	writeFlushLatency: defaultWriteFlushLatency,
-	writeTimeout:      defaultWriteTimeout,
+	connectionTimeout:   defaultConnectionTimeout,
+	signaturePolicy:     ed25519.New(),
+	hashPolicy:          blake2b.New(),
+	recvWindowSize:      defaultReceiveWindowSize,
+	sendWindowSize:      defaultSendWindowSize,
+	recvBufferSize:      defaultRecvBufferSize,
+	writeBufferSize:     defaultWriteBufferSize,
+	writeFlushLatency:   defaultWriteFlushLatency,
+	writeTimeout:        defaultWriteTimeout,
+	batchVerifySize:     defaultBatchVerifySize,
+	batchVerifyDelay:    defaultBatchVerifyDelay,
+	rotationGracePeriod: defaultRotationGrace,
+	preferredIPFamily:   IPv4,
+	dialRaceDelay:       defaultDialRaceDelay,
+	registry:            opcode.DefaultRegistry(),
+	envelopeCodec:       codec.Proto,
+	panicPolicy:         ContinueOnPanic,
+	peerRecordTTL:       defaultPeerRecordTTL,
 }
 
 // A BuilderOption sets options such as connection timeout and cryptographic // policies for the network
@@ -131,6 +161,88 @@ func WriteTimeout(d time.Duration) BuilderOption {
 	}
 }
 
+// BatchVerifySize returns a BuilderOption that sets the maximum number of
+// received signatures verified together, if the signature policy implements
+// crypto.BatchVerifier. A size of 1 verifies every signature on its own
+// (default: 64).
+func BatchVerifySize(size int) BuilderOption {
+	return func(o *options) {
+		o.batchVerifySize = size
+	}
+}
+
+// BatchVerifyDelay returns a BuilderOption that sets how long a received
+// signature may wait for others to be verified with (default: 1ms).
+func BatchVerifyDelay(d time.Duration) BuilderOption {
+	return func(o *options) {
+		o.batchVerifyDelay = d
+	}
+}
+
+// RotationGracePeriod returns a BuilderOption that sets how long messages
+// signed with the previous keys of a peer that rotated its keys are still
+// accepted (default: 10 minutes).
+func RotationGracePeriod(d time.Duration) BuilderOption {
+	return func(o *options) {
+		o.rotationGracePeriod = d
+	}
+}
+
+// PeerRecordTTL returns a BuilderOption that sets how long addresses of peers
+// are kept after they were signed (default: 1 hour). Peers announce fresh
+// addresses whenever they connect. Addresses are kept until they are replaced
+// if the TTL is not positive.
+func PeerRecordTTL(d time.Duration) BuilderOption {
+	return func(o *options) {
+		o.peerRecordTTL = d
+	}
+}
+
+// PreferredIPFamily returns a BuilderOption that sets which IP family is used
+// first for host names resolving to both IPv4 and IPv6 addresses (default: IPv4).
+func PreferredIPFamily(family IPFamily) BuilderOption {
+	return func(o *options) {
+		o.preferredIPFamily = family
+	}
+}
+
+// DialRaceDelay returns a BuilderOption that sets how long dialing an address
+// of a host name may take before the next address is dialed alongside it
+// (default: 250ms).
+func DialRaceDelay(d time.Duration) BuilderOption {
+	return func(o *options) {
+		o.dialRaceDelay = d
+	}
+}
+
+// MessageRegistry returns a BuilderOption that sets the registry of message
+// types the network sends and receives (default: the registry shared by the
+// whole process, which the package level functions of opcode operate on).
+func MessageRegistry(registry *opcode.Registry) BuilderOption {
+	return func(o *options) {
+		o.registry = registry
+	}
+}
+
+// OnPanic returns a BuilderOption that sets what happens after a Component
+// callback panicked (default: ContinueOnPanic).
+func OnPanic(policy PanicPolicy) BuilderOption {
+	return func(o *options) {
+		o.panicPolicy = policy
+	}
+}
+
+// EnvelopeCodec returns a BuilderOption that sets the codec the envelopes of
+// messages are encoded with on the wire (default: protobuf). It is only used
+// over stream connections to peers which encode their envelopes with the
+// same codec, as told by their capabilities; envelopes to any other peer and
+// over UDP are encoded with protobuf.
+func EnvelopeCodec(c codec.Codec) BuilderOption {
+	return func(o *options) {
+		o.envelopeCodec = c
+	}
+}
+
 // NewBuilder returns a new builder with default options.
 func NewBuilder() *Builder {
 	builder := &Builder{
@@ -156,6 +268,9 @@ func NewBuilderWithOptions(opt ...BuilderOption) *Builder {
 		o(&builder.opts)
 	}
 
+	// Default keys should belong to the configured signature policy.
+	builder.keys = builder.opts.signaturePolicy.RandomKeyPair()
+
 	return builder
 }
 
@@ -164,11 +279,49 @@ func (builder *Builder) SetKeys(pair *crypto.KeyPair) {
 	builder.keys = pair
 }
 
+// SetKeysWithPolicy sets the keys to a pair of the given signature policy,
+// such as an identity loaded from a keystore, checking that the policy is the
+// one of the network.
+func (builder *Builder) SetKeysWithPolicy(pair *crypto.KeyPair, sp crypto.SignaturePolicy) error {
+	if reflect.TypeOf(sp) != reflect.TypeOf(builder.opts.signaturePolicy) {
+		return errors.Errorf(ErrStrKeysPolicy, sp, builder.opts.signaturePolicy)
+	}
+
+	builder.keys = pair
+	return nil
+}
+
+// KeyStore loads named key pairs, creating them on first use. It is
+// implemented by *keystore.Keystore.
+type KeyStore interface {
+	LoadOrCreate(name string, sp crypto.SignaturePolicy, passphrase string) (*crypto.KeyPair, crypto.SignaturePolicy, error)
+}
+
+// LoadOrCreateKeys sets the keys to the identity stored under the given name
+// in the key store. On first startup, a new identity is generated with the
+// signature policy of the network and stored encrypted with the passphrase.
+func (builder *Builder) LoadOrCreateKeys(ks KeyStore, name string, passphrase string) error {
+	keys, sp, err := ks.LoadOrCreate(name, builder.opts.signaturePolicy, passphrase)
+	if err != nil {
+		return err
+	}
+
+	return builder.SetKeysWithPolicy(keys, sp)
+}
+
 // SetAddress sets the host address for the network.
 func (builder *Builder) SetAddress(address string) {
 	builder.address = address
 }
 
+// AddAddress adds an address for the network to listen on besides the one set
+// with SetAddress, for example to accept peers over several transports or over
+// both IPv4 and IPv6. All addresses are advertised to peers. Transports listen
+// on all interfaces, so kcp and udp addresses need distinct ports.
+func (builder *Builder) AddAddress(address string) {
+	builder.extraAddresses = append(builder.extraAddresses, address)
+}
+
 // AddComponentWithPriority registers a new Component onto the network with a set priority.
 func (builder *Builder) AddComponentWithPriority(priority int, Component ComponentInterface) error {
 	// Initialize Component list if not exist.
@@ -211,6 +364,11 @@ func (builder *Builder) Build() (*Network, error) {
 		return nil, errors.New(ErrStrNoKeyPair)
 	}
 
+	if len(builder.keys.PrivateKey) != builder.opts.signaturePolicy.PrivateKeySize() ||
+		len(builder.keys.PublicKey) != builder.opts.signaturePolicy.PublicKeySize() {
+		return nil, errors.New(ErrStrKeyPairPolicy)
+	}
+
 	if len(builder.address) == 0 {
 		return nil, errors.New(ErrStrNoAddress)
 	}
@@ -222,29 +380,84 @@ func (builder *Builder) Build() (*Network, error) {
 		builder.Components.SortByPriority()
 	}
 
-	unifiedAddress, err := ToUnifiedAddress(builder.address)
+	unifiedAddress, err := ToUnifiedAddressWithFamily(builder.address, builder.opts.preferredIPFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []string{unifiedAddress}
+
+	for _, address := range builder.extraAddresses {
+		unified, err := ToUnifiedAddressWithFamily(address, builder.opts.preferredIPFamily)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, existing := range addresses {
+			if unified == existing {
+				return nil, errors.Errorf(ErrStrDuplicateAddress, unified)
+			}
+		}
+
+		addresses = append(addresses, unified)
+	}
+
+	for _, address := range addresses {
+		addrInfo, err := ParseAddress(address)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := builder.transports.Load(addrInfo.Protocol); !exists {
+			return nil, errors.Errorf(ErrStrNoTransport, address)
+		}
+	}
+
+	if _, err := builder.Components.startupOrder(); err != nil {
+		return nil, err
+	}
+
+	protocols, err := newProtocolSet(builder.Components)
 	if err != nil {
 		return nil, err
 	}
 
-	id := peer.CreateID(unifiedAddress, builder.keys.PublicKey)
+	id := peer.CreateIDWithHashPolicy(unifiedAddress, builder.keys.PublicKey, builder.opts.hashPolicy)
 
 	net := &Network{
 		opts:    builder.opts,
-		ID:      id,
+		id:      id,
 		keys:    builder.keys,
 		Address: unifiedAddress,
 
+		extraAddresses: addresses[1:],
+
 		Components: builder.Components,
 		transports: builder.transports,
 
 		peers:        new(sync.Map),
 		connections:  new(sync.Map),
 		udpDialAddrs: new(sync.Map),
+		successions:  new(sync.Map),
+		peerStore:    NewPeerStore(builder.opts.signaturePolicy, builder.opts.hashPolicy),
 		listeningCh:  make(chan struct{}),
 		kill:         make(chan struct{}),
 	}
 
+	net.protocols.Store(protocols)
+
+	net.routes, err = newRoutes(net, builder.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	net.verifier = newBatchVerifier(
+		builder.opts.signaturePolicy,
+		builder.opts.hashPolicy,
+		builder.opts.batchVerifySize,
+		builder.opts.batchVerifyDelay,
+	)
+
 	net.Init()
 
 	return net, nil
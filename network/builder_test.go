@@ -2,12 +2,25 @@ package network
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/cocher/crypto"
 	"github.com/cocher/crypto/blake2b"
 	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/crypto/keccak"
+	"github.com/cocher/crypto/keystore"
+	"github.com/cocher/crypto/secp256k1"
+	"github.com/cocher/crypto/sha256"
+	"github.com/cocher/crypto/sha3"
+	"github.com/cocher/crypto/sm2"
+	"github.com/cocher/crypto/sm3"
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/types/opcode"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
@@ -85,6 +98,35 @@ func TestBuilderAddress(t *testing.T) {
 	}
 }
 
+func TestBuilderAddAddress(t *testing.T) {
+	t.Parallel()
+
+	builder := NewBuilder()
+	builder.SetAddress("tcp://localhost:3000")
+	builder.AddAddress("kcp://localhost:3000")
+	builder.AddAddress("tcp://[::1]:3001")
+	net, err := builder.Build()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"tcp://127.0.0.1:3000", "kcp://127.0.0.1:3000", "tcp://[::1]:3001"}, net.Addresses())
+	assert.Equal(t, "tcp://127.0.0.1:3000", net.Address, "the address set first is the primary address")
+
+	address, ok := net.listenAddress("kcp")
+	assert.True(t, ok)
+	assert.Equal(t, "kcp://127.0.0.1:3000", address)
+	_, ok = net.listenAddress("udp")
+	assert.False(t, ok)
+
+	// addresses are compared once resolved
+	builder.AddAddress("tcp://127.0.0.1:3000")
+	_, err = builder.Build()
+	assert.EqualError(t, err, fmt.Sprintf(ErrStrDuplicateAddress, "tcp://127.0.0.1:3000"))
+
+	builder = NewBuilder()
+	builder.AddAddress("quic://localhost:3000")
+	_, err = builder.Build()
+	assert.EqualError(t, err, fmt.Sprintf(ErrStrNoTransport, "quic://127.0.0.1:3000"))
+}
+
 func TestDuplicateComponent(t *testing.T) {
 	t.Parallel()
 
@@ -111,6 +153,48 @@ func TestConnectionTimeout(t *testing.T) {
 	assert.Equal(t, net.opts.connectionTimeout, timeout, "connection timeout given should match found")
 }
 
+func TestPreferredIPFamily(t *testing.T) {
+	t.Parallel()
+
+	builder := NewBuilder()
+	net, err := builder.Build()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, IPv4, net.opts.preferredIPFamily, "IPv4 should be preferred by default")
+	assert.Equal(t, defaultDialRaceDelay, net.opts.dialRaceDelay)
+
+	builder = NewBuilderWithOptions(PreferredIPFamily(IPv6), DialRaceDelay(time.Second))
+	builder.SetAddress("tcp://[::1]:3000")
+	net, err = builder.Build()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, IPv6, net.opts.preferredIPFamily, "preferred IP family given should match found")
+	assert.Equal(t, time.Second, net.opts.dialRaceDelay, "dial race delay given should match found")
+	assert.Equal(t, "tcp://[::1]:3000", net.Address)
+}
+
+func TestMessageRegistry(t *testing.T) {
+	t.Parallel()
+
+	builder := NewBuilder()
+	net, err := builder.Build()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, opcode.DefaultRegistry(), net.Registry(), "the default registry should be used by default")
+
+	registry := opcode.NewRegistry()
+	assert.Equal(t, nil, registry.RegisterMessageType(opcode.Opcode(1000), &protobuf.SignedAddress{}))
+
+	builder = NewBuilderWithOptions(MessageRegistry(registry))
+	net, err = builder.Build()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, registry, net.Registry(), "registry given should match found")
+
+	_, err = net.PrepareMessage(context.Background(), &protobuf.SignedAddress{})
+	assert.Equal(t, nil, err, "messages of the registry of the network should be sent")
+
+	assert.Equal(t, nil, registry.Unregister(opcode.Opcode(1000)))
+	_, err = net.PrepareMessage(context.Background(), &protobuf.SignedAddress{})
+	assert.NotEqual(t, nil, err, "unregistered messages should not be sent")
+}
+
 func TestSignaturePolicy(t *testing.T) {
 	t.Parallel()
 
@@ -119,6 +203,18 @@ func TestSignaturePolicy(t *testing.T) {
 	net, err := builder.Build()
 	assert.Equal(t, nil, err)
 	assert.Equal(t, net.opts.signaturePolicy, signaturePolicy, "signature policy given should match found")
+
+	// keys are generated by the configured signature policy
+	secp256k1Policy := secp256k1.New()
+	builder = NewBuilderWithOptions(SignaturePolicy(secp256k1Policy))
+	net, err = builder.Build()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, secp256k1Policy.PublicKeySize(), len(net.GetKeys().PublicKey), "keys should belong to the signature policy")
+
+	// keys of a different signature policy are rejected
+	builder.SetKeys(ed25519.RandomKeyPair())
+	_, err = builder.Build()
+	assert.Equal(t, errors.New(ErrStrKeyPairPolicy).Error(), err.Error())
 }
 
 func TestHashPolicy(t *testing.T) {
@@ -129,6 +225,20 @@ func TestHashPolicy(t *testing.T) {
 	net, err := builder.Build()
 	assert.Equal(t, nil, err)
 	assert.Equal(t, net.opts.hashPolicy, hashPolicy, "hash policy given should match found")
+
+	sm3Policy := sm3.New()
+	builder = NewBuilderWithOptions(HashPolicy(sm3Policy), SignaturePolicy(sm2.New()))
+	net, err = builder.Build()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, net.opts.hashPolicy, sm3Policy, "hash policy given should match found")
+	assert.Equal(t, sm2.PublicKeySize, len(net.GetKeys().PublicKey), "keys should belong to the signature policy")
+
+	for _, hp := range []crypto.HashPolicy{sha256.New(), sha3.New(), keccak.New()} {
+		net, err = NewBuilderWithOptions(HashPolicy(hp)).Build()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, hp.HashBytes(net.GetKeys().PublicKey), net.GetID().Id, "network ID should be hashed with the hash policy")
+		assert.Equal(t, hp, net.GetHashPolicy())
+	}
 }
 
 func TestWindowSize(t *testing.T) {
@@ -184,6 +294,92 @@ func TestWriteTimeout(t *testing.T) {
 	assert.Equal(t, net.opts.writeTimeout, writeTimeout, "write timeout given should match found")
 }
 
+func TestBatchVerify(t *testing.T) {
+	t.Parallel()
+
+	batchVerifySize := 16
+	batchVerifyDelay := 5 * time.Millisecond
+	builder := NewBuilderWithOptions(
+		BatchVerifySize(batchVerifySize),
+		BatchVerifyDelay(batchVerifyDelay),
+	)
+	net, err := builder.Build()
+	if err != nil {
+		t.Errorf("Build() = %+v, expected <nil>", err)
+	}
+	assert.Equal(t, net.verifier.size, batchVerifySize, "batch verify size given should match found")
+	assert.Equal(t, net.verifier.delay, batchVerifyDelay, "batch verify delay given should match found")
+
+	net, err = NewBuilderWithOptions(BatchVerifySize(1)).Build()
+	if err != nil {
+		t.Errorf("Build() = %+v, expected <nil>", err)
+	}
+	assert.Nil(t, net.verifier, "batch verification should be disabled")
+}
+
+func TestLoadOrCreateKeys(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks := keystore.New(dir, keystore.ScryptParams(keystore.LightScryptN, keystore.LightScryptP))
+
+	builder := NewBuilderWithOptions(SignaturePolicy(secp256k1.New()))
+	assert.Nil(t, builder.LoadOrCreateKeys(ks, "node", "passphrase"))
+	net, err := builder.Build()
+	assert.Nil(t, err)
+
+	// the identity should survive a restart
+	builder = NewBuilderWithOptions(SignaturePolicy(secp256k1.New()))
+	assert.Nil(t, builder.LoadOrCreateKeys(ks, "node", "passphrase"))
+	restarted, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, net.GetID(), restarted.GetID(), "identity should be loaded from the keystore")
+
+	// identities of another signature policy are rejected
+	builder = NewBuilder()
+	err = builder.LoadOrCreateKeys(ks, "node", "passphrase")
+	assert.Equal(t, errors.Errorf(ErrStrKeysPolicy, secp256k1.New(), ed25519.New()).Error(), err.Error())
+
+	assert.NotNil(t, NewBuilder().LoadOrCreateKeys(ks, "node", "wrong passphrase"))
+}
+
+func TestSetKeysWithPolicy(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks := keystore.New(dir, keystore.ScryptParams(keystore.LightScryptN, keystore.LightScryptP))
+
+	keys, sp, err := ks.LoadOrCreate("node", secp256k1.New(), "passphrase")
+	assert.Nil(t, err)
+	builder := NewBuilderWithOptions(SignaturePolicy(secp256k1.New()))
+	assert.Nil(t, builder.SetKeysWithPolicy(keys, sp))
+	net, err := builder.Build()
+	assert.Nil(t, err)
+
+	// the identity should survive a restart
+	keys, sp, err = ks.LoadOrCreate("node", secp256k1.New(), "passphrase")
+	assert.Nil(t, err)
+	builder = NewBuilderWithOptions(SignaturePolicy(secp256k1.New()))
+	assert.Nil(t, builder.SetKeysWithPolicy(keys, sp))
+	restarted, err := builder.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, net.GetID(), restarted.GetID(), "identity should be loaded from the keystore")
+
+	builder = NewBuilder()
+	err = builder.SetKeysWithPolicy(keys, sp)
+	assert.Equal(t, errors.Errorf(ErrStrKeysPolicy, sp, ed25519.New()).Error(), err.Error())
+}
+
 func TestPeers(t *testing.T) {
 	var nodes []*Network
 	addresses := []string{"tcp://127.0.0.1:12345", "tcp://127.0.0.1:12346", "tcp://127.0.0.1:12347"}
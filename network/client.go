@@ -3,6 +3,7 @@ package network
 import (
 	"context"
 	"net"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -10,7 +11,6 @@ import (
 	"github.com/cocher/internal/protobuf"
 	"github.com/cocher/peer"
 
-	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 )
 
@@ -18,12 +18,23 @@ import (
 type PeerClient struct {
 	Network *Network
 
-	ID      *peer.ID
-	Address string
+	// id of the peer, nil until it sent a message, and replaced when it
+	// rotates its keys
+	id      *peer.ID
+	idMutex sync.RWMutex
+	// closed once the peer sent a message signed by its ID
+	identified   chan struct{}
+	identifyOnce sync.Once
+	Address      string
 
 	Requests     sync.Map // uint64 -> *RequestState
 	RequestNonce uint64
 
+	// Cancel functions of the contexts the requests of the peer are handled with.
+	handling sync.Map // uint64 -> context.CancelFunc
+	// Streams replying to the requests of the peer.
+	streams sync.Map // uint64 -> *StreamWriter
+
 	stream StreamState
 
 	outgoingReady chan struct{}
@@ -31,6 +42,19 @@ type PeerClient struct {
 
 	jobs chan func()
 
+	// Opcodes and protocols the peer advertised, a *capabilities.
+	capabilities atomic.Value
+
+	// Name of the codec protobuf messages sent to the peer are encoded with.
+	codec atomic.Value
+
+	// Components told the peer connected and not yet told it disconnected,
+	// so that each is told once however it races with Init and Close.
+	notified      map[*ComponentInfo]struct{}
+	notifiedMutex sync.Mutex
+	// set by Init, before which Components are not told the peer connected
+	initialized bool
+
 	closed      uint32 // for atomic ops
 	closeSignal chan struct{}
 	Time        time.Time
@@ -48,7 +72,7 @@ type StreamState struct {
 
 // RequestState represents a state of a request.
 type RequestState struct {
-	data        chan proto.Message
+	data        chan interface{}
 	closeSignal chan struct{}
 }
 
@@ -66,6 +90,7 @@ func createPeerClient(network *Network, address string) (*PeerClient, error) {
 
 		incomingReady: make(chan struct{}),
 		outgoingReady: make(chan struct{}),
+		identified:    make(chan struct{}),
 
 		stream: StreamState{
 			buffer:   make([]byte, 0),
@@ -82,17 +107,56 @@ func createPeerClient(network *Network, address string) (*PeerClient, error) {
 
 // Init initialize a client's Component and starts executing a jobs.
 func (c *PeerClient) Init() {
-	c.Network.Components.Each(func(Component ComponentInterface) {
-		Component.PeerConnect(c)
-	})
+	c.notifiedMutex.Lock()
+	c.initialized = true
+	c.notifiedMutex.Unlock()
+
+	c.Network.Components.eachInfo(c.connectComponent)
 	go c.executeJobs()
 }
 
+// connectComponent tells a Component the peer connected, unless it was told
+// already, is not registered any more, or the client is not initialized yet
+// or closed.
+func (c *PeerClient) connectComponent(info *ComponentInfo) {
+	c.notifiedMutex.Lock()
+	_, notified := c.notified[info]
+	registered, ok := c.Network.Components.GetInfo(info.Component)
+	if notified || !ok || registered != info || !c.initialized || atomic.LoadUint32(&c.closed) == 1 {
+		c.notifiedMutex.Unlock()
+		return
+	}
+	if c.notified == nil {
+		c.notified = make(map[*ComponentInfo]struct{})
+	}
+	c.notified[info] = struct{}{}
+	c.notifiedMutex.Unlock()
+
+	c.Network.safely(info, c, func() {
+		info.Component.PeerConnect(c)
+	})
+}
+
+// disconnectComponent tells a Component the peer disconnected, if it was
+// told the peer connected.
+func (c *PeerClient) disconnectComponent(info *ComponentInfo) {
+	c.notifiedMutex.Lock()
+	_, notified := c.notified[info]
+	delete(c.notified, info)
+	c.notifiedMutex.Unlock()
+
+	if notified {
+		c.Network.safely(info, c, func() {
+			info.Component.PeerDisconnect(c)
+		})
+	}
+}
+
 func (c *PeerClient) executeJobs() {
 	for {
 		select {
 		case job := <-c.jobs:
-			job()
+			c.Network.safely(nil, c, job)
 		case <-c.closeSignal:
 			return
 		}
@@ -107,6 +171,44 @@ func (c *PeerClient) Submit(job func()) {
 	}
 }
 
+// ID returns the ID of the peer, or nil if it did not send a message yet.
+func (c *PeerClient) ID() *peer.ID {
+	c.idMutex.RLock()
+	defer c.idMutex.RUnlock()
+
+	return c.id
+}
+
+// setID sets the ID of the peer. IDs returned by ID are never modified.
+func (c *PeerClient) setID(id peer.ID) {
+	c.idMutex.Lock()
+	c.id = &id
+	c.idMutex.Unlock()
+}
+
+// setIdentified marks the ID of the peer as proven by the signature of a
+// message it sent.
+func (c *PeerClient) setIdentified() {
+	c.identifyOnce.Do(func() {
+		close(c.identified)
+	})
+}
+
+// waitIdentified waits for the peer to prove its ID by sending a signed
+// message. Returns false if it did not in time, or the client was closed.
+func (c *PeerClient) waitIdentified(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.identified:
+		return true
+	case <-c.closeSignal:
+	case <-timer.C:
+	}
+	return false
+}
+
 // Close stops all sessions/streams and cleans up the nodes in routing table.
 func (c *PeerClient) Close() error {
 	if atomic.SwapUint32(&c.closed, 1) == 1 {
@@ -119,51 +221,74 @@ func (c *PeerClient) Close() error {
 	c.stream.isClosed = true
 	c.stream.Unlock()
 
-	c.Network.Components.Each(func(Component ComponentInterface) {
-		Component.PeerDisconnect(c)
-	})
+	c.cancelRequests()
+
+	c.Network.Components.eachInfo(c.disconnectComponent)
+
+	// Components removed meanwhile may not find the peer any more.
+	c.notifiedMutex.Lock()
+	removed := c.notified
+	c.notified = nil
+	c.notifiedMutex.Unlock()
+	for info := range removed {
+		if info.isRemoved() {
+			continue
+		}
+		info := info
+		c.Network.safely(info, c, func() {
+			info.Component.PeerDisconnect(c)
+		})
+	}
 
 	// Remove entries from node's network.
-	if c.ID != nil {
+	if c.ID() != nil {
 		// close out connections
-		if state, ok := c.Network.ConnectionState(c.ID.Address); ok {
-			addrInfo, err := ParseAddress(c.Network.Address)
-			if err != nil {
-				return err
-			}
-			if addrInfo.Protocol == "tcp" || addrInfo.Protocol == "kcp" {
-				state.conn.(net.Conn).Close()
-			}
-			if addrInfo.Protocol == "udp" {
-				state.conn.(*net.UDPConn).Close()
+		if state, ok := c.Network.ConnectionState(c.Address); ok {
+			switch conn := state.conn.(type) {
+			case *net.UDPConn:
+				conn.Close()
+			case net.Conn:
+				conn.Close()
 			}
 		}
 
-		c.Network.peers.Delete(c.ID.Address)
-		c.Network.connections.Delete(c.ID.Address)
-		c.Network.udpDialAddrs.Delete(c.ID.Address)
+		c.Network.peers.Delete(c.Address)
+		c.Network.connections.Delete(c.Address)
+		c.Network.udpDialAddrs.Delete(c.Address)
 	}
 
 	return nil
 }
 
 // Tell will asynchronously emit a message to a given peer.
-func (c *PeerClient) Tell(ctx context.Context, message proto.Message) error {
-	signed, err := c.Network.PrepareMessage(ctx, message)
+func (c *PeerClient) Tell(ctx context.Context, message interface{}) error {
+	sctx := &SendContext{ctx: c.withCodec(ctx), message: message, addresses: []string{c.Address}}
+	if err := c.Network.intercept(sctx); err != nil {
+		return err
+	}
+
+	clients, err := c.Network.clients(sctx, c)
+	if err != nil || len(clients) == 0 {
+		return err
+	}
+
+	signed, err := c.Network.PrepareMessage(sctx.ctx, sctx.message)
 	if err != nil {
 		return errors.Wrap(err, "failed to sign message")
 	}
 
-	err = c.Network.Write(c.Address, signed)
-	if err != nil {
-		return errors.Wrapf(err, "failed to send message to %s", c.Address)
+	for _, client := range clients {
+		err = c.Network.Write(client.Address, signed)
+		if err != nil {
+			return errors.Wrapf(err, "failed to send message to %s", client.Address)
+		}
 	}
 
 	return nil
 }
 
 // Request requests for a response for a request sent to a given peer.
-func (c *PeerClient) Request(ctx context.Context, req proto.Message) (proto.Message, error) {
+func (c *PeerClient) Request(ctx context.Context, req interface{}) (interface{}, error) {
 	if ctx == nil {
 		return nil, errors.New("network: invalid context")
 	}
@@ -172,42 +297,75 @@ func (c *PeerClient) Request(ctx context.Context, req proto.Message) (proto.Mess
 		return nil, ctx.Err()
 	}
 
-	signed, err := c.Network.PrepareMessage(ctx, req)
-	if err != nil {
+	sctx := &SendContext{ctx: c.withCodec(ctx), message: req, addresses: []string{c.Address}, request: true}
+	if err := c.Network.intercept(sctx); err != nil {
 		return nil, err
 	}
 
-	signed.RequestNonce = atomic.AddUint64(&c.RequestNonce, 1)
+	if len(sctx.addresses) != 1 {
+		return nil, errors.Errorf("network: request must be sent to exactly one peer, not %d", len(sctx.addresses))
+	}
+
+	clients, err := c.Network.clients(sctx, c)
+	if err != nil {
+		return nil, err
+	}
+	// The reply is awaited from the peer the request was rerouted to.
+	target := clients[0]
 
-	err = c.Network.Write(c.Address, signed)
+	signed, err := c.Network.PrepareMessage(sctx.ctx, sctx.message)
 	if err != nil {
 		return nil, err
 	}
 
-	// Start tracking the request.
-	channel := make(chan proto.Message, 1)
+	signed.RequestNonce = atomic.AddUint64(&target.RequestNonce, 1)
+	setTimeout(ctx, signed)
+
+	// Start tracking the request before it is sent, as the reply may arrive
+	// before Write returns.
+	channel := make(chan interface{}, 1)
 	closeSignal := make(chan struct{})
 
-	c.Requests.Store(signed.RequestNonce, &RequestState{
+	target.Requests.Store(signed.RequestNonce, &RequestState{
 		data:        channel,
 		closeSignal: closeSignal,
 	})
 
 	// Stop tracking the request.
 	defer close(closeSignal)
-	defer c.Requests.Delete(signed.RequestNonce)
+	defer target.Requests.Delete(signed.RequestNonce)
+
+	err = c.Network.Write(target.Address, signed)
+	if err != nil {
+		return nil, err
+	}
 
 	select {
 	case res := <-channel:
+		if reply, ok := res.(*protobuf.ErrorReply); ok {
+			return nil, fromErrorReply(target.Address, reply)
+		}
 		return res, nil
 	case <-ctx.Done():
+		// Let the peer stop handling the request early.
+		target.cancelRequest(signed.RequestNonce)
 		return nil, ctx.Err()
 	}
 }
 
 // Reply is equivalent to Write() with an appended nonce to signal a reply.
-func (c *PeerClient) Reply(ctx context.Context, nonce uint64, message proto.Message) error {
-	msg, err := c.Network.PrepareMessage(ctx, message)
+func (c *PeerClient) Reply(ctx context.Context, nonce uint64, message interface{}) error {
+	sctx := &SendContext{ctx: c.withCodec(ctx), message: message, addresses: []string{c.Address}, nonce: nonce, reply: true}
+	if err := c.Network.intercept(sctx); err != nil {
+		return err
+	}
+
+	clients, err := c.Network.clients(sctx, c)
+	if err != nil || len(clients) == 0 {
+		return err
+	}
+
+	msg, err := c.Network.PrepareMessage(sctx.ctx, sctx.message)
 	if err != nil {
 		return err
 	}
@@ -216,9 +374,11 @@ func (c *PeerClient) Reply(ctx context.Context, nonce uint64, message proto.Mess
 	msg.RequestNonce = nonce
 	msg.ReplyFlag = true
 
-	err = c.Network.Write(c.Address, msg)
-	if err != nil {
-		return err
+	for _, client := range clients {
+		err = c.Network.Write(client.Address, msg)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -301,6 +461,39 @@ func (c *PeerClient) RemoteAddr() net.Addr {
 	return addr
 }
 
+// ObservedAddress returns the address the connection to the peer is seen
+// coming from, in the protocol the peer is known by. It differs from Address
+// when the peer is behind NAT or dialed out from an ephemeral port.
+func (c *PeerClient) ObservedAddress() (string, error) {
+	state, ok := c.Network.ConnectionState(c.Address)
+	if !ok {
+		return "", errors.Errorf("network: no connection to %s", c.Address)
+	}
+
+	// Peers talking udp are only known by the address their packets come from.
+	conn, ok := state.conn.(net.Conn)
+	if !ok {
+		return c.Address, nil
+	}
+
+	addrInfo, err := ParseAddress(c.Address)
+	if err != nil {
+		return "", err
+	}
+
+	host, rawPort, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return "", err
+	}
+
+	port, err := strconv.ParseUint(rawPort, 10, 16)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatAddress(addrInfo.Protocol, host, uint16(port)), nil
+}
+
 // SetDeadline implements net.Conn.
 func (c *PeerClient) SetDeadline(t time.Time) error {
 	c.stream.Lock()
@@ -0,0 +1,120 @@
+// Package codec provides the encodings messages and their envelopes may be
+// sent in, which are looked up by name when a message is received.
+package codec
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProtoName is the name of the protobuf codec, which is used for messages
+	// which do not name a codec.
+	ProtoName = "proto"
+	// JSONName is the name of the JSON codec.
+	JSONName = "json"
+)
+
+// Codec encodes values to bytes and back.
+type Codec interface {
+	// Name identifies the codec on the wire, e.g. "json".
+	Name() string
+
+	// Marshal encodes a value.
+	Marshal(v interface{}) ([]byte, error)
+
+	// Unmarshal decodes data into the value v points to.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// Proto encodes protobuf messages.
+	Proto Codec = protoCodec{}
+	// JSON encodes values with encoding/json.
+	JSON Codec = jsonCodec{}
+)
+
+var (
+	// codecs is a map of <name, Codec> pairs
+	codecs = sync.Map{}
+)
+
+func init() {
+	codecs.Store(ProtoName, Proto)
+	codecs.Store(JSONName, JSON)
+}
+
+// Register makes a codec available to all networks under its name, e.g. a
+// msgpack codec wrapping a msgpack library.
+func Register(c Codec) error {
+	if c.Name() == "" {
+		return errors.New("codec: codec must have a name")
+	}
+	if _, loaded := codecs.LoadOrStore(c.Name(), c); loaded {
+		return errors.Errorf("codec: a codec named %s is already registered", c.Name())
+	}
+	return nil
+}
+
+// Get returns the codec registered under a name. An empty name stands for
+// the protobuf codec.
+func Get(name string) (Codec, error) {
+	if name == "" {
+		return Proto, nil
+	}
+	if c, ok := codecs.Load(name); ok {
+		return c.(Codec), nil
+	}
+	return nil, errors.Errorf("codec: no codec named %s, did you register it?", name)
+}
+
+// Names returns the names of all registered codecs in ascending order.
+func Names() []string {
+	var names []string
+	codecs.Range(func(key, value interface{}) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Name() string {
+	return ProtoName
+}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("codec: %T is not a protobuf message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return errors.Errorf("codec: %T is not a protobuf message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return JSONName
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
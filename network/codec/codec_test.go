@@ -0,0 +1,65 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/cocher/internal/protobuf"
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X, Y int
+}
+
+type upperCodec struct {
+	jsonCodec
+}
+
+func (upperCodec) Name() string {
+	return "upper"
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	c, err := Get("")
+	assert.Nil(t, err)
+	assert.Equal(t, Proto, c)
+
+	raw, err := Proto.Marshal(&protobuf.Bytes{Data: []byte("data")})
+	assert.Nil(t, err)
+	msg := new(protobuf.Bytes)
+	assert.Nil(t, Proto.Unmarshal(raw, msg))
+	assert.Equal(t, []byte("data"), msg.Data)
+
+	// test failure if a value which is no protobuf message is encoded as one
+	_, err = Proto.Marshal(&point{X: 1})
+	assert.NotNil(t, err)
+
+	c, err = Get(JSONName)
+	assert.Nil(t, err)
+	raw, err = c.Marshal(&point{X: 1, Y: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"X":1,"Y":2}`, string(raw))
+
+	p := new(point)
+	assert.Nil(t, c.Unmarshal(raw, p))
+	assert.Equal(t, point{X: 1, Y: 2}, *p)
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	_, err := Get("upper")
+	assert.NotNil(t, err)
+
+	assert.Nil(t, Register(upperCodec{}))
+	assert.NotNil(t, Register(upperCodec{}), "codec names should be unique")
+	assert.NotNil(t, Register(jsonCodec{}), "built-in codecs should not be replaced")
+
+	c, err := Get("upper")
+	assert.Nil(t, err)
+	assert.Equal(t, "upper", c.Name())
+	assert.Contains(t, Names(), "upper")
+	assert.Contains(t, Names(), ProtoName)
+}
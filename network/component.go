@@ -1,12 +1,15 @@
 package network
 
+import "github.com/cocher/peer"
+
 // ComponentInterface is used to proxy callbacks to a particular Component instance.
 type ComponentInterface interface {
 	// Callback for when the network starts listening for peers.
 	Startup(net *Network)
 
-	// Callback for when an incoming message is received. Return true
-	// if the Component will intercept messages to be processed.
+	// Callback for when an incoming message is received. Return
+	// ErrStopPropagation to stop the message from being passed to
+	// Components after this one.
 	Receive(ctx *ComponentContext) error
 
 	// Callback for when the network stops listening for peers.
@@ -19,6 +22,71 @@ type ComponentInterface interface {
 	PeerDisconnect(client *PeerClient)
 }
 
+// RotationHandler is an optional interface for Components that keep track of
+// peer IDs and need to follow nodes, including this one, rotating their keys.
+type RotationHandler interface {
+	// Callback for when a node replaced its previous ID with a successor.
+	Rotate(net *Network, previous peer.ID, successor peer.ID)
+}
+
+// ProtocolProvider is an optional interface for Components that speak a
+// protocol of their own, negotiated with peers when they connect.
+type ProtocolProvider interface {
+	// Protocols returns the versioned protocols the Component speaks.
+	Protocols() []Protocol
+}
+
+// RouteProvider is an optional interface for Components that handle messages
+// per message type. Such Components are only passed the messages they
+// registered handlers for, and their Receive callback is never called.
+type RouteProvider interface {
+	// RegisterHandlers registers the handlers of the Component when the
+	// network is built.
+	RegisterHandlers(router *Router) error
+}
+
+// Middleware is an optional interface for Components that wrap the handling
+// of received messages by all Components after them, e.g. to time it or to
+// recover from panics. Intercept is called instead of Receive.
+type Middleware interface {
+	// Callback for when an incoming message is received. Calling next passes
+	// the message on to the Components after this one, and not calling it
+	// stops the message from being processed any further.
+	Intercept(ctx *ComponentContext, next HandlerFunc) error
+}
+
+// DependencyProvider is an optional interface for Components that rely on
+// other Components being registered, e.g. to look them up with
+// Network.Component. Components are started after and cleaned up before the
+// Components they depend on, whatever their priority.
+type DependencyProvider interface {
+	// Dependencies returns the Component IDs of the Components this one
+	// depends on, e.g. discovery.ComponentID, or wrapped with Optional those
+	// of Components it only has to be started after if they are registered.
+	Dependencies() []interface{}
+}
+
+// optionalDependency is a dependency which need not be registered.
+type optionalDependency struct {
+	key interface{}
+}
+
+// Optional marks the Component ID of a dependency returned by
+// DependencyProvider which need not be registered, e.g. to be started after
+// nat.ComponentID, which sets the address of the node, if NAT traversal is
+// used.
+func Optional(key interface{}) interface{} {
+	return optionalDependency{key: key}
+}
+
+// SendHandler is an optional interface for Components that intercept outgoing
+// messages before they are written, such as to audit, tag, reroute or veto them.
+type SendHandler interface {
+	// Callback for when a message is about to be sent by Tell, Request,
+	// Reply or a Broadcast. Returning an error vetoes the message.
+	Send(ctx *SendContext) error
+}
+
 // Component is an abstract class which all Components extend.
 type Component struct{}
 
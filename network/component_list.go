@@ -3,18 +3,39 @@ package network
 import (
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
 )
 
 // ComponentInfo wraps a priority level with a Component interface.
 type ComponentInfo struct {
 	Priority  int
 	Component ComponentInterface
+
+	// set once the Component is removed after a panic
+	removed uint32
+}
+
+// remove stops the callbacks of the Component from being called. Returns
+// false if it was removed already.
+func (info *ComponentInfo) remove() bool {
+	return atomic.CompareAndSwapUint32(&info.removed, 0, 1)
+}
+
+func (info *ComponentInfo) isRemoved() bool {
+	return atomic.LoadUint32(&info.removed) == 1
 }
 
 // ComponentList holds a statically-typed sorted map of Components
-// registered on Noise.
+// registered on Noise. It is safe for concurrent use.
 type ComponentList struct {
-	keys   map[reflect.Type]*ComponentInfo
+	mutex sync.RWMutex
+	keys  map[reflect.Type]*ComponentInfo
+	// values is replaced rather than modified, so that it may be iterated
+	// over without holding the mutex
 	values []*ComponentInfo
 }
 
@@ -28,19 +49,27 @@ func NewComponentList() *ComponentList {
 
 // SortByPriority sorts the Components list by each Components priority.
 func (m *ComponentList) SortByPriority() {
-	sort.SliceStable(m.values, func(i, j int) bool {
-		return m.values[i].Priority < m.values[j].Priority
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	values := append([]*ComponentInfo{}, m.values...)
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].Priority < values[j].Priority
 	})
+	m.values = values
 }
 
 // PutInfo places a new Components info onto the list.
 func (m *ComponentList) PutInfo(Component *ComponentInfo) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	ty := reflect.TypeOf(Component.Component)
 	if _, ok := m.keys[ty]; ok {
 		return false
 	}
 	m.keys[ty] = Component
-	m.values = append(m.values, Component)
+	m.values = append(m.values[:len(m.values):len(m.values)], Component)
 	return true
 }
 
@@ -52,13 +81,78 @@ func (m *ComponentList) Put(priority int, Component ComponentInterface) bool {
 	})
 }
 
+// insert places a new Components info onto the list after all Components of
+// the same or a lower priority, keeping the list sorted.
+func (m *ComponentList) insert(Component *ComponentInfo) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ty := reflect.TypeOf(Component.Component)
+	if _, ok := m.keys[ty]; ok {
+		return false
+	}
+
+	i := sort.Search(len(m.values), func(i int) bool {
+		return m.values[i].Priority > Component.Priority
+	})
+
+	values := make([]*ComponentInfo, 0, len(m.values)+1)
+	values = append(values, m.values[:i]...)
+	values = append(values, Component)
+	values = append(values, m.values[i:]...)
+
+	m.keys[ty] = Component
+	m.values = values
+	return true
+}
+
+// nextPriority returns a priority after the priorities of all Components.
+func (m *ComponentList) nextPriority() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if len(m.values) == 0 {
+		return 0
+	}
+	return m.values[len(m.values)-1].Priority + 1
+}
+
+// Remove removes a Component given its Component ID, returning its info.
+func (m *ComponentList) Remove(withTy interface{}) (*ComponentInfo, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ty := reflect.TypeOf(withTy)
+	info, ok := m.keys[ty]
+	if !ok {
+		return nil, false
+	}
+	delete(m.keys, ty)
+
+	values := make([]*ComponentInfo, 0, len(m.values)-1)
+	for _, item := range m.values {
+		if item != info {
+			values = append(values, item)
+		}
+	}
+	m.values = values
+
+	return info, true
+}
+
 // Len returns the number of Components in the Component list.
 func (m *ComponentList) Len() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	return len(m.keys)
 }
 
 // GetInfo gets the priority and Component interface given a Component ID. Returns nil if not exists.
 func (m *ComponentList) GetInfo(withTy interface{}) (*ComponentInfo, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	item, ok := m.keys[reflect.TypeOf(withTy)]
 	return item, ok
 }
@@ -73,8 +167,155 @@ func (m *ComponentList) Get(withTy interface{}) (ComponentInterface, bool) {
 }
 
 // Each goes through every Component in ascending order of priority of the Component list.
+// Components removed after a panic are skipped. Components added or removed
+// while going through the list are not taken into account.
 func (m *ComponentList) Each(f func(value ComponentInterface)) {
-	for _, item := range m.values {
-		f(item.Component)
+	m.eachInfo(func(info *ComponentInfo) {
+		f(info.Component)
+	})
+}
+
+func (m *ComponentList) eachInfo(f func(info *ComponentInfo)) {
+	m.mutex.RLock()
+	values := m.values
+	m.mutex.RUnlock()
+
+	for _, item := range values {
+		if !item.isRemoved() {
+			f(item)
+		}
 	}
 }
+
+// startupOrder returns the Components in the order they are started: in
+// ascending order of priority, with every Component moved after the
+// Components it depends on. Errors if a dependency is missing or cyclic.
+func (m *ComponentList) startupOrder() ([]*ComponentInfo, error) {
+	return m.order(false)
+}
+
+// cleanupOrder returns the Components in the order they are cleaned up: in
+// ascending order of priority, with every Component moved before the
+// Components which depend on it.
+func (m *ComponentList) cleanupOrder() ([]*ComponentInfo, error) {
+	return m.order(true)
+}
+
+// order sorts the Components topologically by their dependencies, breaking
+// ties by priority.
+func (m *ComponentList) order(cleanup bool) ([]*ComponentInfo, error) {
+	m.mutex.RLock()
+	keys := make(map[reflect.Type]*ComponentInfo, len(m.keys))
+	for ty, info := range m.keys {
+		keys[ty] = info
+	}
+	values := m.values
+	m.mutex.RUnlock()
+
+	// map of Components to the Components which must come before them
+	before := make(map[*ComponentInfo][]*ComponentInfo, len(values))
+	for _, info := range values {
+		dependencies, err := dependenciesOf(keys, info)
+		if err != nil {
+			return nil, err
+		}
+
+		if !cleanup {
+			before[info] = dependencies
+			continue
+		}
+		for _, dependency := range dependencies {
+			before[dependency] = append(before[dependency], info)
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[*ComponentInfo]int, len(values))
+	ordered := make([]*ComponentInfo, 0, len(values))
+	var path []*ComponentInfo
+
+	var visit func(info *ComponentInfo) error
+	visit = func(info *ComponentInfo) error {
+		switch state[info] {
+		case visited:
+			return nil
+		case visiting:
+			return cycleError(path, info, cleanup)
+		}
+
+		state[info] = visiting
+		path = append(path, info)
+		for _, previous := range before[info] {
+			if err := visit(previous); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[info] = visited
+
+		ordered = append(ordered, info)
+		return nil
+	}
+
+	for _, info := range values {
+		if err := visit(info); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// dependenciesOf looks up the Components a Component depends on.
+func dependenciesOf(keys map[reflect.Type]*ComponentInfo, info *ComponentInfo) ([]*ComponentInfo, error) {
+	provider, ok := info.Component.(DependencyProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	var dependencies []*ComponentInfo
+	for _, key := range provider.Dependencies() {
+		optional, isOptional := key.(optionalDependency)
+		if isOptional {
+			key = optional.key
+		}
+
+		dependency, ok := keys[reflect.TypeOf(key)]
+		if !ok {
+			if isOptional {
+				continue
+			}
+			return nil, errors.Errorf(ErrStrMissingDependency, reflect.TypeOf(info.Component), reflect.TypeOf(key))
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, nil
+}
+
+// cycleError describes the cycle closed by visiting info again, from a
+// Component to the Component it depends on.
+func cycleError(path []*ComponentInfo, info *ComponentInfo, reversed bool) error {
+	start := 0
+	for i, item := range path {
+		if item == info {
+			start = i
+		}
+	}
+
+	names := make([]string, 0, len(path)-start+1)
+	for _, item := range append(path[start:len(path):len(path)], info) {
+		names = append(names, reflect.TypeOf(item.Component).String())
+	}
+
+	if reversed {
+		for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+			names[i], names[j] = names[j], names[i]
+		}
+	}
+
+	return errors.Errorf(ErrStrDependencyCycle, strings.Join(names, " -> "))
+}
@@ -1,10 +1,15 @@
 package network
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/internal/protobuf"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/uber-go/atomic"
@@ -118,3 +123,211 @@ func TestRegisterComponent(t *testing.T) {
 	Component := p.(*Component)
 	assert.NotEqual(t, nil, Component)
 }
+
+func startTestNode(t *testing.T, Components ...ComponentInterface) *Network {
+	builder := NewBuilder()
+	builder.SetKeys(ed25519.RandomKeyPair())
+	builder.SetAddress(FormatAddress("tcp", "localhost", uint16(GetRandomUnusedPort())))
+	for _, Component := range Components {
+		builder.AddComponent(Component)
+	}
+
+	node, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go node.Listen()
+	node.BlockUntilListening()
+
+	return node
+}
+
+func waitFor(condition func() bool) bool {
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if condition() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRuntimeComponents(t *testing.T) {
+	t.Parallel()
+
+	runtime := Protocol{ID: "/test/runtime", Version: "1.0.0"}
+
+	node := startTestNode(t)
+	defer node.Close()
+	other := startTestNode(t, &protocolTestComponent{protocols: []Protocol{runtime}})
+	defer other.Close()
+
+	client, err := other.Client(node.Address)
+	assert.Nil(t, err)
+	assert.True(t, waitFor(func() bool {
+		connected := false
+		node.EachPeer(func(*PeerClient) bool {
+			connected = true
+			return false
+		})
+		return connected
+	}), "expected the node to connect to its peer")
+
+	// Added Components are started and told about connected peers.
+	mock := new(MockComponent)
+	assert.Nil(t, node.AddComponent(mock))
+	assert.NotNil(t, node.AddComponent(new(MockComponent)), "expected Components to be added once")
+	assert.Equal(t, int32(1), mock.startup.Load())
+	assert.Equal(t, int32(1), mock.peerConnect.Load())
+
+	assert.Nil(t, client.Tell(context.Background(), &protobuf.Ping{}))
+	assert.True(t, waitFor(func() bool { return mock.receive.Load() == 1 }), "expected added Component to receive messages")
+
+	// Protocols of added Components are advertised to peers.
+	assert.Nil(t, node.AddComponentWithPriority(-1, &protocolTestComponent{protocols: []Protocol{runtime}}))
+	assert.True(t, waitFor(func() bool { return client.SupportsProtocol(runtime.ID) }), "expected peer to learn the added protocol")
+
+	// Removed Components are told peers disconnected, and cleaned up.
+	assert.Nil(t, node.RemoveComponent(mock))
+	assert.NotNil(t, node.RemoveComponent(mock), "expected Components to be removed once")
+	assert.Equal(t, int32(1), mock.peerDisconnect.Load())
+	assert.Equal(t, int32(1), mock.cleanup.Load())
+
+	assert.Nil(t, node.RemoveComponent((*protocolTestComponent)(nil)))
+	assert.True(t, waitFor(func() bool { return !client.SupportsProtocol(runtime.ID) }), "expected peer to learn the removed protocol")
+
+	assert.Nil(t, client.Tell(context.Background(), &protobuf.Ping{}))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), mock.receive.Load(), "expected removed Component to receive no more messages")
+}
+
+func TestRuntimeComponentsRacePeers(t *testing.T) {
+	t.Parallel()
+
+	node := startTestNode(t)
+	defer node.Close()
+
+	// Components added or removed while peers connect or disconnect are told
+	// about each peer once.
+	for i := 0; i < 50; i++ {
+		address := FormatAddress("tcp", "localhost", uint16(10000+i))
+		client, err := createPeerClient(node, address)
+		assert.Nil(t, err)
+		node.peers.Store(address, client)
+
+		mock := new(MockComponent)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.Init()
+		}()
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, node.AddComponent(mock))
+		}()
+		wg.Wait()
+		assert.Equal(t, int32(1), mock.peerConnect.Load())
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.Close()
+		}()
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, node.RemoveComponent(mock))
+		}()
+		wg.Wait()
+		assert.Equal(t, int32(1), mock.peerDisconnect.Load())
+		node.peers.Delete(address)
+	}
+}
+
+type dependencyTestComponent struct {
+	*Component
+
+	dependencies []interface{}
+}
+
+func (p *dependencyTestComponent) Dependencies() []interface{} {
+	return p.dependencies
+}
+
+type storeTestComponent struct{ dependencyTestComponent }
+
+type cacheTestComponent struct{ dependencyTestComponent }
+
+type apiTestComponent struct{ dependencyTestComponent }
+
+func buildWithDependencies(store, cache, api []interface{}) (*Network, error) {
+	builder := NewBuilder()
+	builder.AddComponentWithPriority(0, &apiTestComponent{dependencyTestComponent{dependencies: api}})
+	builder.AddComponentWithPriority(1, &cacheTestComponent{dependencyTestComponent{dependencies: cache}})
+	builder.AddComponentWithPriority(2, &storeTestComponent{dependencyTestComponent{dependencies: store}})
+	builder.AddComponentWithPriority(3, new(MockComponent))
+	return builder.Build()
+}
+
+func TestComponentDependencies(t *testing.T) {
+	t.Parallel()
+
+	names := func(order []*ComponentInfo, err error) []string {
+		assert.Nil(t, err)
+
+		var names []string
+		for _, info := range order {
+			names = append(names, reflect.TypeOf(info.Component).String())
+		}
+		return names
+	}
+
+	store, cache, api := (*storeTestComponent)(nil), (*cacheTestComponent)(nil), (*apiTestComponent)(nil)
+
+	// Components start after the Components they depend on, and are cleaned
+	// up before them. Other Components keep the order of their priority.
+	net, err := buildWithDependencies(nil, []interface{}{store}, []interface{}{cache, store})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"*network.storeTestComponent",
+		"*network.cacheTestComponent",
+		"*network.apiTestComponent",
+		"*network.MockComponent",
+	}, names(net.Components.startupOrder()))
+	assert.Equal(t, []string{
+		"*network.apiTestComponent",
+		"*network.cacheTestComponent",
+		"*network.storeTestComponent",
+		"*network.MockComponent",
+	}, names(net.Components.cleanupOrder()))
+
+	// Components can not be removed before the Components which depend on
+	// them, nor be added before the Components they depend on.
+	assert.EqualError(t, net.RemoveComponent(store), "network: Component *network.storeTestComponent is required by Component *network.apiTestComponent")
+	assert.Nil(t, net.RemoveComponent(api))
+	assert.Nil(t, net.RemoveComponent(cache))
+	assert.Nil(t, net.RemoveComponent(store))
+	assert.EqualError(t, net.AddComponent(&apiTestComponent{dependencyTestComponent{dependencies: []interface{}{cache}}}),
+		"builder: Component *network.apiTestComponent depends on Component *network.cacheTestComponent, which is not registered")
+
+	// Missing and cyclic dependencies are refused.
+	_, err = buildWithDependencies(nil, []interface{}{(*receiveTestComponent)(nil)}, nil)
+	assert.EqualError(t, err, "builder: Component *network.cacheTestComponent depends on Component *network.receiveTestComponent, which is not registered")
+
+	_, err = buildWithDependencies([]interface{}{api}, []interface{}{store}, []interface{}{cache})
+	assert.EqualError(t, err, "builder: Components depend on each other in a cycle: "+
+		"*network.apiTestComponent -> *network.cacheTestComponent -> *network.storeTestComponent -> *network.apiTestComponent")
+
+	// Optional dependencies order Components if they are registered, and
+	// neither have to be registered nor keep them from being removed.
+	net, err = buildWithDependencies(nil, nil, []interface{}{Optional(store), Optional((*receiveTestComponent)(nil))})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"*network.storeTestComponent",
+		"*network.apiTestComponent",
+		"*network.cacheTestComponent",
+		"*network.MockComponent",
+	}, names(net.Components.startupOrder()))
+	assert.Nil(t, net.RemoveComponent(store))
+	assert.Nil(t, net.AddComponent(&storeTestComponent{dependencyTestComponent{dependencies: []interface{}{Optional(api)}}}))
+}
@@ -10,6 +10,8 @@ type (
 
 const (
 	signMessageCtxKey signMessageCtxKeyType = "signMessage"
+	codecCtxKey       signMessageCtxKeyType = "codec"
+	streamWindowKey   signMessageCtxKeyType = "streamWindow"
 )
 
 // WithSignMessage sets whether the request should be signed
@@ -25,3 +27,31 @@ func GetSignMessage(ctx context.Context) bool {
 	}
 	return sign
 }
+
+// WithCodec sets the name of the codec a protobuf message is encoded with
+func WithCodec(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, codecCtxKey, name)
+}
+
+// GetCodec returns the name of the codec a protobuf message is encoded with,
+// empty for protobuf
+func GetCodec(ctx context.Context) string {
+	name, _ := ctx.Value(codecCtxKey).(string)
+	return name
+}
+
+// WithStreamWindow sets the number of replies to a streaming request buffered
+// before the responder waits for them to be received
+func WithStreamWindow(ctx context.Context, window uint32) context.Context {
+	return context.WithValue(ctx, streamWindowKey, window)
+}
+
+// GetStreamWindow returns the number of replies to a streaming request
+// buffered, 16 unless set with WithStreamWindow
+func GetStreamWindow(ctx context.Context) uint32 {
+	window, ok := ctx.Value(streamWindowKey).(uint32)
+	if !ok || window == 0 {
+		return defaultStreamWindow
+	}
+	return window
+}
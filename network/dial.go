@@ -0,0 +1,140 @@
+package network
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/cocher/network/transport"
+	"github.com/cocher/utils/log"
+	"github.com/pkg/errors"
+)
+
+// dial establishes a connection to an address. If the host of the address is
+// a host name, all of its addresses are raced against each other.
+func (n *Network) dial(address string) (interface{}, error) {
+	addrInfo, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Choose scheme.
+	t, exists := n.transports.Load(addrInfo.Protocol)
+	if !exists {
+		log.Fatal("invalid protocol: " + addrInfo.Protocol)
+	}
+
+	hosts, err := ResolveHost(addrInfo.Host, n.opts.preferredIPFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, host := range hosts {
+		hosts[i] = n.dialHost(host)
+	}
+
+	return dialRace(t.(transport.Layer), hosts, addrInfo.Port, n.opts.dialRaceDelay)
+}
+
+// dialHost returns the loopback address of the same IP family if the host is
+// one this node listens on, as nodes behind NAT may not reach their own
+// public address.
+func (n *Network) dialHost(host string) string {
+	ip := parseIP(host)
+	if ip == nil || ip.IsLoopback() {
+		return host
+	}
+
+	for _, address := range n.Addresses() {
+		if info, err := ParseAddress(address); err == nil && info.Host == host {
+			if familyOf(ip) == IPv6 {
+				return net.IPv6loopback.String()
+			}
+			return "127.0.0.1"
+		}
+	}
+
+	return host
+}
+
+// dialRace dials the hosts in order, starting the next attempt once the
+// previous one failed or has not completed within delay, and returns the
+// first connection established (Happy Eyeballs, RFC 8305). Connections of
+// attempts completing after that are closed.
+func dialRace(layer transport.Layer, hosts []string, port uint16, delay time.Duration) (interface{}, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New(ErrStrNoAvailableAddresses)
+	}
+
+	type attempt struct {
+		conn interface{}
+		err  error
+	}
+
+	results := make(chan attempt, len(hosts))
+	next, pending := 0, 0
+
+	start := func() {
+		address := net.JoinHostPort(hosts[next], strconv.Itoa(int(port)))
+		next++
+		pending++
+
+		go func() {
+			conn, err := layer.Dial(address)
+			results <- attempt{conn: conn, err: err}
+		}()
+	}
+
+	start()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var firstErr error
+
+	for pending > 0 {
+		select {
+		case result := <-results:
+			pending--
+
+			if result.err == nil {
+				go func(pending int) {
+					for i := 0; i < pending; i++ {
+						if late := <-results; late.err == nil {
+							if closer, ok := late.conn.(io.Closer); ok {
+								closer.Close()
+							}
+						}
+					}
+				}(pending)
+
+				return result.conn, nil
+			}
+
+			if firstErr == nil {
+				firstErr = result.err
+			}
+
+			// Move on to the next address right away.
+			if next < len(hosts) {
+				start()
+
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(delay)
+			}
+		case <-timer.C:
+			if next < len(hosts) {
+				start()
+				timer.Reset(delay)
+			}
+		}
+	}
+
+	return nil, firstErr
+}
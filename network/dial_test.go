@@ -0,0 +1,136 @@
+package network
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cocher/network/transport"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// raceLayer is a transport layer whose dials take a set time per host and
+// fail for hosts without one.
+type raceLayer struct {
+	sync.Mutex
+	delays map[string]time.Duration
+	dialed []string
+}
+
+func (l *raceLayer) Listen(port int) (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (l *raceLayer) Dial(address string) (interface{}, error) {
+	host, _, _ := net.SplitHostPort(address)
+
+	l.Lock()
+	l.dialed = append(l.dialed, host)
+	l.Unlock()
+
+	delay, ok := l.delays[host]
+	if !ok {
+		return nil, errors.Errorf("dial %s refused", host)
+	}
+	time.Sleep(delay)
+	return host, nil
+}
+
+func (l *raceLayer) Dialed() []string {
+	l.Lock()
+	defer l.Unlock()
+	return append([]string{}, l.dialed...)
+}
+
+func TestDialRace(t *testing.T) {
+	t.Parallel()
+
+	delay := 50 * time.Millisecond
+
+	testCases := []struct {
+		description string
+		delays      map[string]time.Duration
+		expected    interface{}
+		dialed      []string
+	}{
+		{"fast first address wins alone", map[string]time.Duration{"::1": 0, "127.0.0.1": 0}, "::1", []string{"::1"}},
+		{"stalled first address is raced", map[string]time.Duration{"::1": time.Second, "127.0.0.1": 0}, "127.0.0.1", []string{"::1", "127.0.0.1"}},
+		{"failed first address falls back", map[string]time.Duration{"127.0.0.1": 0}, "127.0.0.1", []string{"::1", "127.0.0.1"}},
+	}
+	for _, tt := range testCases {
+		layer := &raceLayer{delays: tt.delays}
+
+		started := time.Now()
+		conn, err := dialRace(layer, []string{"::1", "127.0.0.1"}, 3000, delay)
+		elapsed := time.Since(started)
+
+		assert.Nil(t, err, tt.description)
+		assert.Equal(t, tt.expected, conn, tt.description)
+		assert.Equal(t, tt.dialed, layer.Dialed(), tt.description)
+		assert.True(t, elapsed < time.Second, tt.description)
+	}
+
+	// a failed attempt does not wait for the race delay
+	layer := &raceLayer{delays: map[string]time.Duration{"127.0.0.1": 0}}
+	started := time.Now()
+	_, err := dialRace(layer, []string{"::1", "127.0.0.1"}, 3000, time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(started) < time.Second)
+
+	// all addresses fail
+	layer = &raceLayer{}
+	_, err = dialRace(layer, []string{"::1", "127.0.0.1"}, 3000, delay)
+	assert.EqualError(t, err, "dial ::1 refused")
+
+	_, err = dialRace(layer, nil, 3000, delay)
+	assert.EqualError(t, err, ErrStrNoAvailableAddresses)
+}
+
+func TestDialRaceIPv6Loopback(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback is not available: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+
+	// nothing listens on the IPv4 loopback, so the IPv6 address wins
+	conn, err := dialRace(transport.NewTCP(), []string{"127.0.0.1", "::1"}, port, time.Second)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer conn.(net.Conn).Close()
+
+	assert.Equal(t, net.JoinHostPort("::1", strconv.Itoa(int(port))), conn.(net.Conn).RemoteAddr().String())
+}
+
+func TestDialHost(t *testing.T) {
+	t.Parallel()
+
+	builder := NewBuilder()
+	builder.SetAddress("tcp://[2001:db8::1]:3000")
+	builder.AddAddress("kcp://192.0.2.1:3000")
+	net, err := builder.Build()
+	assert.Nil(t, err)
+
+	assert.Equal(t, "::1", net.dialHost("2001:db8::1"))
+	assert.Equal(t, "127.0.0.1", net.dialHost("192.0.2.1"))
+	assert.Equal(t, "192.0.2.2", net.dialHost("192.0.2.2"))
+	assert.Equal(t, "::1", net.dialHost("::1"))
+}
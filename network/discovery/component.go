@@ -8,7 +8,10 @@ import (
 	"github.com/cocher/dht"
 	"github.com/cocher/internal/protobuf"
 	"github.com/cocher/network"
+	"github.com/cocher/network/addressmap"
+	"github.com/cocher/network/nat"
 	"github.com/cocher/peer"
+	"github.com/cocher/types/opcode"
 )
 
 type Component struct {
@@ -24,11 +27,41 @@ type Component struct {
 var (
 	ComponentID                            = (*Component)(nil)
 	_           network.ComponentInterface = (*Component)(nil)
+	_           network.RotationHandler    = (*Component)(nil)
+	_           network.ProtocolProvider   = (*Component)(nil)
+	_           network.DependencyProvider = (*Component)(nil)
 )
 
+const (
+	// ProtocolID identifies the discovery protocol.
+	ProtocolID = "/cocher/discovery"
+	// ProtocolVersion is the version of the discovery protocol spoken.
+	ProtocolVersion = "1.0.0"
+)
+
+// Dependencies implements network.DependencyProvider. The routing table is
+// created once the Components setting the address of the node started.
+func (state *Component) Dependencies() []interface{} {
+	return []interface{}{network.Optional(nat.ComponentID), network.Optional(addressmap.ComponentID)}
+}
+
 func (state *Component) Startup(net *network.Network) {
 	// Create routing table.
-	state.Routes = dht.CreateRoutingTable(net.ID)
+	state.Routes = dht.CreateRoutingTable(net.GetID())
+}
+
+// Protocols implements network.ProtocolProvider.
+func (state *Component) Protocols() []network.Protocol {
+	return []network.Protocol{{
+		ID:      ProtocolID,
+		Version: ProtocolVersion,
+		Opcodes: []opcode.Opcode{
+			opcode.PingCode,
+			opcode.PongCode,
+			opcode.LookupNodeRequestCode,
+			opcode.LookupNodeResponseCode,
+		},
+	}}
 }
 
 func (state *Component) Receive(ctx *network.ComponentContext) error {
@@ -92,17 +125,22 @@ func (state *Component) Receive(ctx *network.ComponentContext) error {
 	return nil
 }
 
+// Rotate replaces the routing table entry of a node that rotated its keys.
+func (state *Component) Rotate(net *network.Network, previous peer.ID, successor peer.ID) {
+	state.Routes.Replace(previous, successor)
+}
+
 func (state *Component) Cleanup(net *network.Network) {
 	// TODO: Save routing table?
 }
 
 func (state *Component) PeerDisconnect(client *network.PeerClient) {
 	// Delete peer if in routing table.
-	if client.ID != nil {
-		if state.Routes.PeerExists(*client.ID) {
-			state.Routes.RemovePeer(*client.ID)
+	if client.ID() != nil {
+		if state.Routes.PeerExists(*client.ID()) {
+			state.Routes.RemovePeer(*client.ID())
 
-			log.Infof("Peer %s has disconnected from %s.", client.ID.Address, client.Network.ID.Address)
+			log.Infof("Peer %s has disconnected from %s.", client.ID().Address, client.Network.GetID().Address)
 		}
 	}
 }
@@ -9,9 +9,13 @@ import (
 	"github.com/cocher/dht"
 	"github.com/cocher/internal/protobuf"
 	"github.com/cocher/network"
+	"github.com/cocher/network/rpc"
 	"github.com/cocher/peer"
 )
 
+// lookupPolicy bounds the time a peer is given to reply to a lookup.
+var lookupPolicy = rpc.New(rpc.WithAttemptTimeout(3 * time.Second))
+
 func queryPeerByID(net *network.Network, peerID peer.ID, targetID peer.ID, responses chan []*protobuf.ID) {
 	client, err := net.Client(peerID.Address)
 	if err != nil {
@@ -22,9 +26,7 @@ func queryPeerByID(net *network.Network, peerID peer.ID, targetID peer.ID, respo
 	targetProtoID := protobuf.ID(targetID)
 
 	msg := &protobuf.LookupNodeRequest{Target: &targetProtoID}
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-	response, err := client.Request(ctx, msg)
+	response, err := lookupPolicy.Request(context.Background(), client, msg)
 
 	if err != nil {
 		responses <- []*protobuf.ID{}
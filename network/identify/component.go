@@ -0,0 +1,343 @@
+package identify
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/network"
+	"github.com/cocher/types/opcode"
+	"github.com/cocher/utils/log"
+)
+
+const (
+	// ProtocolID identifies the identify protocol.
+	ProtocolID = "/cocher/identify"
+	// ProtocolVersion is the version of the identify protocol spoken.
+	ProtocolVersion = "1.0.0"
+
+	// DefaultAgentVersion is announced when no agent version is configured.
+	DefaultAgentVersion = "cocher"
+
+	// DefaultObservationThreshold is the number of distinct networks peers
+	// connect from which have to observe the same public host before it is
+	// advertised.
+	DefaultObservationThreshold = 2
+
+	// maxObservers bounds the number of networks the observations of which
+	// are kept, forgetting the oldest first.
+	maxObservers = 256
+	// maxConfirmedHosts bounds the number of observed hosts advertised.
+	maxConfirmedHosts = 8
+)
+
+// Info is what a peer announced about itself when it connected.
+type Info struct {
+	// AgentVersion is the name and version of the software the peer runs.
+	AgentVersion string
+	// Protocols the peer speaks, as IDs with versions.
+	Protocols []string
+	// Opcodes of the message types the peer has registered.
+	Opcodes []opcode.Opcode
+	// ListenAddresses the peer is reachable on, in order of preference.
+	ListenAddresses []string
+	// ObservedAddress is the address the peer saw this node connecting from.
+	ObservedAddress string
+}
+
+// Component is the identify Component. It exchanges metadata with every peer
+// that connects, and learns the public address of this node from the
+// addresses peers observe it connecting from.
+type Component struct {
+	*network.Component
+
+	agentVersion string
+	threshold    int
+
+	net *network.Network
+
+	mutex sync.RWMutex
+	// map of peer addresses to what they announced
+	peers map[string]*Info
+	// map of networks peers connect from to the host they last observed
+	observations map[string]string
+	// networks in observations, in the order they were first seen
+	observers []string
+	// hosts observed from enough networks to be advertised
+	confirmed []string
+}
+
+// ComponentOption are configurable options for the identify Component
+type ComponentOption func(*Component)
+
+// WithAgentVersion sets the name and version of the software announced to peers.
+func WithAgentVersion(agentVersion string) ComponentOption {
+	return func(o *Component) {
+		o.agentVersion = agentVersion
+	}
+}
+
+// WithObservationThreshold sets the number of distinct networks peers connect
+// from which have to observe the same public host before it is advertised.
+func WithObservationThreshold(threshold int) ComponentOption {
+	return func(o *Component) {
+		o.threshold = threshold
+	}
+}
+
+func defaultOptions() ComponentOption {
+	return func(o *Component) {
+		o.agentVersion = DefaultAgentVersion
+		o.threshold = DefaultObservationThreshold
+	}
+}
+
+var (
+	_ network.ComponentInterface = (*Component)(nil)
+	_ network.ProtocolProvider   = (*Component)(nil)
+	_ network.RouteProvider      = (*Component)(nil)
+	// ComponentID is used to check existence of the identify Component
+	ComponentID = (*Component)(nil)
+)
+
+// New returns a new identify Component with specified options
+func New(opts ...ComponentOption) *Component {
+	p := new(Component)
+	defaultOptions()(p)
+
+	p.peers = make(map[string]*Info)
+	p.observations = make(map[string]string)
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Protocols implements network.ProtocolProvider.
+func (p *Component) Protocols() []network.Protocol {
+	return []network.Protocol{{
+		ID:      ProtocolID,
+		Version: ProtocolVersion,
+		Opcodes: []opcode.Opcode{opcode.IdentifyCode},
+	}}
+}
+
+// Startup implements the Component callback
+func (p *Component) Startup(net *network.Network) {
+	p.net = net
+}
+
+// PeerConnect sends the identify message to a peer. It is sent right away
+// rather than from a goroutine, so that it is ordered before any message
+// sent once the peer is ready.
+func (p *Component) PeerConnect(client *network.PeerClient) {
+	msg, err := p.identify(client)
+	if err != nil {
+		log.Warnf("identify: failed to identify to %s: %v", client.Address, err)
+		return
+	}
+
+	if err := client.Tell(context.Background(), msg); err != nil {
+		log.Warnf("identify: failed to identify to %s: %v", client.Address, err)
+	}
+}
+
+// PeerDisconnect forgets what a peer announced.
+func (p *Component) PeerDisconnect(client *network.PeerClient) {
+	p.mutex.Lock()
+	delete(p.peers, client.Address)
+	p.mutex.Unlock()
+}
+
+// RegisterHandlers implements network.RouteProvider.
+func (p *Component) RegisterHandlers(router *network.Router) error {
+	return router.Handle(&protobuf.Identify{}, p.handleIdentify)
+}
+
+// handleIdentify stores what a peer announced, and takes note of the address
+// it observed this node connecting from.
+func (p *Component) handleIdentify(ctx *network.ComponentContext, msg *protobuf.Identify) error {
+	info := &Info{
+		AgentVersion:    msg.AgentVersion,
+		Protocols:       msg.Protocols,
+		ListenAddresses: msg.ListenAddresses,
+		ObservedAddress: msg.ObservedAddress,
+	}
+	for _, code := range msg.Opcodes {
+		info.Opcodes = append(info.Opcodes, opcode.Opcode(code))
+	}
+
+	p.mutex.Lock()
+	p.peers[ctx.Client().Address] = info
+	p.mutex.Unlock()
+
+	if msg.ObservedAddress != "" {
+		remote, err := ctx.Client().ObservedAddress()
+		if err != nil {
+			log.Warnf("identify: failed to get the address of %s: %v", ctx.Client().Address, err)
+			return nil
+		}
+
+		observer, err := observerNetwork(remote)
+		if err != nil {
+			log.Warnf("identify: peer connected from a malformed address %s: %v", remote, err)
+			return nil
+		}
+
+		p.observe(observer, msg.ObservedAddress)
+	}
+
+	return nil
+}
+
+// observerNetwork returns the network a peer connects from, the /24 of IPv4
+// hosts and the /48 of IPv6 hosts, so that a single host can not pose as
+// several observers with several keys or addresses.
+func observerNetwork(address string) (string, error) {
+	info, err := network.ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	ip := net.ParseIP(info.Host)
+	if ip == nil {
+		return info.Host, nil
+	}
+
+	mask := net.CIDRMask(48, 8*net.IPv6len)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, mask = ip4, net.CIDRMask(24, 8*net.IPv4len)
+	}
+
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String(), nil
+}
+
+// Peer returns what the peer connected on the given address announced.
+func (p *Component) Peer(address string) (*Info, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	info, ok := p.peers[address]
+	return info, ok
+}
+
+// ObservedHosts returns the public hosts peers agreed on observing this node
+// connecting from.
+func (p *Component) ObservedHosts() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return append([]string{}, p.confirmed...)
+}
+
+// identify builds the identify message for a peer.
+func (p *Component) identify(client *network.PeerClient) (*protobuf.Identify, error) {
+	observed, err := client.ObservedAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &protobuf.Identify{
+		AgentVersion:    p.agentVersion,
+		ListenAddresses: p.net.AdvertisedAddresses(),
+		ObservedAddress: observed,
+	}
+
+	for _, protocol := range p.net.Protocols() {
+		msg.Protocols = append(msg.Protocols, protocol.String())
+	}
+
+	for _, code := range p.net.Registry().Opcodes() {
+		msg.Opcodes = append(msg.Opcodes, uint32(code))
+	}
+
+	return msg, nil
+}
+
+// observe records that a peer connecting from the observer network saw this
+// node connecting from an address. Each network counts for the host it last
+// observed. Once enough distinct networks saw the same public host, it is
+// advertised on the ports of every address this node listens on in the same
+// IP family, as ports are expected to be forwarded unchanged by the NAT.
+func (p *Component) observe(observer string, observed string) {
+	info, err := network.ParseAddress(observed)
+	if err != nil {
+		log.Warnf("identify: peer observed a malformed address %s: %v", observed, err)
+		return
+	}
+
+	ip := net.ParseIP(info.Host)
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() {
+		return
+	}
+
+	var candidates []string
+	for _, address := range p.net.Addresses() {
+		listen, err := network.ParseAddress(address)
+		if err != nil || listen.Host == info.Host {
+			return
+		}
+
+		if listenIP := net.ParseIP(listen.Host); listenIP != nil && (listenIP.To4() == nil) != (ip.To4() == nil) {
+			continue
+		}
+
+		candidates = append(candidates, network.FormatAddress(listen.Protocol, info.Host, listen.Port))
+	}
+
+	p.mutex.Lock()
+	if _, seen := p.observations[observer]; !seen {
+		if len(p.observers) >= maxObservers {
+			delete(p.observations, p.observers[0])
+			p.observers = p.observers[1:]
+		}
+		p.observers = append(p.observers, observer)
+	}
+	p.observations[observer] = info.Host
+
+	if !p.confirm(info.Host) {
+		p.mutex.Unlock()
+		return
+	}
+	p.mutex.Unlock()
+
+	for _, candidate := range candidates {
+		added, err := p.net.Advertise(candidate)
+		if err != nil {
+			log.Warnf("identify: failed to advertise %s: %v", candidate, err)
+			continue
+		}
+		if added {
+			log.Infof("Other peers may connect to you through the address %s.", candidate)
+		}
+	}
+}
+
+// confirm adds a host to the confirmed hosts once it was observed from enough
+// networks. Returns false if the host was not added.
+func (p *Component) confirm(host string) bool {
+	if len(p.confirmed) >= maxConfirmedHosts {
+		return false
+	}
+	for _, confirmed := range p.confirmed {
+		if confirmed == host {
+			return false
+		}
+	}
+
+	count := 0
+	for _, observed := range p.observations {
+		if observed == host {
+			count++
+		}
+	}
+	if count < p.threshold {
+		return false
+	}
+
+	p.confirmed = append(p.confirmed, host)
+	return true
+}
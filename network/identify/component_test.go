@@ -0,0 +1,165 @@
+package identify
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/network"
+	"github.com/cocher/network/discovery"
+	"github.com/cocher/types/opcode"
+	"github.com/stretchr/testify/assert"
+)
+
+func newNet(t *testing.T, component *Component) *network.Network {
+	builder := network.NewBuilder()
+	builder.SetKeys(ed25519.RandomKeyPair())
+	builder.SetAddress(network.FormatAddress("tcp", "127.0.0.1", uint16(network.GetRandomUnusedPort())))
+	builder.AddComponent(new(discovery.Component))
+	builder.AddComponent(component)
+
+	net, err := builder.Build()
+	if err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	return net
+}
+
+func TestIdentify(t *testing.T) {
+	t.Parallel()
+
+	first, second := New(WithAgentVersion("first/1.0.0")), New(WithAgentVersion("second/1.0.0"))
+
+	firstNet := newNet(t, first)
+	go firstNet.Listen()
+	firstNet.BlockUntilListening()
+	defer firstNet.Close()
+
+	secondNet := newNet(t, second)
+	go secondNet.Listen()
+	secondNet.BlockUntilListening()
+	defer secondNet.Close()
+
+	secondNet.Bootstrap(firstNet.Address)
+
+	var info *Info
+	for deadline := time.Now().Add(3 * time.Second); info == nil && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		info, _ = first.Peer(secondNet.Address)
+	}
+	if !assert.NotNil(t, info, "expected the dialing peer to identify itself") {
+		return
+	}
+
+	assert.Equal(t, "second/1.0.0", info.AgentVersion)
+	assert.Equal(t, []string{
+		discovery.ProtocolID + "/" + discovery.ProtocolVersion,
+		ProtocolID + "/" + ProtocolVersion,
+	}, info.Protocols)
+	assert.Contains(t, info.Opcodes, opcode.IdentifyCode)
+	assert.Equal(t, []string{secondNet.Address}, info.ListenAddresses)
+
+	// The dialed peer sees the connection coming from the address dialed.
+	assert.Equal(t, firstNet.Address, info.ObservedAddress)
+
+	info = nil
+	for deadline := time.Now().Add(3 * time.Second); info == nil && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		info, _ = second.Peer(firstNet.Address)
+	}
+	if !assert.NotNil(t, info, "expected the dialed peer to identify itself") {
+		return
+	}
+
+	assert.Equal(t, "first/1.0.0", info.AgentVersion)
+
+	// The dialing peer connects from an ephemeral port.
+	observed, err := network.ParseAddress(info.ObservedAddress)
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", observed.Host)
+}
+
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	component := New()
+	net := newNet(t, component)
+	component.Startup(net)
+
+	listen, err := network.ParseAddress(net.Address)
+	assert.Nil(t, err)
+	public := network.FormatAddress("tcp", "203.0.113.7", listen.Port)
+
+	// Loopback and private hosts of this node are not learned.
+	component.observe("198.51.100.0/24", network.FormatAddress("tcp", "127.0.0.1", 40000))
+	component.observe("192.0.2.0/24", network.FormatAddress("tcp", "127.0.0.1", 40001))
+	assert.Empty(t, component.ObservedHosts())
+
+	// One network is not enough to trust a public host, however often it says so.
+	component.observe("198.51.100.0/24", network.FormatAddress("tcp", "203.0.113.7", 40000))
+	component.observe("198.51.100.0/24", network.FormatAddress("tcp", "203.0.113.7", 40002))
+	assert.Empty(t, component.ObservedHosts())
+	assert.Equal(t, net.Addresses(), net.AdvertisedAddresses())
+
+	// The public host is advertised on the port this node listens on.
+	component.observe("192.0.2.0/24", network.FormatAddress("tcp", "203.0.113.7", 40001))
+	assert.Equal(t, []string{"203.0.113.7"}, component.ObservedHosts())
+	assert.Equal(t, []string{net.Address, public}, net.AdvertisedAddresses())
+
+	// Hosts of another IP family do not match any listen address.
+	component.observe("198.51.100.0/24", network.FormatAddress("tcp", "2001:db8::1", 40000))
+	component.observe("192.0.2.0/24", network.FormatAddress("tcp", "2001:db8::1", 40001))
+	assert.Equal(t, []string{net.Address, public}, net.AdvertisedAddresses())
+}
+
+func TestObserverNetwork(t *testing.T) {
+	t.Parallel()
+
+	for address, expected := range map[string]string{
+		network.FormatAddress("tcp", "198.51.100.7", 3000):        "198.51.100.0/24",
+		network.FormatAddress("tcp", "198.51.100.200", 3001):      "198.51.100.0/24",
+		network.FormatAddress("udp", "2001:db8:1:2::7", 3000):     "2001:db8:1::/48",
+		network.FormatAddress("tcp", "2001:db8:1:ffff::7", 3000):  "2001:db8:1::/48",
+		network.FormatAddress("tcp", "::ffff:198.51.100.7", 3000): "198.51.100.0/24",
+		network.FormatAddress("tcp", "localhost", 3000):           "localhost",
+	} {
+		observer, err := observerNetwork(address)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, observer, address)
+	}
+
+	_, err := observerNetwork("not an address")
+	assert.NotNil(t, err)
+}
+
+func TestObserveBounds(t *testing.T) {
+	t.Parallel()
+
+	component := New()
+	net := newNet(t, component)
+	component.Startup(net)
+
+	// Each network counts for the host it observed last.
+	component.observe("198.51.100.0/24", network.FormatAddress("tcp", "203.0.113.7", 40000))
+	component.observe("198.51.100.0/24", network.FormatAddress("tcp", "203.0.113.8", 40000))
+	component.observe("192.0.2.0/24", network.FormatAddress("tcp", "203.0.113.7", 40000))
+	assert.Empty(t, component.ObservedHosts())
+
+	// The oldest networks are forgotten once too many observed this node.
+	for i := 0; i < maxObservers+1; i++ {
+		component.observe(fmt.Sprintf("10.%d.%d.0/24", i/256, i%256), network.FormatAddress("tcp", "203.0.113.9", 40000))
+	}
+	component.mutex.Lock()
+	assert.Len(t, component.observations, maxObservers)
+	assert.Len(t, component.observers, maxObservers)
+	_, ok := component.observations["198.51.100.0/24"]
+	component.mutex.Unlock()
+	assert.False(t, ok)
+
+	// Only so many hosts are confirmed.
+	for i := 0; i < maxConfirmedHosts+1; i++ {
+		host := fmt.Sprintf("203.0.114.%d", i)
+		component.observe("198.51.100.0/24", network.FormatAddress("tcp", host, 40000))
+		component.observe("192.0.2.0/24", network.FormatAddress("tcp", host, 40000))
+	}
+	assert.Len(t, component.ObservedHosts(), maxConfirmedHosts)
+}
@@ -81,6 +81,7 @@ func defaultOptions() ComponentOption {
 
 var (
 	_ network.ComponentInterface = (*Component)(nil)
+	_ network.RouteProvider      = (*Component)(nil)
 	// ComponentID is used to check existence of the keepalive Component
 	ComponentID = (*Component)(nil)
 )
@@ -120,19 +121,15 @@ func (p *Component) PeerDisconnect(client *network.PeerClient) {
 	p.updateLastStateAndNotify(client, PEER_UNREACHABLE)
 }
 
-func (p *Component) Receive(ctx *network.ComponentContext) error {
-	switch ctx.Message().(type) {
-	case *protobuf.Keepalive:
-		// Send keepalive response to peer.
-		err := ctx.Reply(context.Background(), &protobuf.KeepaliveResponse{})
-
-		if err != nil {
-			return err
-		}
-	case *protobuf.KeepaliveResponse:
-	}
+// RegisterHandlers implements network.RouteProvider. Keepalive responses
+// need no handling, as receiving any message marks the peer as alive.
+func (p *Component) RegisterHandlers(router *network.Router) error {
+	return router.Handle(&protobuf.Keepalive{}, p.handleKeepalive)
+}
 
-	return nil
+func (p *Component) handleKeepalive(ctx *network.ComponentContext, msg *protobuf.Keepalive) error {
+	// Send keepalive response to peer.
+	return ctx.Reply(context.Background(), &protobuf.KeepaliveResponse{})
 }
 
 func (p *Component) keepaliveService() {
@@ -3,28 +3,159 @@ package network
 import (
 	"context"
 
-	"github.com/gogo/protobuf/proto"
 	"github.com/cocher/peer"
+	"github.com/cocher/utils/log"
+
+	"github.com/pkg/errors"
 )
 
 // ComponentContext provides parameters and helper functions to a Component
 // for interacting with/analyzing incoming messages from a select peer.
+//
+// A ComponentContext is reused for other messages once the handlers of the
+// message ran, and must not be kept beyond them, unless the handlers took
+// over its stream with Stream. Replies sent later go through the StreamWriter
+// and its Context, or PeerClient.Reply.
 type ComponentContext struct {
 	client  *PeerClient
-	message proto.Message
+	message interface{}
 	nonce   uint64
+	// values set by Components for the Components after them
+	values map[interface{}]interface{}
+
+	// whether the message is a request awaiting a reply
+	request bool
+	// whether a reply was sent
+	replied bool
+	// first error returned handling the message
+	err error
+	// stream replying to the message if it is a streaming request
+	stream *StreamWriter
+	// whether a handler took over the stream
+	streamed bool
+	// context of the request, and its cancel function
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Context returns the context of a request, which is done once the deadline
+// the requester set passed, the requester canceled the request or the request
+// was handled. Streaming requests are handled once their stream was closed.
+// Other messages are handled with the background context.
+func (pctx *ComponentContext) Context() context.Context {
+	if pctx.ctx == nil {
+		return context.Background()
+	}
+	return pctx.ctx
 }
 
 // Reply sends back a message to an incoming message's incoming stream.
-func (pctx *ComponentContext) Reply(ctx context.Context, message proto.Message) error {
+func (pctx *ComponentContext) Reply(ctx context.Context, message interface{}) error {
+	pctx.replied = true
 	return pctx.client.Reply(ctx, pctx.nonce, message)
 }
 
-// Message returns the decoded protobuf message.
-func (pctx *ComponentContext) Message() proto.Message {
+// ReplyError replies to a request with an error, which the requester's
+// Request returns as a *RemoteError. The code and details of the error are
+// replied if it is a *RemoteError. Other errors are replied with
+// ErrorCodeUnknown and a generic message, so that their text is not sent to
+// the peer. Streaming requests are ended with the error.
+//
+// Requests which handlers registered for their type with a Router returned
+// an error for, and did not reply to, are replied to with the first error
+// returned. Errors returned by the Receive callback of Components are only
+// logged, as the Component may not be the one handling the request.
+func (pctx *ComponentContext) ReplyError(ctx context.Context, err error) error {
+	pctx.replied = true
+	if pctx.stream != nil {
+		pctx.streamed = true
+		return pctx.stream.CloseWithError(ctx, err)
+	}
+	return pctx.client.Reply(ctx, pctx.nonce, toErrorReply(err))
+}
+
+// IsStream returns true if the message is a streaming request, which may be
+// replied to many times through Stream.
+func (pctx *ComponentContext) IsStream() bool {
+	return pctx.stream != nil
+}
+
+// Stream takes over the replies to a streaming request. The stream must be
+// closed once all replies were sent. Streaming requests which are not taken
+// over are ended after the handlers of the request ran, and any reply they
+// sent with Reply.
+func (pctx *ComponentContext) Stream() (*StreamWriter, error) {
+	if pctx.stream == nil {
+		return nil, errors.New("network: message is not a streaming request")
+	}
+	pctx.streamed = true
+	return pctx.stream, nil
+}
+
+// fail records an error returned handling the message.
+func (pctx *ComponentContext) fail(err error) {
+	if pctx.err == nil {
+		pctx.err = err
+	}
+}
+
+// finishRequest ends the handling of a request once its handlers ran, so
+// that the requester does not wait for replies until it times out. Requests
+// which were not replied to are replied to with the first error returned by
+// their handlers or middleware.
+func (pctx *ComponentContext) finishRequest() {
+	if !pctx.request {
+		return
+	}
+
+	var err error
+	switch stream := pctx.stream; {
+	case stream != nil && pctx.streamed:
+		if pctx.err != nil {
+			err = stream.CloseWithError(context.Background(), pctx.err)
+		}
+	case stream != nil && pctx.err != nil && !pctx.replied:
+		err = stream.CloseWithError(context.Background(), pctx.err)
+	case stream != nil && pctx.replied:
+		err = stream.Close(context.Background())
+	case stream != nil:
+		// The request may be replied to later with PeerClient.Reply.
+		stream.finish(ErrStreamClosed)
+	case pctx.err != nil && !pctx.replied:
+		err = pctx.client.Reply(context.Background(), pctx.nonce, toErrorReply(pctx.err))
+	}
+
+	if err != nil {
+		log.Warnf("network: failed to reply to peer %s: %v", pctx.client.Address, err)
+	}
+
+	// Streams are handled once they were closed.
+	if pctx.stream == nil {
+		pctx.cancel()
+	}
+}
+
+// Message returns the decoded message, a protobuf message unless its type was
+// registered with another codec.
+func (pctx *ComponentContext) Message() interface{} {
 	return pctx.message
 }
 
+// Set annotates the message with a value under a key, for the Components
+// handling the message after this one.
+func (pctx *ComponentContext) Set(key interface{}, value interface{}) {
+	if pctx.values == nil {
+		pctx.values = make(map[interface{}]interface{})
+	}
+	pctx.values[key] = value
+}
+
+// Get returns the value a Component set on the message under a key.
+func (pctx *ComponentContext) Get(key interface{}) (interface{}, bool) {
+	value, ok := pctx.values[key]
+	return value, ok
+}
+
 // Client returns the peer client.
 func (pctx *ComponentContext) Client() *PeerClient {
 	return pctx.client
@@ -37,12 +168,12 @@ func (pctx *ComponentContext) Network() *Network {
 
 // Self returns the node's ID.
 func (pctx *ComponentContext) Self() peer.ID {
-	return pctx.Network().ID
+	return pctx.Network().GetID()
 }
 
 // Sender returns the peer's ID.
 func (pctx *ComponentContext) Sender() peer.ID {
-	return *pctx.client.ID
+	return *pctx.client.ID()
 }
 
 func (pctx *ComponentContext) Disconnect() {
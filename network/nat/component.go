@@ -7,7 +7,6 @@ import (
 	"github.com/fd/go-nat"
 	"github.com/cocher/utils/log"
 	"github.com/cocher/network"
-	"github.com/cocher/peer"
 )
 
 type Component struct {
@@ -76,8 +75,7 @@ func (p *Component) Startup(n *network.Network) {
 	info.Port = uint16(p.externalPort)
 
 	// Set peer information based off of port mapping info.
-	n.Address = info.String()
-	n.ID = peer.CreateID(n.Address, n.GetKeys().PublicKey)
+	n.SetAddress(info.String())
 
 	log.Infof("Other peers may connect to you through the address %s.", n.Address)
 }
@@ -96,7 +94,9 @@ func (p *Component) Cleanup(n *network.Network) {
 // RegisterComponent registers a Component that automates port-forwarding of this nodes
 // listening socket through any available UPnP interface.
 //
-// The Component is registered with a priority of -999999, and thus is executed first.
+// Components using the address of the node on Startup, such as discovery, declare
+// it as an optional dependency with network.Optional(nat.ComponentID) to be started
+// after it.
 func RegisterComponent(builder *network.Builder) {
-	builder.AddComponentWithPriority(-99999, new(Component))
+	builder.AddComponent(new(Component))
 }
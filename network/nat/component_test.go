@@ -1,4 +1,4 @@
-package nat
+package nat_test
 
 import (
 	"testing"
@@ -6,6 +6,7 @@ import (
 
 	"github.com/cocher/network"
 	"github.com/cocher/network/discovery"
+	"github.com/cocher/network/nat"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,7 +20,7 @@ func TestNatConnect(t *testing.T) {
 		b := network.NewBuilder()
 		port := network.GetRandomUnusedPort()
 		b.SetAddress(network.FormatAddress("tcp", "localhost", uint16(port)))
-		RegisterComponent(b)
+		nat.RegisterComponent(b)
 		b.AddComponent(new(discovery.Component))
 		n, err := b.Build()
 		go n.Listen()
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/cocher/crypto"
 	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/network/codec"
 	"github.com/cocher/network/transport"
 	"github.com/cocher/peer"
 	"github.com/cocher/types/opcode"
@@ -20,7 +22,6 @@ import (
 	"os/signal"
 	"syscall"
 
-	"github.com/gogo/protobuf/proto"
 	"github.com/cocher/utils/log"
 	"github.com/pkg/errors"
 )
@@ -41,6 +42,11 @@ const (
 	defaultWriteFlushLatency = 50 * time.Millisecond
 	defaultWriteTimeout      = 3 * time.Second
 	defaultWriteMode         = WRITE_MODE_LOOP
+	defaultBatchVerifySize   = 64
+	defaultBatchVerifyDelay  = 1 * time.Millisecond
+	defaultRotationGrace     = 10 * time.Minute
+	defaultDialRaceDelay     = 250 * time.Millisecond
+	defaultPeerRecordTTL     = 1 * time.Hour
 )
 
 var contextPool = sync.Pool{
@@ -55,6 +61,10 @@ var (
 
 // Network represents the current networking state for this node.
 type Network struct {
+	// Number of panics recovered from, first for 64-bit alignment of atomic
+	// operations.
+	panics uint64
+
 	netID uint32
 
 	opts options
@@ -62,15 +72,39 @@ type Network struct {
 	// Node's keypair.
 	keys *crypto.KeyPair
 
+	// Guards keys and ID while they are rotated.
+	identityMutex sync.RWMutex
+
 	// Full address to listen on. `protocol://host:port`
 	Address string
 
+	// Further addresses to listen on besides Address.
+	extraAddresses []string
+
+	// Addresses this node is reachable on without listening on them, such
+	// as public addresses behind NAT.
+	advertisedAddresses []string
+	advertisedMutex     sync.RWMutex
+
 	// Map of Components registered to the network.
 	// map[string]Component
 	Components *ComponentList
 
-	// Node's cryptographic ID.
-	ID peer.ID
+	// Protocols spoken by the Components, a *protocolSet.
+	protocols atomic.Value
+
+	// Serializes adding and removing Components with starting and stopping
+	// them.
+	lifecycleMutex sync.Mutex
+	// Whether the Components were started and not cleaned up yet.
+	started bool
+
+	// Handlers of received messages, by opcode.
+	routes *routeTable
+
+	// Node's cryptographic ID, read with GetID. It changes when the node
+	// rotates its keys.
+	id peer.ID
 
 	// Map of real remote ip:port (key) with real local ip:port(value);
 	// especially, when a udp client call server, local port is dynamic,
@@ -78,7 +112,13 @@ type Network struct {
 	udpDialAddrs *sync.Map
 
 	// Map of connection addresses (string) <-> *network.PeerClient
-	// so that the Network doesn't dial multiple times to the same ip
+	// so that the Network doesn't dial multiple times to the same ip.
+	//
+	// Peers and connections are keyed by address rather than by public key,
+	// as a connection exists before the peer proves its key by signing a
+	// message, packets of udp and kcp peers are told apart by the address
+	// they come from, and a peer which rotates its keys keeps its
+	// connections. ClientByID and the peer store look peers up by key.
 	peers *sync.Map
 
 	//RecvQueue chan *protobuf.Message
@@ -89,25 +129,44 @@ type Network struct {
 	// Map of protocol addresses (string) <-> *transport.Layer
 	transports *sync.Map
 
+	// Map of public key hashes (string) of peers that rotated their keys <-> *succession
+	successions *sync.Map
+
+	// Signed addresses of peers, keyed by their public key hashes.
+	peerStore *PeerStore
+
 	// listeningCh will block a goroutine until this node is listening for peers.
 	listeningCh chan struct{}
 
+	// Verifies signatures of received messages in batches, if the signature
+	// policy supports it.
+	verifier *batchVerifier
+
 	// <-kill will begin the server shutdown process
 	kill chan struct{}
 }
 
 // options for network struct
 type options struct {
-	connectionTimeout time.Duration
-	signaturePolicy   crypto.SignaturePolicy
-	hashPolicy        crypto.HashPolicy
-	recvWindowSize    int
-	sendWindowSize    int
-	writeBufferSize   int
-	recvBufferSize    int
-	writeFlushLatency time.Duration
-	writeTimeout      time.Duration
-	writeMode         writeMode
+	connectionTimeout   time.Duration
+	signaturePolicy     crypto.SignaturePolicy
+	hashPolicy          crypto.HashPolicy
+	recvWindowSize      int
+	sendWindowSize      int
+	writeBufferSize     int
+	recvBufferSize      int
+	writeFlushLatency   time.Duration
+	writeTimeout        time.Duration
+	writeMode           writeMode
+	batchVerifySize     int
+	batchVerifyDelay    time.Duration
+	rotationGracePeriod time.Duration
+	preferredIPFamily   IPFamily
+	dialRaceDelay       time.Duration
+	registry            *opcode.Registry
+	envelopeCodec       codec.Codec
+	panicPolicy         PanicPolicy
+	peerRecordTTL       time.Duration
 }
 
 // ConnState represents a connection.
@@ -117,13 +176,22 @@ type ConnState struct {
 	messageNonce uint64
 	writerMutex  *sync.Mutex
 	IsDial       bool // when dial out to server on udp condition, value is true; otherwise, it is false;
+	// encodeEnvelopes is set to 1 once the peer advertised that it encodes
+	// envelopes with the same codec as this node, so that envelopes sent on
+	// the connection are encoded with it
+	encodeEnvelopes uint32
 }
 
 // Init starts all network I/O workers.
 func (n *Network) Init() {
 	// Spawn write flusher.
 	go n.flushLoop()
+	go n.pruneLoop()
 	go n.waitExit()
+
+	if n.verifier != nil {
+		go n.verifier.loop(n.kill)
+	}
 }
 
 func (n *Network) waitExit() {
@@ -137,6 +205,25 @@ func (n *Network) waitExit() {
 	}
 }
 
+// pruneLoop forgets the addresses of peers signed longer ago than the peer
+// record TTL.
+func (n *Network) pruneLoop() {
+	if n.opts.peerRecordTTL/4 <= 0 {
+		return
+	}
+
+	t := time.NewTicker(n.opts.peerRecordTTL / 4)
+	defer t.Stop()
+	for {
+		select {
+		case <-n.kill:
+			return
+		case now := <-t.C:
+			n.peerStore.Prune(now.Add(-n.opts.peerRecordTTL))
+		}
+	}
+}
+
 func (n *Network) flushLoop() {
 	t := time.NewTicker(n.opts.writeFlushLatency)
 	defer t.Stop()
@@ -161,15 +248,46 @@ func (n *Network) flushLoop() {
 
 // GetKeys returns the keypair for this network
 func (n *Network) GetKeys() *crypto.KeyPair {
+	n.identityMutex.RLock()
+	defer n.identityMutex.RUnlock()
+
 	return n.keys
 }
 
+// GetID returns the ID of this network, which changes when it rotates its keys
+func (n *Network) GetID() peer.ID {
+	n.identityMutex.RLock()
+	defer n.identityMutex.RUnlock()
+
+	return n.id
+}
+
+// SetAddress sets the address peers know this node by, such as the public
+// address of a port mapping, and derives the ID of the node from it.
+func (n *Network) SetAddress(address string) {
+	n.identityMutex.Lock()
+	defer n.identityMutex.Unlock()
+
+	n.Address = address
+	n.id = peer.CreateIDWithHashPolicy(address, n.keys.PublicKey, n.opts.hashPolicy)
+}
+
+// GetHashPolicy returns the hash policy for this network
+func (n *Network) GetHashPolicy() crypto.HashPolicy {
+	return n.opts.hashPolicy
+}
+
+// Registry returns the registry of message types this node sends and receives.
+func (n *Network) Registry() *opcode.Registry {
+	return n.opts.registry
+}
+
 func (n *Network) dispatchMessage(client *PeerClient, msg *protobuf.Message) {
 	if !client.IsIncomingReady() {
 		return
 	}
 
-	var ptr proto.Message
+	var ptr interface{}
 	// unmarshal message based on specified opcode
 	code := opcode.Opcode(msg.Opcode)
 	switch code {
@@ -185,19 +303,34 @@ func (n *Network) dispatchMessage(client *PeerClient, msg *protobuf.Message) {
 		ptr = &protobuf.LookupNodeResponse{}
 	case opcode.DisconnectCode:
 		ptr = &protobuf.Disconnect{}
+	case opcode.KeySuccessionCode:
+		ptr = &protobuf.KeySuccession{}
+	case opcode.PeerRecordCode:
+		ptr = &protobuf.PeerRecord{}
+	case opcode.CapabilitiesCode:
+		ptr = &protobuf.Capabilities{}
+	case opcode.ErrorReplyCode:
+		ptr = &protobuf.ErrorReply{}
+	case opcode.StreamControlCode:
+		ptr = &protobuf.StreamControl{}
 	case opcode.UnregisteredCode:
 		log.Error("network: message received had no opcode")
 		return
 	default:
 		var err error
-		ptr, err = opcode.GetMessageType(code)
+		ptr, err = n.opts.registry.New(code)
 		if err != nil {
 			log.Error("network: received message opcode is not registered")
 			return
 		}
 	}
 	if len(msg.Message) > 0 {
-		if err := proto.Unmarshal(msg.Message, ptr); err != nil {
+		c, err := codec.Get(msg.Codec)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		if err := c.Unmarshal(msg.Message, ptr); err != nil {
 			log.Error(err)
 			return
 		}
@@ -219,21 +352,53 @@ func (n *Network) dispatchMessage(client *PeerClient, msg *protobuf.Message) {
 	switch msgRaw := ptr.(type) {
 	case *protobuf.Bytes:
 		client.handleBytes(msgRaw.Data)
+	case *protobuf.KeySuccession:
+		n.handleSuccession(client, msgRaw, msg.RequestNonce)
+	case *protobuf.PeerRecord:
+		if !msg.ReplyFlag {
+			n.handlePeerRecord(client, msgRaw, msg.RequestNonce)
+		}
+	case *protobuf.Capabilities:
+		n.handleCapabilities(client, msgRaw)
+	case *protobuf.StreamControl:
+		if !msg.ReplyFlag {
+			client.handleStreamControl(msg.RequestNonce, msgRaw)
+		}
 	default:
 		ctx := contextPool.Get().(*ComponentContext)
 		ctx.client = client
 		ctx.message = msgRaw
 		ctx.nonce = msg.RequestNonce
+		ctx.values = nil
+		ctx.request = msg.RequestNonce > 0 && !msg.ReplyFlag
+		ctx.replied = false
+		ctx.err = nil
+		ctx.ctx, ctx.cancel = nil, nil
+		ctx.stream = nil
+		ctx.streamed = false
+		if ctx.request {
+			ctx.ctx, ctx.cancel = client.requestContext(msg.RequestNonce, msg.Timeout)
+			if msg.StreamWindow > 0 {
+				ctx.stream = newStreamWriter(ctx.ctx, ctx.cancel, client, msg.RequestNonce, msg.StreamWindow)
+			}
+		}
+
+		handler := n.routes.handler(code)
 
 		go func() {
-			// Execute 'on receive message' callback for all Components.
-			n.Components.Each(func(Component ComponentInterface) {
-				if err := Component.Receive(ctx); err != nil {
-					log.Errorf("%+v", err)
-				}
-			})
+			// Execute the handlers of the message, including the 'on
+			// receive message' callback of Components without handlers.
+			if err := handler(ctx); err != nil && errors.Cause(err) != ErrStopPropagation {
+				log.Errorf("%+v", err)
+				ctx.fail(err)
+			}
+			ctx.finishRequest()
 
-			contextPool.Put(ctx)
+			// Handlers which took over the stream may still use the
+			// context until they close it.
+			if !ctx.streamed {
+				contextPool.Put(ctx)
+			}
 		}()
 	}
 }
@@ -241,87 +406,217 @@ func (n *Network) dispatchMessage(client *PeerClient, msg *protobuf.Message) {
 // Listen starts listening for peers on a port.
 func (n *Network) Listen() {
 
-	// Handle 'network starts listening' callback for Components.
-	n.Components.Each(func(Component ComponentInterface) {
-		Component.Startup(n)
-	})
+	// Handle 'network starts listening' callback for Components, which are
+	// started after the Components they depend on.
+	n.lifecycleMutex.Lock()
+	order, err := n.Components.startupOrder()
+	if err != nil {
+		n.lifecycleMutex.Unlock()
+		log.Fatal(err)
+	}
+	for _, info := range order {
+		if !info.isRemoved() {
+			n.safely(info, nil, func() {
+				info.Component.Startup(n)
+			})
+		}
+	}
+	n.started = true
+	n.lifecycleMutex.Unlock()
 
-	// Handle 'network stops listening' callback for Components.
+	// Handle 'network stops listening' callback for Components, which are
+	// cleaned up before the Components they depend on.
 	defer func() {
-		n.Components.Each(func(Component ComponentInterface) {
-			Component.Cleanup(n)
-		})
+		n.lifecycleMutex.Lock()
+		defer n.lifecycleMutex.Unlock()
+
+		n.started = false
+
+		order, err := n.Components.cleanupOrder()
+		if err != nil {
+			log.Errorf("%+v", err)
+			return
+		}
+		for _, info := range order {
+			if !info.isRemoved() {
+				n.safely(info, nil, func() {
+					info.Component.Cleanup(n)
+				})
+			}
+		}
 	}()
 
-	addrInfo, err := ParseAddress(n.Address)
-	if err != nil {
-		log.Fatal(err)
+	// Transports listen on all interfaces, so addresses sharing a protocol
+	// and port are served by a single listener.
+	type listenerKey struct {
+		protocol string
+		port     uint16
 	}
 
-	var listener interface{}
-	if t, exists := n.transports.Load(addrInfo.Protocol); exists {
-		listener, err = t.(transport.Layer).Listen(int(addrInfo.Port))
+	var keys []listenerKey
+	listeners := make(map[listenerKey]interface{})
+
+	for _, address := range n.Addresses() {
+		addrInfo, err := ParseAddress(address)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		key := listenerKey{protocol: addrInfo.Protocol, port: addrInfo.Port}
+		if _, exists := listeners[key]; exists {
+			continue
+		}
+
+		t, exists := n.transports.Load(addrInfo.Protocol)
+		if !exists {
+			log.Fatal("invalid protocol: " + addrInfo.Protocol)
+		}
+
+		listener, err := t.(transport.Layer).Listen(int(addrInfo.Port))
 		if err != nil {
 			log.Fatal(err)
 		}
-	} else {
-		log.Fatal("invalid protocol: " + addrInfo.Protocol)
+
+		keys = append(keys, key)
+		listeners[key] = listener
 	}
 
 	n.startListening()
 
-	log.Infof("Listening for peers on %s.", n.Address)
+	for _, address := range n.Addresses() {
+		log.Infof("Listening for peers on %s.", address)
+	}
 
 	// handle server shutdowns
 	go func() {
 		select {
 		case <-n.kill:
 			// cause listener.Accept() to stop blocking so it can continue the loop
-			if addrInfo.Protocol == "tcp" || addrInfo.Protocol == "kcp" {
-				listener.(net.Listener).Close()
-			}
-			if addrInfo.Protocol == "udp" {
-				udpConn, _ := listener.(*net.UDPConn)
-				udpConn.Close()
+			for _, listener := range listeners {
+				switch listener := listener.(type) {
+				case net.Listener:
+					listener.Close()
+				case *net.UDPConn:
+					listener.Close()
+				}
 			}
 		}
 	}()
 
 	// Handle new clients.
-	switch addrInfo.Protocol {
-	case "tcp", "kcp":
-		for {
-			if conn, err := listener.(net.Listener).Accept(); err == nil {
-				go n.Accept(conn)
-
-			} else {
-				// if the Shutdown flag is set, no need to continue with the for loop
-				select {
-				case <-n.kill:
-					log.Infof("Shutting down server on %s.", n.Address)
-					return
-				default:
-					log.Error(err)
-				}
+	var wait sync.WaitGroup
+
+	for _, key := range keys {
+		switch key.protocol {
+		case "tcp", "kcp":
+			wait.Add(1)
+
+			go func(key listenerKey, listener net.Listener) {
+				defer wait.Done()
+				n.serve(key.protocol, key.port, listener)
+			}(key, listeners[key].(net.Listener))
+		case "udp":
+			go n.AcceptUdp(listeners[key].(*net.UDPConn))
+		default:
+			log.Fatal("invalid protocol: " + key.protocol)
+		}
+	}
+
+	wait.Wait()
+}
+
+// serve accepts connections from a stream listener until the network shuts down.
+func (n *Network) serve(protocol string, port uint16, listener net.Listener) {
+	for {
+		if conn, err := listener.Accept(); err == nil {
+			go n.Accept(conn)
+
+		} else {
+			// if the Shutdown flag is set, no need to continue with the for loop
+			select {
+			case <-n.kill:
+				log.Infof("Shutting down %s server on port %d.", protocol, port)
+				return
+			default:
+				log.Error(err)
 			}
 		}
-	case "udp":
-		go n.AcceptUdp(listener.(*net.UDPConn))
-	default:
-		log.Fatal("invalid protocol: " + addrInfo.Protocol)
 	}
+}
 
+// Addresses returns all addresses this node listens on, starting with Address.
+func (n *Network) Addresses() []string {
+	return append([]string{n.Address}, n.extraAddresses...)
+}
+
+// AdvertisedAddresses returns the addresses announced to peers: the ones this
+// node listens on, followed by the ones added with Advertise.
+func (n *Network) AdvertisedAddresses() []string {
+	n.advertisedMutex.RLock()
+	defer n.advertisedMutex.RUnlock()
+
+	return append(n.Addresses(), n.advertisedAddresses...)
+}
+
+// Advertise adds an address this node is reachable on without listening on
+// it, such as a public address behind NAT, and announces it to all connected
+// peers. Returns false if the address is already known.
+func (n *Network) Advertise(address string) (bool, error) {
+	address, err := ToUnifiedAddressWithFamily(address, n.opts.preferredIPFamily)
+	if err != nil {
+		return false, err
+	}
+
+	n.advertisedMutex.Lock()
+	for _, own := range append(n.Addresses(), n.advertisedAddresses...) {
+		if address == own {
+			n.advertisedMutex.Unlock()
+			return false, nil
+		}
+	}
+	n.advertisedAddresses = append(n.advertisedAddresses, address)
+	n.advertisedMutex.Unlock()
+
+	n.EachPeer(func(client *PeerClient) bool {
+		n.announceAddresses(client)
+		return true
+	})
+
+	return true, nil
+}
+
+// isOwnAddress returns true if the address is one this node listens on or
+// advertises.
+func (n *Network) isOwnAddress(address string) bool {
+	for _, own := range n.AdvertisedAddresses() {
+		if address == own {
+			return true
+		}
+	}
+	return false
+}
+
+// listenAddress returns the first address this node listens on for a protocol.
+func (n *Network) listenAddress(protocol string) (string, bool) {
+	for _, address := range n.Addresses() {
+		if addrInfo, err := ParseAddress(address); err == nil && addrInfo.Protocol == protocol {
+			return address, true
+		}
+	}
+	return "", false
 }
 
 // getOrSetPeerClient either returns a cached peer client or creates a new one given a net.Conn
 // or dials the client if no net.Conn is provided.
 func (n *Network) getOrSetPeerClient(address string, conn interface{}) (*PeerClient, error) {
-	address, err := ToUnifiedAddress(address)
+	dialAddress := address
+
+	address, err := ToUnifiedAddressWithFamily(address, n.opts.preferredIPFamily)
 	if err != nil {
 		return nil, err
 	}
 
-	if address == n.Address {
+	if n.isOwnAddress(address) {
 		return nil, errors.New("network: peer should not dial itself")
 	}
 
@@ -376,34 +671,48 @@ func (n *Network) getOrSetPeerClient(address string, conn interface{}) (*PeerCli
 	}()
 	isDial := false
 	if conn == nil {
-		conn, err = n.Dial(address)
+		conn, err = n.dial(dialAddress)
 		isDial = true
 		if err != nil {
 			n.peers.Delete(address)
 			return nil, err
 		}
 	}
-	if addrInfo.Protocol == "tcp" || addrInfo.Protocol == "kcp" {
-		netConn, _ := conn.(net.Conn)
+	// The transport of a connection accepted from a peer may differ from the
+	// protocol of the address the peer is known by.
+	switch c := conn.(type) {
+	case *net.UDPConn:
 		n.connections.Store(address, &ConnState{
 			conn:        conn,
-			writer:      bufio.NewWriterSize(netConn, n.opts.writeBufferSize),
+			writer:      bufio.NewWriterSize(c, n.opts.writeBufferSize),
 			writerMutex: new(sync.Mutex),
+			IsDial:      isDial,
 		})
-	}
-	if addrInfo.Protocol == "udp" {
-		udpConn, _ := conn.(*net.UDPConn)
+		n.udpDialAddrs.Store(address, c.LocalAddr().String())
+	case net.Conn:
 		n.connections.Store(address, &ConnState{
 			conn:        conn,
-			writer:      bufio.NewWriterSize(udpConn, n.opts.writeBufferSize),
+			writer:      bufio.NewWriterSize(c, n.opts.writeBufferSize),
 			writerMutex: new(sync.Mutex),
-			IsDial:      isDial,
 		})
-		n.udpDialAddrs.Store(address, udpConn.LocalAddr().String())
 	}
+
+	// use the dialed connection for also receiving messages
+	if isDial {
+		n.receive(conn, client)
+	}
+	// Advertise what this node supports before Components get to send
+	// anything to the peer.
+	n.sendCapabilities(client)
+
 	client.Init()
 
 	client.setIncomingReady()
+
+	// Announce before anything else is written, as the receive window of the
+	// peer starts at the nonce of the first message it receives.
+	n.announceAddresses(client)
+
 	return client, nil
 }
 
@@ -441,9 +750,20 @@ func (n *Network) BlockUntilListening() {
 func (n *Network) Bootstrap(addresses ...string) {
 	n.BlockUntilListening()
 
-	addresses = FilterPeers(n.Address, addresses)
+	visited := make(map[string]struct{})
 
 	for _, address := range addresses {
+		// Host names are passed on unresolved, so that all of their
+		// addresses are tried when dialing.
+		unified, err := ToUnifiedAddressWithFamily(address, n.opts.preferredIPFamily)
+		if err != nil {
+			continue
+		}
+		if _, seen := visited[unified]; seen || n.isOwnAddress(unified) {
+			continue
+		}
+		visited[unified] = struct{}{}
+
 		client, err := n.Client(address)
 
 		if err != nil {
@@ -460,48 +780,37 @@ func (n *Network) Bootstrap(addresses ...string) {
 
 // Dial establishes a bidirectional connection to an address, and additionally handshakes with said address.
 func (n *Network) Dial(address string) (interface{}, error) {
-	addrInfo, err := ParseAddress(address)
+	conn, err := n.dial(address)
 	if err != nil {
 		return nil, err
 	}
 
-	if addrInfo.Host != "127.0.0.1" {
-		host, err := ParseAddress(n.Address)
-		if err != nil {
-			return nil, err
-		}
-		// check if dialing address is same as its own IP
-		if addrInfo.Host == host.Host {
-			addrInfo.Host = "127.0.0.1"
-		}
-	}
-
-	// Choose scheme.
-	t, exists := n.transports.Load(addrInfo.Protocol)
-	if !exists {
-		log.Fatal("invalid protocol: " + addrInfo.Protocol)
-	}
+	// use the connection for also receiving messages
+	n.receive(conn, nil)
 
-	var conn interface{}
-	conn, err = t.(transport.Layer).Dial(addrInfo.HostPort())
-	if err != nil {
-		return nil, err
-	}
+	return conn, nil
+}
 
-	// use the connection for also receiving messages
-	if addrInfo.Protocol == "udp" {
+// receive processes messages arriving over a connection on a new goroutine.
+func (n *Network) receive(conn interface{}, client *PeerClient) {
+	switch conn.(type) {
+	case *net.UDPConn:
 		go n.AcceptUdp(conn)
+	case net.Conn:
+		go n.accept(conn, client)
 	}
-	if addrInfo.Protocol == "tcp" || addrInfo.Protocol == "kcp" {
-		go n.Accept(conn)
-	}
-
-	return conn, nil
 }
 
 // Accept handles peer registration and processes incoming message streams.
 func (n *Network) Accept(incoming interface{}) {
-	var client *PeerClient
+	n.accept(incoming, nil)
+}
+
+// accept processes the message stream of a connection. client is the peer
+// client a dialed connection belongs to, or nil for connections accepted from
+// a listener, whose client is looked up by the sender of the first message.
+// The sender may be known by another address than the one it was dialed on.
+func (n *Network) accept(incoming interface{}, client *PeerClient) {
 	var clientInit sync.Once
 
 	recvWindow := NewRecvWindow(n.opts.recvWindowSize)
@@ -519,6 +828,26 @@ func (n *Network) Accept(incoming interface{}) {
 		}
 	}()
 
+	verify, stop := n.verifyInOrder(func(msg *protobuf.Message) {
+		// Peer sent message with a completely different ID. Disconnect.
+		if !n.acceptsSender(client, peer.ID(*msg.Sender)) {
+			log.Errorf("message signed by peer %s but client is %s", peer.ID(*msg.Sender), client.ID().Address)
+			return
+		}
+		client.setIdentified()
+
+		recvWindow.Push(msg.MessageNonce, msg)
+
+		ready := recvWindow.Pop()
+		for _, msg := range ready {
+			msg := msg
+			client.Submit(func() {
+				n.dispatchMessage(client, msg.(*protobuf.Message))
+			})
+		}
+	})
+	defer stop()
+
 	for {
 		msg, err := n.receiveMessage(incoming)
 		if err != nil {
@@ -530,14 +859,16 @@ func (n *Network) Accept(incoming interface{}) {
 
 		// Initialize client if not exists.
 		clientInit.Do(func() {
-			client, err = n.getOrSetPeerClient(msg.Sender.Address, incoming)
-			if err != nil {
-				return
+			if client == nil {
+				client, err = n.getOrSetPeerClient(msg.Sender.Address, incoming)
+				if err != nil {
+					return
+				}
 			}
 
-			client.ID = (*peer.ID)(msg.Sender)
+			client.setID(peer.ID(*msg.Sender))
 
-			if !n.ConnectionStateExists(client.ID.Address) {
+			if !n.ConnectionStateExists(client.Address) {
 				err = errors.New("network: failed to load session")
 			}
 
@@ -549,33 +880,7 @@ func (n *Network) Accept(incoming interface{}) {
 			return
 		}
 
-		go func() {
-			if msg.Signature != nil && !crypto.Verify(
-				n.opts.signaturePolicy,
-				n.opts.hashPolicy,
-				msg.Sender.NetKey,
-				SerializeMessage(msg.Sender, msg.Message),
-				msg.Signature,
-			) {
-				log.Errorf("received message had an malformed signature")
-				return
-			}
-			// Peer sent message with a completely different ID. Disconnect.
-			if !client.ID.Equals(peer.ID(*msg.Sender)) {
-				log.Errorf("message signed by peer %s but client is %s", peer.ID(*msg.Sender), client.ID.Address)
-				return
-			}
-
-			recvWindow.Push(msg.MessageNonce, msg)
-
-			ready := recvWindow.Pop()
-			for _, msg := range ready {
-				msg := msg
-				client.Submit(func() {
-					n.dispatchMessage(client, msg.(*protobuf.Message))
-				})
-			}
-		}()
+		verify(msg)
 	}
 }
 
@@ -605,14 +910,8 @@ func (n *Network) AcceptUdp(incoming interface{}) {
 			}
 			break
 		}
-		go func() {
-			if msg.Signature != nil && !crypto.Verify(
-				n.opts.signaturePolicy,
-				n.opts.hashPolicy,
-				msg.Sender.NetKey,
-				SerializeMessage(msg.Sender, msg.Message),
-				msg.Signature,
-			) {
+		n.verifyMessage(msg, func(ok bool) {
+			if !ok {
 				log.Error("received message had an malformed signature")
 				return
 			}
@@ -623,7 +922,7 @@ func (n *Network) AcceptUdp(incoming interface{}) {
 				return
 			}
 
-			client.ID = (*peer.ID)(msg.Sender)
+			client.setID(peer.ID(*msg.Sender))
 
 			if !n.ConnectionStateExists(msg.DialAddress) {
 				log.Error(errors.New("network: failed to load session"))
@@ -631,14 +930,16 @@ func (n *Network) AcceptUdp(incoming interface{}) {
 			}
 
 			// Peer sent message with a completely different ID. Disconnect.
-			if !client.ID.Equals(peer.ID(*msg.Sender)) {
-				log.Errorf("message signed by peer %s but client is %s", peer.ID(*msg.Sender), client.ID.Address)
+			if !client.ID().Equals(peer.ID(*msg.Sender)) {
+				log.Errorf("message signed by peer %s but client is %s", peer.ID(*msg.Sender), client.ID().Address)
 				return
 			}
+			client.setIdentified()
+
 			client.Submit(func() {
 				n.dispatchMessage(client, msg)
 			})
-		}()
+		})
 	}
 }
 
@@ -650,33 +951,187 @@ func (n *Network) Component(key interface{}) (ComponentInterface, bool) {
 	return n.Components.Get(key)
 }
 
-// PrepareMessage marshals a message into a *protobuf.Message and signs it with this
+// AddComponent registers a new Component onto a live network, after all
+// Components registered so far.
+func (n *Network) AddComponent(Component ComponentInterface) error {
+	return n.AddComponentWithPriority(n.Components.nextPriority(), Component)
+}
+
+// AddComponentWithPriority registers a new Component onto a live network with
+// a set priority. The Component is started if the network is listening, and
+// is then told about every connected peer. Protocols the Component speaks are
+// advertised to all peers. The Components it depends on must be registered
+// already.
+//
+// Must not be called from a Startup or Cleanup callback.
+func (n *Network) AddComponentWithPriority(priority int, Component ComponentInterface) error {
+	n.lifecycleMutex.Lock()
+	defer n.lifecycleMutex.Unlock()
+
+	if _, exists := n.Components.GetInfo(Component); exists {
+		return errors.Errorf(ErrStrDuplicateComponent, reflect.TypeOf(Component).String())
+	}
+
+	if provider, ok := Component.(DependencyProvider); ok {
+		for _, key := range provider.Dependencies() {
+			if _, optional := key.(optionalDependency); optional {
+				continue
+			}
+			if _, exists := n.Components.GetInfo(key); !exists {
+				return errors.Errorf(ErrStrMissingDependency, reflect.TypeOf(Component), reflect.TypeOf(key))
+			}
+		}
+	}
+
+	protocols, err := newProtocolSet(n.Components)
+	if err == nil {
+		err = protocols.addComponent(Component)
+	}
+	if err != nil {
+		return err
+	}
+
+	info := &ComponentInfo{Priority: priority, Component: Component}
+
+	if n.started {
+		n.safely(info, nil, func() {
+			Component.Startup(n)
+		})
+	}
+
+	if err := n.routes.register(info); err != nil {
+		if n.started {
+			n.safely(info, nil, func() {
+				Component.Cleanup(n)
+			})
+		}
+		return err
+	}
+
+	if !n.Components.insert(info) {
+		n.routes.remove(info)
+		return errors.Errorf(ErrStrDuplicateComponent, reflect.TypeOf(Component).String())
+	}
+	n.protocols.Store(protocols)
+
+	n.EachPeer(func(client *PeerClient) bool {
+		client.connectComponent(info)
+		return true
+	})
+
+	if _, ok := Component.(ProtocolProvider); ok {
+		n.advertiseCapabilities()
+	}
+
+	return nil
+}
+
+// RemoveComponent unregisters a Component from a live network given its
+// Component ID. The Component is told every connected peer disconnected, and
+// is cleaned up if the network is listening. Peers are told the protocols it
+// spoke are not spoken any more. Components other Components depend on can
+// not be removed before them.
+//
+// Must not be called from a Startup or Cleanup callback.
+//
+// Example: network.RemoveComponent((*Component)(nil))
+func (n *Network) RemoveComponent(key interface{}) error {
+	n.lifecycleMutex.Lock()
+	defer n.lifecycleMutex.Unlock()
+
+	var dependent ComponentInterface
+	n.Components.Each(func(Component ComponentInterface) {
+		if provider, ok := Component.(DependencyProvider); ok && dependent == nil {
+			// optional dependencies do not keep a Component from being removed
+			for _, dependency := range provider.Dependencies() {
+				if reflect.TypeOf(dependency) == reflect.TypeOf(key) && reflect.TypeOf(Component) != reflect.TypeOf(key) {
+					dependent = Component
+				}
+			}
+		}
+	})
+	if dependent != nil {
+		return errors.Errorf("network: Component %s is required by Component %s", reflect.TypeOf(key), reflect.TypeOf(dependent))
+	}
+
+	info, ok := n.Components.Remove(key)
+	if !ok {
+		return errors.Errorf("network: Component %s is not registered", reflect.TypeOf(key).String())
+	}
+	n.routes.remove(info)
+
+	protocols, err := newProtocolSet(n.Components)
+	if err != nil {
+		return err
+	}
+	n.protocols.Store(protocols)
+
+	if !info.isRemoved() {
+		n.EachPeer(func(client *PeerClient) bool {
+			client.disconnectComponent(info)
+			return true
+		})
+
+		if n.started {
+			n.safely(info, nil, func() {
+				info.Component.Cleanup(n)
+			})
+		}
+	}
+
+	if _, ok := info.Component.(ProtocolProvider); ok {
+		n.advertiseCapabilities()
+	}
+
+	return nil
+}
+
+// PrepareMessage encodes a message into a *protobuf.Message and signs it with this
 // nodes private key. Errors if the message is null.
-func (n *Network) PrepareMessage(ctx context.Context, message proto.Message) (*protobuf.Message, error) {
+//
+// Messages are encoded with the codec their type is registered with, or if
+// it is protobuf, with the codec set on the context with WithCodec.
+func (n *Network) PrepareMessage(ctx context.Context, message interface{}) (*protobuf.Message, error) {
 	if message == nil {
 		return nil, errors.New("network: message is null")
 	}
 
-	opcode, err := opcode.GetOpcode(message)
+	opcode, err := n.opts.registry.GetOpcode(message)
+	if err != nil {
+		return nil, err
+	}
+
+	codecName := n.opts.registry.Codec(opcode)
+	if codecName == "" {
+		codecName = GetCodec(ctx)
+	}
+	if codecName == codec.ProtoName {
+		codecName = ""
+	}
+
+	c, err := codec.Get(codecName)
 	if err != nil {
 		return nil, err
 	}
 
-	raw, err := proto.Marshal(message)
+	raw, err := c.Marshal(message)
 	if err != nil {
 		return nil, err
 	}
 
-	id := protobuf.ID(n.ID)
+	n.identityMutex.RLock()
+	id, keys := protobuf.ID(n.id), n.keys
+	n.identityMutex.RUnlock()
 
 	msg := &protobuf.Message{
 		Message: raw,
 		Opcode:  uint32(opcode),
 		Sender:  &id,
+		Codec:   codecName,
 	}
 
 	if GetSignMessage(ctx) {
-		signature, err := n.keys.Sign(
+		signature, err := keys.Sign(
 			n.opts.signaturePolicy,
 			n.opts.hashPolicy,
 			SerializeMessage(&id, raw),
@@ -698,22 +1153,16 @@ func (n *Network) Write(address string, message *protobuf.Message) error {
 
 	message.MessageNonce = atomic.AddUint64(&state.messageNonce, 1)
 
-	addrInfo, err := ParseAddress(n.Address)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if addrInfo.Protocol == "tcp" || addrInfo.Protocol == "kcp" {
-		tcpConn, _ := state.conn.(net.Conn)
-		tcpConn.SetWriteDeadline(time.Now().Add(n.opts.writeTimeout))
-		err = n.sendMessage(state.writer, message, state.writerMutex, state)
+	switch conn := state.conn.(type) {
+	case *net.UDPConn:
+		conn.SetWriteDeadline(time.Now().Add(n.opts.writeTimeout))
+		err := n.sendUDPMessage(state.writer, message, state.writerMutex, state, address)
 		if err != nil {
 			return err
 		}
-	}
-	if addrInfo.Protocol == "udp" {
-		udpConn, _ := state.conn.(*net.UDPConn)
-		udpConn.SetWriteDeadline(time.Now().Add(n.opts.writeTimeout))
-		err = n.sendUDPMessage(state.writer, message, state.writerMutex, state, address)
+	case net.Conn:
+		conn.SetWriteDeadline(time.Now().Add(n.opts.writeTimeout))
+		err := n.sendMessage(state.writer, message, state.writerMutex, state)
 		if err != nil {
 			return err
 		}
@@ -730,49 +1179,60 @@ func (n *Network) Write(address string, message *protobuf.Message) error {
 }
 
 // Broadcast asynchronously broadcasts a message to all peer clients.
-func (n *Network) Broadcast(ctx context.Context, message proto.Message) {
-	signed, err := n.PrepareMessage(ctx, message)
-	if err != nil {
-		log.Errorf("network: failed to broadcast message")
-		return
-	}
-
+func (n *Network) Broadcast(ctx context.Context, message interface{}) {
+	var addresses []string
 	n.EachPeer(func(client *PeerClient) bool {
-		err := n.Write(client.Address, signed)
-		if err != nil {
-			log.Warnf("failed to send message to peer %v [err=%s]", client.ID, err)
-		}
+		addresses = append(addresses, client.Address)
 		return true
 	})
-}
 
-// BroadcastByAddresses broadcasts a message to a set of peer clients denoted by their addresses.
-func (n *Network) BroadcastByAddresses(ctx context.Context, message proto.Message, addresses ...string) {
-	signed, err := n.PrepareMessage(ctx, message)
-	if err != nil {
-		return
-	}
+	n.broadcast(ctx, message, addresses)
+}
 
-	for _, address := range addresses {
-		n.Write(address, signed)
-	}
+// BroadcastByAddresses broadcasts a message to a set of peer clients denoted by their addresses,
+// dialing those which are not connected yet.
+func (n *Network) BroadcastByAddresses(ctx context.Context, message interface{}, addresses ...string) {
+	n.broadcast(ctx, message, addresses)
 }
 
-// BroadcastByIDs broadcasts a message to a set of peer clients denoted by their peer IDs.
-func (n *Network) BroadcastByIDs(ctx context.Context, message proto.Message, ids ...peer.ID) {
-	signed, err := n.PrepareMessage(ctx, message)
-	if err != nil {
-		return
+// BroadcastByIDs broadcasts a message to a set of peer clients denoted by their
+// peer IDs. Connected peers are written to right away, while peers which are
+// not connected are dialed in the background with ClientByID, without blocking
+// the broadcast.
+func (n *Network) BroadcastByIDs(ctx context.Context, message interface{}, ids ...peer.ID) {
+	pending := make(map[string]peer.ID, len(ids))
+	for _, id := range ids {
+		pending[id.PublicKeyHex()] = id
 	}
 
-	for _, id := range ids {
-		n.Write(id.Address, signed)
+	var addresses []string
+	n.EachPeer(func(client *PeerClient) bool {
+		if id := client.ID(); id != nil {
+			if _, ok := pending[id.PublicKeyHex()]; ok {
+				addresses = append(addresses, client.Address)
+				delete(pending, id.PublicKeyHex())
+			}
+		}
+		return true
+	})
+
+	n.broadcast(ctx, message, addresses)
+
+	for _, id := range pending {
+		go func(id peer.ID) {
+			client, err := n.ClientByID(id)
+			if err != nil {
+				log.Warnf("failed to send message to peer %v [err=%s]", id, err)
+				return
+			}
+			n.broadcast(ctx, message, []string{client.Address})
+		}(id)
 	}
 }
 
 // BroadcastRandomly asynchronously broadcasts a message to random selected K peers.
 // Does not guarantee broadcasting to exactly K peers.
-func (n *Network) BroadcastRandomly(ctx context.Context, message proto.Message, K int) {
+func (n *Network) BroadcastRandomly(ctx context.Context, message interface{}, K int) {
 	var addresses []string
 
 	n.EachPeer(func(client *PeerClient) bool {
@@ -6,8 +6,6 @@ import (
 	"github.com/cocher/crypto"
 	"github.com/cocher/internal/protobuf"
 	"github.com/cocher/peer"
-
-	"github.com/gogo/protobuf/proto"
 )
 
 // NetworkInterface represents a node in the network.
@@ -25,6 +23,9 @@ type NetworkInterface interface {
 	// Client either creates or returns a cached peer client given its host address.
 	Client(address string) (*PeerClient, error)
 
+	// ClientByID returns a client for a peer, dialing its known addresses in order if not connected.
+	ClientByID(id peer.ID) (*PeerClient, error)
+
 	// BlockUntilListening blocks until this node is listening for new peers.
 	BlockUntilListening()
 
@@ -43,25 +44,25 @@ type NetworkInterface interface {
 	// Example: network.Component((*Component)(nil))
 	Component(key interface{}) (ComponentInterface, bool)
 
-	// PrepareMessage marshals a message into a *protobuf.Message and signs it with this
+	// PrepareMessage encodes a message into a *protobuf.Message and signs it with this
 	// nodes private key. Errors if the message is null.
-	PrepareMessage(ctx context.Context, message proto.Message) (*protobuf.Message, error)
+	PrepareMessage(ctx context.Context, message interface{}) (*protobuf.Message, error)
 
 	// Write asynchronously sends a message to a denoted target address.
 	Write(address string, message *protobuf.Message) error
 
 	// Broadcast asynchronously broadcasts a message to all peer clients.
-	Broadcast(ctx context.Context, message proto.Message)
+	Broadcast(ctx context.Context, message interface{})
 
 	// BroadcastByAddresses broadcasts a message to a set of peer clients denoted by their addresses.
-	BroadcastByAddresses(ctx context.Context, message proto.Message, addresses ...string)
+	BroadcastByAddresses(ctx context.Context, message interface{}, addresses ...string)
 
 	// BroadcastByIDs broadcasts a message to a set of peer clients denoted by their peer IDs.
-	BroadcastByIDs(ctx context.Context, message proto.Message, ids ...peer.ID)
+	BroadcastByIDs(ctx context.Context, message interface{}, ids ...peer.ID)
 
 	// BroadcastRandomly asynchronously broadcasts a message to random selected K peers.
 	// Does not guarantee broadcasting to exactly K peers.
-	BroadcastRandomly(ctx context.Context, message proto.Message, K int)
+	BroadcastRandomly(ctx context.Context, message interface{}, K int)
 
 	// Close shuts down the entire network.
 	Close()
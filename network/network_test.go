@@ -2,13 +2,20 @@ package network_test
 
 import (
 	"context"
+	"io"
+	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	pb "github.com/cocher/internal/protobuf"
 	"github.com/cocher/internal/test/protobuf"
 	"github.com/cocher/network"
+	"github.com/cocher/network/codec"
+	"github.com/cocher/peer"
 	"github.com/cocher/types/opcode"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -102,6 +109,71 @@ func testNodeBroadcastByIDs(t *testing.T, e env, numNodes, numPeers int) {
 	}
 }
 
+func TestBroadcastByIDsDoesNotBlock(t *testing.T) {
+	te := newTest(t, tcpEnv, network.ConnectionTimeout(2*time.Second))
+	te.startBoostrap(2)
+	defer te.tearDown()
+
+	// A peer which accepts connections but never identifies itself.
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	silent := peer.CreateID("tcp://"+listener.Addr().String(), tcpEnv.signature.RandomKeyPair().PublicKey)
+	node := te.nodes[0]
+
+	start := time.Now()
+	te.bootstrapNode.BroadcastByIDs(context.Background(), &protobuf.TestMessage{Message: "test message"}, silent, node.GetID())
+	assert.True(t, time.Since(start) < time.Second, "expected the broadcast not to wait for the silent peer")
+
+	select {
+	case msg := <-te.getMailbox(node).RecvMailbox:
+		assert.Equal(t, "test message", msg.Message)
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the connected peer to receive the broadcast")
+	}
+}
+
+func TestSendMailboxDoesNotBlock(t *testing.T) {
+	te := newTest(t, tcpEnv)
+	te.startBoostrap(2)
+	defer te.tearDown()
+
+	node := te.nodes[0]
+	client, err := te.bootstrapNode.Client(node.Address)
+	assert.Nil(t, err)
+
+	// Sends are not held up by a SendMailbox nobody drains.
+	sent := cap(te.getMailbox(te.bootstrapNode).SendMailbox) * 2
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < sent; i++ {
+			assert.Nil(t, client.Tell(context.Background(), &protobuf.TestMessage{Message: "test message"}))
+		}
+	}()
+
+	for i := 0; i < sent; i++ {
+		select {
+		case <-te.getMailbox(node).RecvMailbox:
+		case <-time.After(3 * time.Second):
+			t.Fatalf("expected %d messages to be received, got %d", sent, i)
+		}
+	}
+	<-done
+}
+
 func TestNodeBroadcastByAddresses(t *testing.T) {
 	if testing.Short() {
 		t.Skipf("skipping %s in short mode", t.Name())
@@ -184,3 +256,563 @@ func testClientRequest(t *testing.T, e env, numNodes int) {
 	}(ctx)
 	cancel()
 }
+
+func TestRequestError(t *testing.T) {
+	te := newTest(t, tcpEnv, network.WriteTimeout(1*time.Second))
+	te.startBoostrap(2, new(clientTestComponent))
+	defer te.tearDown()
+
+	address := te.nodes[0].Address
+	client, err := te.bootstrapNode.Client(address)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Errors returned handling a request are replied instead of timing out.
+	response, err := client.Request(ctx, &protobuf.TestMessage{Message: "not found"})
+	assert.Nil(t, response)
+	remote, ok := network.AsRemoteError(err)
+	if assert.True(t, ok, "expected a remote error, got %v", err) {
+		assert.Equal(t, &network.RemoteError{
+			Address: address,
+			Code:    network.ErrorCodeNotFound,
+			Message: "no such message",
+			Details: map[string]string{"message": "not found"},
+		}, remote)
+	}
+
+	// Errors which are no remote error are replied with an unknown code, and
+	// without their text.
+	_, err = client.Request(ctx, &protobuf.TestMessage{Message: "fail"})
+	remote, ok = network.AsRemoteError(err)
+	if assert.True(t, ok, "expected a remote error, got %v", err) {
+		assert.Equal(t, network.ErrorCodeUnknown, remote.Code)
+		assert.Equal(t, "network: peer "+address+" replied with error 0: request failed", remote.Error())
+	}
+
+	// Requests handled without errors are replied to as before.
+	response, err = client.Request(ctx, &protobuf.TestMessage{Message: "ok"})
+	assert.Nil(t, err)
+	assert.Equal(t, &protobuf.TestMessage{Message: "ok"}, response)
+}
+
+func TestRequestStream(t *testing.T) {
+	component := &streamTestComponent{errs: make(chan error, 1)}
+
+	te := newTest(t, tcpEnv, network.WriteTimeout(1*time.Second))
+	te.startBoostrap(2, component)
+	defer te.tearDown()
+
+	client, err := te.bootstrapNode.Client(te.nodes[0].Address)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	recvAll := func(stream *network.ReplyStream) ([]string, error) {
+		var replies []string
+		for {
+			reply, err := stream.Recv()
+			if err != nil {
+				return replies, err
+			}
+			replies = append(replies, reply.(*protobuf.TestMessage).Message)
+		}
+	}
+
+	// Replies are received until the responder ends the stream.
+	stream, err := client.RequestStream(network.WithStreamWindow(ctx, 2), &protobuf.TestMessage{Message: "count", Duration: 5})
+	assert.Nil(t, err)
+	replies, err := recvAll(stream)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, replies)
+
+	// Streams handlers do not take over end after their reply.
+	stream, err = client.RequestStream(ctx, &protobuf.TestMessage{Message: "once"})
+	assert.Nil(t, err)
+	replies, err = recvAll(stream)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"once"}, replies)
+
+	// Errors returned by handlers end the stream.
+	stream, err = client.RequestStream(ctx, &protobuf.TestMessage{Message: "fail"})
+	assert.Nil(t, err)
+	replies, err = recvAll(stream)
+	assert.Equal(t, []string{"0"}, replies)
+	remote, ok := network.AsRemoteError(err)
+	if assert.True(t, ok, "expected a remote error, got %v", err) {
+		assert.Equal(t, network.ErrorCodeUnknown, remote.Code)
+		assert.Equal(t, "request failed", remote.Message)
+	}
+
+	// The responder sends no more replies than the requester has room for.
+	atomic.StoreUint32(&component.sent, 0)
+	stream, err = client.RequestStream(network.WithStreamWindow(ctx, 2), &protobuf.TestMessage{Message: "forever"})
+	assert.Nil(t, err)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, uint32(2), atomic.LoadUint32(&component.sent))
+
+	_, err = stream.Recv()
+	assert.Nil(t, err)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, uint32(3), atomic.LoadUint32(&component.sent))
+
+	// Closing the stream cancels it on the responder's side.
+	stream.Close()
+	select {
+	case err := <-component.errs:
+		assert.Equal(t, network.ErrStreamCanceled, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the responder to be canceled")
+	}
+
+	_, err = stream.Recv()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestRequestCancel(t *testing.T) {
+	component := &cancelTestComponent{deadlines: make(chan time.Time, 1), errs: make(chan error, 1)}
+
+	te := newTest(t, tcpEnv, network.WriteTimeout(1*time.Second))
+	te.startBoostrap(2, component)
+	defer te.tearDown()
+
+	client, err := te.bootstrapNode.Client(te.nodes[0].Address)
+	assert.Nil(t, err)
+
+	// Handlers are given the deadline of the request.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	_, err = client.Request(ctx, &protobuf.TestMessage{Message: "wait"})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.WithinDuration(t, deadline, <-component.deadlines, 100*time.Millisecond)
+	assert.NotNil(t, <-component.errs)
+
+	// Handlers stop once the requester canceled the request.
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	_, err = client.Request(ctx, &protobuf.TestMessage{Message: "wait"})
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, (<-component.deadlines).IsZero())
+	assert.Equal(t, context.Canceled, <-component.errs)
+}
+
+func TestSignedRequest(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipping %s in short mode", t.Name())
+	}
+
+	for _, e := range []env{tcpEnv, tcpSecp256k1Env, tcpSMEnv} {
+		testSignedRequest(t, e)
+	}
+}
+
+func testSignedRequest(t *testing.T, e env) {
+	te := newTest(t, e, network.WriteTimeout(1*time.Second))
+	te.startBoostrap(2, new(clientTestComponent))
+	defer te.tearDown()
+
+	client, err := te.bootstrapNode.Client(te.nodes[0].Address)
+	assert.Equal(t, nil, err, "expected client error to be nil")
+
+	ctx, cancel := context.WithTimeout(network.WithSignMessage(context.Background(), true), 3*time.Second)
+	defer cancel()
+
+	msgStr := "signed test message"
+	response, err := client.Request(ctx, &protobuf.TestMessage{Message: msgStr})
+	assert.Equalf(t, nil, err, "[%s] expected signed request to succeed", e.name)
+
+	resp, ok := response.(*protobuf.TestMessage)
+	assert.Equalf(t, true, ok, "[%s] expected response to be cast successfully", e.name)
+	if ok {
+		assert.Equalf(t, msgStr, resp.Message, "[%s] expected reply message to be '%s', got '%s'", e.name, msgStr, resp.Message)
+	}
+}
+
+func TestCodecRequest(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipping %s in short mode", t.Name())
+	}
+
+	for _, e := range allEnvs {
+		testCodecRequest(t, e)
+	}
+}
+
+func testCodecRequest(t *testing.T, e env) {
+	te := newTest(t, e, network.WriteTimeout(1*time.Second))
+	te.startBoostrap(2, new(clientTestComponent))
+	defer te.tearDown()
+
+	client, err := te.bootstrapNode.Client(te.nodes[0].Address)
+	assert.Equal(t, nil, err, "expected client error to be nil")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Types registered with a codec are encoded with it.
+	response, err := client.Request(ctx, &jsonTestMessage{Message: "json", Tags: []string{"request"}})
+	assert.Equalf(t, nil, err, "[%s] expected json request to succeed", e.name)
+	assert.Equalf(t, &jsonTestMessage{Message: "json", Tags: []string{"request", "reply"}}, response, "[%s] unexpected json response", e.name)
+
+	// Protobuf messages are encoded with the codec set on the connection.
+	assert.Equal(t, nil, client.SetCodec(codec.JSONName))
+	response, err = client.Request(ctx, &protobuf.TestMessage{Message: "proto as json"})
+	assert.Equalf(t, nil, err, "[%s] expected request encoded as json to succeed", e.name)
+	if resp, ok := response.(*protobuf.TestMessage); assert.Equalf(t, true, ok, "[%s] expected response to be cast successfully", e.name) {
+		assert.Equal(t, "proto as json", resp.Message)
+	}
+
+	assert.NotEqual(t, nil, client.SetCodec("unknown"), "expected unknown codecs to be rejected")
+	assert.Equal(t, nil, client.SetCodec(""))
+}
+
+func TestOutboundHooks(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipping %s in short mode", t.Name())
+	}
+
+	for _, e := range allEnvs {
+		testOutboundHooks(t, e)
+	}
+}
+
+func testOutboundHooks(t *testing.T, e env) {
+	te := newTest(t, e, network.WriteTimeout(1*time.Second))
+	hooks := new(outboundTestComponent)
+	te.startBoostrap(2, new(clientTestComponent), hooks)
+	defer te.tearDown()
+
+	// Nodes the bootstrap node is not connected to yet to reroute to.
+	start := func(components ...network.ComponentInterface) *network.Network {
+		builder := network.NewBuilderWithOptions(te.builderOptions...)
+		builder.SetKeys(e.signature.RandomKeyPair())
+		builder.SetAddress(network.FormatAddress(e.networkType, "localhost", uint16(network.GetRandomUnusedPort())))
+		builder.AddComponent(new(MailBoxComponent))
+		for _, component := range components {
+			builder.AddComponent(component)
+		}
+
+		node, err := builder.Build()
+		assert.Nil(t, err)
+		go node.Listen()
+		node.BlockUntilListening()
+		return node
+	}
+
+	second, third := start(new(clientTestComponent)), start()
+	defer second.Close()
+	defer third.Close()
+
+	first := te.nodes[0]
+	hooks.reroute = second.Address
+
+	client, err := te.bootstrapNode.Client(first.Address)
+	assert.Equal(t, nil, err, "expected client error to be nil")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Hooks run in order of priority, the mailbox seeing the message before
+	// it is tagged.
+	response, err := client.Request(ctx, &protobuf.TestMessage{Message: "hello"})
+	assert.Equalf(t, nil, err, "[%s] expected request to succeed", e.name)
+	assert.Equalf(t, &protobuf.TestMessage{Message: "hello [tagged]"}, response, "[%s] expected the request to be tagged", e.name)
+	assert.Equal(t, "hello", (<-te.getMailbox(te.bootstrapNode).SendMailbox).Message)
+	assert.Equal(t, "hello [tagged]", (<-te.getMailbox(first).RecvMailbox).Message)
+
+	// Requests await the reply of the peer they are rerouted to.
+	response, err = client.Request(ctx, &protobuf.TestMessage{Message: "reroute"})
+	assert.Equalf(t, nil, err, "[%s] expected rerouted request to succeed", e.name)
+	assert.Equalf(t, &protobuf.TestMessage{Message: "reroute [tagged]"}, response, "[%s] unexpected response", e.name)
+	assert.Equal(t, "reroute [tagged]", (<-te.getMailbox(second).RecvMailbox).Message)
+
+	// Broadcasts are rerouted to peers which are dialed too.
+	hooks.reroute = third.Address
+	te.bootstrapNode.Broadcast(ctx, &protobuf.TestMessage{Message: "reroute"})
+	assert.Equal(t, "reroute [tagged]", (<-te.getMailbox(third).RecvMailbox).Message)
+
+	// Vetoed messages are not sent.
+	err = client.Tell(ctx, &protobuf.TestMessage{Message: "veto"})
+	assert.Equal(t, "vetoed by test", errors.Cause(err).Error())
+	te.bootstrapNode.Broadcast(ctx, &protobuf.TestMessage{Message: "veto"})
+
+	assert.Equal(t, nil, client.Tell(ctx, &protobuf.TestMessage{Message: "drop"}))
+	_, err = client.Request(ctx, &protobuf.TestMessage{Message: "drop"})
+	assert.NotEqual(t, nil, err, "expected requests sent to no peer to fail")
+
+	time.Sleep(50 * time.Millisecond)
+	for _, node := range []*network.Network{first, second, third} {
+		assert.Equalf(t, 0, len(te.getMailbox(node).RecvMailbox), "[%s] expected no further messages", e.name)
+	}
+}
+
+func TestRotateKeys(t *testing.T) {
+	for _, e := range []env{tcpEnv, tcpSecp256k1Env} {
+		testRotateKeys(t, e)
+	}
+}
+
+func testRotateKeys(t *testing.T, e env) {
+	te := newTest(t, e, network.WriteTimeout(1*time.Second))
+	te.startBoostrap(2, new(clientTestComponent))
+	defer te.tearDown()
+
+	node := te.nodes[0]
+	previous := node.GetID()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	keys := e.signature.RandomKeyPair()
+	err := node.RotateKeys(ctx, keys)
+	assert.Equalf(t, nil, err, "[%s] expected key rotation to succeed", e.name)
+	assert.Equalf(t, keys.PublicKey, node.GetID().NetKey, "[%s] expected node to use the new key", e.name)
+	assert.Equalf(t, keys, node.GetKeys(), "[%s] expected node to use the new key pair", e.name)
+
+	// The connection of the bootstrap node to the rotated node is updated in place.
+	client, err := te.bootstrapNode.Client(node.Address)
+	assert.Equalf(t, nil, err, "[%s] expected client error to be nil", e.name)
+	assert.Truef(t, client.ID().Equals(node.GetID()), "[%s] expected client ID to be the successor", e.name)
+
+	successor, ok := te.bootstrapNode.Successor(previous)
+	assert.Truef(t, ok && successor.Equals(node.GetID()), "[%s] expected successor of previous ID to be known", e.name)
+
+	// Both routing tables follow the rotation.
+	routes := te.getRoutes(te.bootstrapNode)
+	assert.Truef(t, routes.PeerExists(node.GetID()), "[%s] expected successor in routing table", e.name)
+	assert.Falsef(t, routes.PeerExists(previous), "[%s] expected previous ID to be replaced", e.name)
+	assert.Truef(t, te.getRoutes(node).Self().Equals(node.GetID()), "[%s] expected routing table of rotated node to use its successor", e.name)
+
+	// Messages signed with the new key are accepted.
+	client, err = node.Client(te.bootstrapNode.Address)
+	assert.Equalf(t, nil, err, "[%s] expected client error to be nil", e.name)
+
+	signedCtx, signedCancel := context.WithTimeout(network.WithSignMessage(context.Background(), true), 3*time.Second)
+	defer signedCancel()
+
+	msgStr := "rotated test message"
+	response, err := client.Request(signedCtx, &protobuf.TestMessage{Message: msgStr})
+	assert.Equalf(t, nil, err, "[%s] expected signed request with the new key to succeed", e.name)
+
+	resp, ok := response.(*protobuf.TestMessage)
+	assert.Equalf(t, true, ok, "[%s] expected response to be cast successfully", e.name)
+	if ok {
+		assert.Equalf(t, msgStr, resp.Message, "[%s] expected reply message to be '%s', got '%s'", e.name, msgStr, resp.Message)
+	}
+}
+
+func TestClientByID(t *testing.T) {
+	for _, e := range allEnvs {
+		testClientByID(t, e)
+	}
+}
+
+func testClientByID(t *testing.T, e env) {
+	te := newTest(t, e, network.WriteTimeout(1*time.Second))
+	te.startBoostrap(2)
+	defer te.tearDown()
+
+	node := te.nodes[0]
+
+	// Connected peers announce their signed addresses.
+	var record *pb.PeerRecord
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if r, ok := te.bootstrapNode.PeerStore().Record(node.GetID()); ok {
+			record = r
+			break
+		}
+	}
+	if record == nil {
+		t.Fatalf("[%s] expected the bootstrap node to learn the addresses of its peer", e.name)
+	}
+	assert.Equalf(t, []string{node.Address}, te.bootstrapNode.PeerStore().Addresses(node.GetID()), "[%s] unexpected peer addresses", e.name)
+
+	// A node which never met the peer dials it by its ID alone, falling back
+	// from an unreachable address signed later to the one it listens on.
+	builder := network.NewBuilderWithOptions(te.builderOptions...)
+	builder.SetKeys(e.signature.RandomKeyPair())
+	builder.SetAddress(network.FormatAddress(e.networkType, "localhost", uint16(network.GetRandomUnusedPort())))
+
+	stranger, err := builder.Build()
+	assert.Nil(t, err)
+	go stranger.Listen()
+	stranger.BlockUntilListening()
+	defer stranger.Close()
+
+	unreachable := &pb.SignedAddress{
+		Address:   network.FormatAddress(e.networkType, "localhost", uint16(network.GetRandomUnusedPort())),
+		Timestamp: time.Now().UnixNano(),
+	}
+	unreachable.Signature, err = node.GetKeys().Sign(e.signature, e.hash, network.SerializeAddress(record.Id, unreachable))
+	assert.Nil(t, err)
+	record.Addresses = append(record.Addresses, unreachable)
+
+	assert.Nil(t, stranger.PeerStore().AddRecord(record))
+	assert.Equalf(t, unreachable.Address, stranger.PeerStore().Addresses(node.GetID())[0], "[%s] expected most recent address first", e.name)
+
+	id := peer.ID{Id: node.GetID().Id, NetKey: node.GetID().NetKey}
+
+	client, err := stranger.ClientByID(id)
+	assert.Equalf(t, nil, err, "[%s] expected client error to be nil", e.name)
+	if client != nil {
+		assert.Equalf(t, node.Address, client.Address, "[%s] expected client to be dialed on the reachable address", e.name)
+	}
+
+	_, err = stranger.ClientByID(peer.ID{Id: []byte("unknown")})
+	assert.NotNilf(t, err, "[%s] expected dialing an unknown peer to fail", e.name)
+
+	// A peer reached on the address of another ID is not taken for it, and
+	// the connection dialed to it is closed.
+	impostor := peer.CreateID(te.bootstrapNode.Address, e.signature.RandomKeyPair().PublicKey)
+	_, err = stranger.ClientByID(impostor)
+	assert.NotNilf(t, err, "[%s] expected dialing a peer by another ID to fail", e.name)
+
+	stranger.EachPeer(func(client *network.PeerClient) bool {
+		assert.NotEqualf(t, te.bootstrapNode.Address, client.Address, "[%s] expected the connection to the other peer to be closed", e.name)
+		return true
+	})
+}
+
+func TestClientByIDRelayed(t *testing.T) {
+	te := newTest(t, tcpEnv, network.WriteTimeout(1*time.Second), network.ConnectionTimeout(3*time.Second))
+	te.startBoostrap(2)
+	defer te.tearDown()
+
+	node := te.nodes[0]
+	for deadline := time.Now().Add(3 * time.Second); len(te.bootstrapNode.PeerStore().Addresses(node.GetID())) == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the bootstrap node to learn the addresses of its peer")
+		}
+	}
+
+	// A node which only knows the bootstrap node learns the addresses of the
+	// peer from it.
+	builder := network.NewBuilderWithOptions(te.builderOptions...)
+	builder.SetKeys(tcpEnv.signature.RandomKeyPair())
+	builder.SetAddress(network.FormatAddress("tcp", "localhost", uint16(network.GetRandomUnusedPort())))
+
+	stranger, err := builder.Build()
+	assert.Nil(t, err)
+	go stranger.Listen()
+	stranger.BlockUntilListening()
+	defer stranger.Close()
+
+	_, err = stranger.ClientByID(te.bootstrapNode.GetID())
+	assert.Nil(t, err)
+
+	id := peer.ID{Id: node.GetID().Id, NetKey: node.GetID().NetKey}
+	client, err := stranger.ClientByID(id)
+	if assert.Nil(t, err) {
+		assert.Equal(t, node.Address, client.Address)
+	}
+	assert.Equal(t, []string{node.Address}, stranger.PeerStore().Addresses(id))
+}
+
+func TestMultipleListeners(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipping %s in short mode", t.Name())
+	}
+
+	te := newTest(t, tcpEnv, network.WriteTimeout(1*time.Second))
+
+	builder := network.NewBuilderWithOptions(te.builderOptions...)
+	builder.SetAddress(network.FormatAddress("tcp", "127.0.0.1", uint16(network.GetRandomUnusedPort())))
+	builder.AddAddress(network.FormatAddress("kcp", "127.0.0.1", uint16(network.GetRandomUnusedPort())))
+	builder.AddAddress(network.FormatAddress("tcp", "::1", uint16(network.GetRandomUnusedPort())))
+	builder.AddComponent(new(clientTestComponent))
+
+	node, err := builder.Build()
+	assert.Nil(t, err)
+	go node.Listen()
+	node.BlockUntilListening()
+	defer node.Close()
+
+	// Peers using different transports and IP versions reach the same node.
+	for _, address := range node.Addresses() {
+		info, err := network.ParseAddress(address)
+		assert.Nil(t, err)
+
+		builder := network.NewBuilderWithOptions(te.builderOptions...)
+		builder.SetAddress(network.FormatAddress(info.Protocol, "127.0.0.1", uint16(network.GetRandomUnusedPort())))
+
+		other, err := builder.Build()
+		assert.Nil(t, err)
+		go other.Listen()
+		other.BlockUntilListening()
+		defer other.Close()
+
+		client, err := other.Client(address)
+		assert.Equalf(t, nil, err, "[%s] expected client error to be nil", address)
+		if err != nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		response, err := client.Request(ctx, &protobuf.TestMessage{Message: address})
+		cancel()
+		assert.Equalf(t, nil, err, "[%s] expected request to succeed", address)
+		if resp, ok := response.(*protobuf.TestMessage); assert.Truef(t, ok, "[%s] expected response to be cast successfully", address) {
+			assert.Equal(t, address, resp.Message)
+		}
+
+		// The node advertises all of its addresses.
+		var addresses []string
+		for deadline := time.Now().Add(3 * time.Second); len(addresses) < 3 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			addresses = other.PeerStore().Addresses(node.GetID())
+		}
+		assert.Equalf(t, node.Addresses(), addresses, "[%s] expected all addresses of the node to be advertised", address)
+	}
+}
+
+func TestIPv6Loopback(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipping %s in short mode", t.Name())
+	}
+
+	for _, e := range allEnvs {
+		testIPv6Loopback(t, e)
+	}
+}
+
+func testIPv6Loopback(t *testing.T, e env) {
+	te := newTest(t, e, network.WriteTimeout(1*time.Second), network.PreferredIPFamily(network.IPv6))
+
+	var nodes []*network.Network
+	for i := 0; i < 2; i++ {
+		builder := network.NewBuilderWithOptions(te.builderOptions...)
+		builder.SetKeys(e.signature.RandomKeyPair())
+		builder.SetAddress(network.FormatAddress(e.networkType, "::1", uint16(network.GetRandomUnusedPort())))
+		builder.AddComponent(new(clientTestComponent))
+
+		node, err := builder.Build()
+		if err != nil {
+			t.Fatalf("[%s] Build() = expected no error, got %v", e.name, err)
+		}
+		go node.Listen()
+		node.BlockUntilListening()
+		defer node.Close()
+
+		nodes = append(nodes, node)
+	}
+
+	client, err := nodes[0].Client(nodes[1].Address)
+	if !assert.Equalf(t, nil, err, "[%s] expected client error to be nil", e.name) {
+		return
+	}
+	assert.Equalf(t, nodes[1].Address, client.Address, "[%s] expected client to keep the IPv6 address", e.name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	msgStr := "IPv6 test message"
+	response, err := client.Request(ctx, &protobuf.TestMessage{Message: msgStr})
+	assert.Equalf(t, nil, err, "[%s] expected request over IPv6 to succeed", e.name)
+	if resp, ok := response.(*protobuf.TestMessage); assert.Truef(t, ok, "[%s] expected response to be cast successfully", e.name) {
+		assert.Equal(t, msgStr, resp.Message)
+	}
+}
@@ -2,21 +2,36 @@ package network_test
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/cocher/crypto"
 	"github.com/cocher/crypto/blake2b"
 	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/crypto/secp256k1"
+	"github.com/cocher/crypto/sm2"
+	"github.com/cocher/crypto/sm3"
+	"github.com/cocher/dht"
 	"github.com/cocher/internal/test/protobuf"
 	"github.com/cocher/network"
+	"github.com/cocher/network/codec"
 	"github.com/cocher/network/discovery"
 	"github.com/cocher/peer"
 	"github.com/cocher/types/opcode"
+	"github.com/pkg/errors"
 )
 
 func init() {
 	opcode.RegisterMessageType(opcode.Opcode(1000), &protobuf.TestMessage{})
+	opcode.RegisterType(opcode.Opcode(1001), &jsonTestMessage{}, codec.JSONName)
+}
+
+// jsonTestMessage is a plain Go type sent encoded as JSON.
+type jsonTestMessage struct {
+	Message string   `json:"message"`
+	Tags    []string `json:"tags"`
 }
 
 type env struct {
@@ -29,6 +44,8 @@ type env struct {
 var (
 	kcpEnv             = env{name: "kcp-blake2b-ed25519", networkType: "kcp", hash: blake2b.New(), signature: ed25519.New()}
 	tcpEnv             = env{name: "tcp-blake2b-ed25519", networkType: "tcp", hash: blake2b.New(), signature: ed25519.New()}
+	tcpSecp256k1Env    = env{name: "tcp-blake2b-secp256k1", networkType: "tcp", hash: blake2b.New(), signature: secp256k1.New()}
+	tcpSMEnv           = env{name: "tcp-sm3-sm2", networkType: "tcp", hash: sm3.New(), signature: sm2.New()}
 	allEnvs            = []env{kcpEnv, tcpEnv}
 	mailboxComponentID = (*MailBoxComponent)(nil)
 )
@@ -47,7 +64,7 @@ func newTest(t *testing.T, e env, opts ...network.BuilderOption) *testSuite {
 	te := &testSuite{
 		t:              t,
 		e:              e,
-		builderOptions: opts,
+		builderOptions: append([]network.BuilderOption{network.SignaturePolicy(e.signature), network.HashPolicy(e.hash)}, opts...),
 	}
 	return te
 }
@@ -124,13 +141,16 @@ func (te *testSuite) getPeers(n *network.Network) []peer.ID {
 	if n == nil {
 		return nil
 	}
+	return te.getRoutes(n).GetPeers()
+}
+
+func (te *testSuite) getRoutes(n *network.Network) *dht.RoutingTable {
 	ComponentInt, ok := n.Component(discovery.ComponentID)
 	if !ok {
 		te.t.Errorf("Component() expected true, got false")
 	}
 	Component := ComponentInt.(*discovery.Component)
-	routes := Component.Routes
-	return routes.GetPeers()
+	return Component.Routes
 }
 
 // MailBoxComponent buffers all messages into a mailbox for test validation.
@@ -146,11 +166,15 @@ func (state *MailBoxComponent) Startup(net *network.Network) {
 	state.SendMailbox = make(chan *protobuf.TestMessage, 100)
 }
 
-// Send puts a sent message into the SendMailbox channel
-func (state *MailBoxComponent) Send(ctx *network.ComponentContext) error {
+// Send puts a sent message into the SendMailbox channel, dropping it if the
+// channel is full so that sending never blocks on an undrained mailbox
+func (state *MailBoxComponent) Send(ctx *network.SendContext) error {
 	switch msg := ctx.Message().(type) {
 	case *protobuf.TestMessage:
-		state.SendMailbox <- msg
+		select {
+		case state.SendMailbox <- msg:
+		default:
+		}
 	}
 	return nil
 }
@@ -187,14 +211,145 @@ type clientTestComponent struct {
 	*network.Component
 }
 
+// RegisterHandlers handles test messages with Receive, so that the errors it
+// returns are replied to requests
+func (p *clientTestComponent) RegisterHandlers(router *network.Router) error {
+	if err := router.Handle(&protobuf.TestMessage{}, network.HandlerFunc(p.Receive)); err != nil {
+		return err
+	}
+	return router.Handle(&jsonTestMessage{}, network.HandlerFunc(p.Receive))
+}
+
 // Receive takes in *messages.ProxyMessage and replies with *messages.ID
 func (p *clientTestComponent) Receive(ctx *network.ComponentContext) error {
 	switch msg := ctx.Message().(type) {
 	case *protobuf.TestMessage:
+		switch msg.Message {
+		case "not found":
+			return network.NewRemoteError(network.ErrorCodeNotFound, "no such message").WithDetail("message", msg.Message)
+		case "fail":
+			return errors.New("failed by test")
+		}
+
 		response := &protobuf.TestMessage{Message: msg.Message}
 		time.Sleep(time.Duration(msg.Duration) * time.Second)
 		ctx.Reply(context.Background(), response)
+	case *jsonTestMessage:
+		ctx.Reply(context.Background(), &jsonTestMessage{Message: msg.Message, Tags: append(msg.Tags, "reply")})
+	}
+
+	return nil
+}
+
+// Plugin for streaming requests test
+type streamTestComponent struct {
+	*network.Component
+
+	// number of replies sent
+	sent uint32
+	// errors streams ended with
+	errs chan error
+}
+
+// RegisterHandlers handles test messages with Receive, so that the errors it
+// returns end streams
+func (p *streamTestComponent) RegisterHandlers(router *network.Router) error {
+	return router.Handle(&protobuf.TestMessage{}, network.HandlerFunc(p.Receive))
+}
+
+// Receive streams as many replies as asked for, or replies until canceled
+func (p *streamTestComponent) Receive(ctx *network.ComponentContext) error {
+	msg, ok := ctx.Message().(*protobuf.TestMessage)
+	if !ok || !ctx.IsStream() {
+		return nil
+	}
+
+	switch msg.Message {
+	case "once":
+		return ctx.Reply(context.Background(), &protobuf.TestMessage{Message: msg.Message})
+	case "fail":
+		stream, err := ctx.Stream()
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(context.Background(), &protobuf.TestMessage{Message: "0"}); err != nil {
+			return err
+		}
+		return errors.New("failed by test")
+	}
+
+	stream, err := ctx.Stream()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for i := 0; msg.Message == "forever" || i < int(msg.Duration); i++ {
+			if err := stream.Send(context.Background(), &protobuf.TestMessage{Message: fmt.Sprint(i)}); err != nil {
+				p.errs <- err
+				return
+			}
+			atomic.AddUint32(&p.sent, 1)
+		}
+		stream.Close(context.Background())
+	}()
+
+	return nil
+}
+
+// Plugin for request cancellation test
+type cancelTestComponent struct {
+	*network.Component
+
+	// deadlines of the requests handled
+	deadlines chan time.Time
+	// errors the contexts of the requests ended with
+	errs chan error
+}
+
+// Receive waits for the context of requests to be done
+func (p *cancelTestComponent) Receive(ctx *network.ComponentContext) error {
+	if msg, ok := ctx.Message().(*protobuf.TestMessage); !ok || msg.Message != "wait" {
+		return nil
+	}
+
+	deadline, _ := ctx.Context().Deadline()
+	p.deadlines <- deadline
+
+	select {
+	case <-ctx.Context().Done():
+		p.errs <- ctx.Context().Err()
+	case <-time.After(3 * time.Second):
+		p.errs <- errors.New("request was not canceled")
+	}
+
+	return nil
+}
+
+// Plugin for outbound hooks test
+type outboundTestComponent struct {
+	*network.Component
+
+	// address requests and messages asking for it are rerouted to
+	reroute string
+}
+
+// Send tags test messages, vetoes those asking for it and reroutes others
+func (p *outboundTestComponent) Send(ctx *network.SendContext) error {
+	msg, ok := ctx.Message().(*protobuf.TestMessage)
+	if !ok || ctx.IsReply() {
+		return nil
+	}
+
+	switch msg.Message {
+	case "veto":
+		return errors.New("vetoed by test")
+	case "reroute":
+		ctx.SetAddresses(p.reroute)
+	case "drop":
+		ctx.SetAddresses()
 	}
 
+	ctx.SetMessage(&protobuf.TestMessage{Message: msg.Message + " [tagged]", Duration: msg.Duration})
 	return nil
 }
@@ -0,0 +1,166 @@
+package network
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/cocher/utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// SendContext provides parameters and helper functions to a Component for
+// inspecting and altering an outgoing message before it is sent.
+type SendContext struct {
+	ctx       context.Context
+	network   *Network
+	message   interface{}
+	addresses []string
+	nonce     uint64
+	request   bool
+	reply     bool
+}
+
+// Context returns the context the message is sent with.
+func (sctx *SendContext) Context() context.Context {
+	return sctx.ctx
+}
+
+// Network returns the entire node's network.
+func (sctx *SendContext) Network() *Network {
+	return sctx.network
+}
+
+// Message returns the message about to be sent.
+func (sctx *SendContext) Message() interface{} {
+	return sctx.message
+}
+
+// SetMessage replaces the message sent, e.g. with a copy of it carrying a tag.
+func (sctx *SendContext) SetMessage(message interface{}) {
+	sctx.message = message
+}
+
+// Addresses returns the addresses of the peers the message is sent to.
+func (sctx *SendContext) Addresses() []string {
+	return append([]string{}, sctx.addresses...)
+}
+
+// SetAddresses reroutes the message to other peers, which are dialed if not
+// connected yet. Setting no address drops the message. Requests are sent to
+// exactly one peer, the reply of which is awaited.
+func (sctx *SendContext) SetAddresses(addresses ...string) {
+	sctx.addresses = append([]string{}, addresses...)
+}
+
+// IsRequest returns true if the message is a request awaiting a reply.
+func (sctx *SendContext) IsRequest() bool {
+	return sctx.request
+}
+
+// IsReply returns true if the message replies to a request.
+func (sctx *SendContext) IsReply() bool {
+	return sctx.reply
+}
+
+// Nonce returns the nonce of the request the message replies to.
+func (sctx *SendContext) Nonce() uint64 {
+	return sctx.nonce
+}
+
+// intercept runs the Send callback of Components on an outgoing message in
+// ascending order of priority. The first error returned vetoes the message.
+// A callback which panicked vetoes it too, unless the panic policy is to
+// carry on as if it returned without an error.
+func (n *Network) intercept(sctx *SendContext) error {
+	sctx.network = n
+
+	var err error
+	n.Components.eachInfo(func(info *ComponentInfo) {
+		handler, ok := info.Component.(SendHandler)
+		if !ok || err != nil {
+			return
+		}
+		if n.safely(info, nil, func() { err = handler.Send(sctx) }) {
+			err = nil
+			if n.opts.panicPolicy != ContinueOnPanic {
+				err = errors.Errorf("Component %s panicked", reflect.TypeOf(info.Component))
+			}
+		}
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "network: outgoing message was vetoed")
+	}
+	return nil
+}
+
+// clients returns the clients of the peers an intercepted message is sent
+// to, dialing peers it was rerouted to if need be, and checks that they
+// support the message.
+func (n *Network) clients(sctx *SendContext, original *PeerClient) ([]*PeerClient, error) {
+	clients := make([]*PeerClient, 0, len(sctx.addresses))
+	for _, address := range sctx.addresses {
+		client, err := n.client(sctx, original, address)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+// client returns the client of a peer an intercepted message is sent to,
+// dialing it if it is not the original one, and checks that it supports the
+// message.
+func (n *Network) client(sctx *SendContext, original *PeerClient, address string) (*PeerClient, error) {
+	client := original
+	if client == nil || address != client.Address {
+		var err error
+		if client, err = n.Client(address); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := client.Supports(sctx.message); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// broadcast runs the outbound hooks on a message broadcasted to a set of
+// peers, and writes it to the peers the hooks left, dialing them if need be.
+// Peers which can not be dialed or do not support the message are skipped.
+func (n *Network) broadcast(ctx context.Context, message interface{}, addresses []string) {
+	sctx := &SendContext{ctx: ctx, message: message, addresses: addresses}
+	if err := n.intercept(sctx); err != nil {
+		log.Warnf("network: failed to broadcast message: %v", err)
+		return
+	}
+
+	clients := make([]*PeerClient, 0, len(sctx.addresses))
+	for _, address := range sctx.addresses {
+		client, err := n.client(sctx, nil, address)
+		if err != nil {
+			log.Warnf("failed to send message to peer %s [err=%s]", address, err)
+			continue
+		}
+		clients = append(clients, client)
+	}
+
+	if len(clients) == 0 {
+		return
+	}
+
+	signed, err := n.PrepareMessage(sctx.ctx, sctx.message)
+	if err != nil {
+		log.Errorf("network: failed to broadcast message")
+		return
+	}
+
+	for _, client := range clients {
+		if err := n.Write(client.Address, signed); err != nil {
+			log.Warnf("failed to send message to peer %s [err=%s]", client.Address, err)
+		}
+	}
+}
@@ -28,6 +28,8 @@ func (n *Network) sendUDPMessage(w io.Writer, message *protobuf.Message, writerM
 		} else {
 			log.Errorf("package: failed to load dial address")
 		}
+	} else if address, ok := n.listenAddress("udp"); ok {
+		message.DialAddress = address
 	} else {
 		message.DialAddress = n.Address
 	}
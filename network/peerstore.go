@@ -0,0 +1,487 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cocher/crypto"
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/peer"
+	"github.com/cocher/utils/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxPeerAddresses bounds the number of addresses kept for a single peer.
+	maxPeerAddresses = 16
+	// maxStoredPeers bounds the number of peers addresses are kept for.
+	maxStoredPeers = 4096
+	// maxAddressClockSkew is how far ahead of the local clock addresses may
+	// be signed, so that they can not outlive pruning.
+	maxAddressClockSkew = 30 * time.Second
+	// maxRecordQueries bounds the number of connected peers asked for the
+	// addresses of a peer which can not be reached otherwise.
+	maxRecordQueries = 8
+)
+
+// addressDomain separates address signatures from message signatures.
+var addressDomain = []byte("cocher/peer-address")
+
+// SerializeAddress packs the fields of an address signed by the peer owning it.
+// The address in the ID is left out, so that signatures stay valid for every
+// address the peer is known by.
+func SerializeAddress(id *protobuf.ID, address *protobuf.SignedAddress) []byte {
+	timestamp := make([]byte, 8)
+	binary.LittleEndian.PutUint64(timestamp, uint64(address.Timestamp))
+
+	raw := append(append([]byte{}, addressDomain...), address.Address...)
+	return SerializeMessage(&protobuf.ID{Id: id.Id}, append(raw, timestamp...))
+}
+
+// VerifyAddress checks that an address is signed by the key of the given peer,
+// and that the ID of the peer matches its key.
+func VerifyAddress(sp crypto.SignaturePolicy, hp crypto.HashPolicy, id *protobuf.ID, address *protobuf.SignedAddress) error {
+	if id == nil || address == nil {
+		return errors.New("network: peer record is missing an ID or address")
+	}
+
+	if !bytes.Equal(id.Id, hp.HashBytes(id.NetKey)) {
+		return errors.New("network: peer record ID does not match its key")
+	}
+
+	if !crypto.Verify(sp, hp, id.NetKey, SerializeAddress(id, address), address.Signature) {
+		return errors.Errorf("network: address %s has a malformed signature", address.Address)
+	}
+
+	return nil
+}
+
+// PeerStore keeps the known addresses of peers, keyed by the hash of their
+// public key rather than by address, so that a peer which moves to another
+// address or listens on several transports is still the same peer.
+//
+// Every address is signed by the peer together with the time it was signed
+// at, so that addresses can not be forged and newer ones win over older ones.
+// Once the store holds addresses of maxStoredPeers peers, the peer signed the
+// longest ago is forgotten for a new one.
+type PeerStore struct {
+	sp crypto.SignaturePolicy
+	hp crypto.HashPolicy
+
+	mutex    sync.RWMutex
+	peers    map[string]*peerEntry
+	maxPeers int
+}
+
+type peerEntry struct {
+	id        peer.ID
+	addresses []*protobuf.SignedAddress
+}
+
+// NewPeerStore returns an empty peer store verifying addresses with the given policies.
+func NewPeerStore(sp crypto.SignaturePolicy, hp crypto.HashPolicy) *PeerStore {
+	return &PeerStore{
+		sp:       sp,
+		hp:       hp,
+		peers:    make(map[string]*peerEntry),
+		maxPeers: maxStoredPeers,
+	}
+}
+
+func storeKey(id peer.ID) string {
+	return hex.EncodeToString(id.Id)
+}
+
+// Add verifies and stores addresses of a peer. An address already known for
+// the peer is only replaced by one signed later. If any address fails to
+// verify, or is signed too far in the future, none of them are stored.
+func (s *PeerStore) Add(id peer.ID, addresses ...*protobuf.SignedAddress) error {
+	pid := protobuf.ID(id)
+	latest := int64(0)
+	for _, address := range addresses {
+		if err := VerifyAddress(s.sp, s.hp, &pid, address); err != nil {
+			return err
+		}
+		if address.Timestamp > time.Now().Add(maxAddressClockSkew).UnixNano() {
+			return errors.Errorf("network: address %s is signed in the future", address.Address)
+		}
+		if address.Timestamp > latest {
+			latest = address.Timestamp
+		}
+	}
+
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.peers[storeKey(id)]
+	if !ok {
+		if len(s.peers) >= s.maxPeers && !s.evict(latest) {
+			return errors.Errorf("network: peer store is full, not storing peer %s", hex.EncodeToString(id.Id))
+		}
+
+		entry = &peerEntry{id: id}
+		s.peers[storeKey(id)] = entry
+	}
+
+	for _, address := range addresses {
+		known := false
+		for i, existing := range entry.addresses {
+			if existing.Address == address.Address {
+				if address.Timestamp > existing.Timestamp {
+					entry.addresses[i] = address
+				}
+				known = true
+				break
+			}
+		}
+		if !known {
+			entry.addresses = append(entry.addresses, address)
+		}
+	}
+
+	// Most recently signed addresses come first; addresses signed at the same
+	// time keep the order of preference of the peer.
+	sort.SliceStable(entry.addresses, func(i, j int) bool {
+		return entry.addresses[i].Timestamp > entry.addresses[j].Timestamp
+	})
+
+	if len(entry.addresses) > maxPeerAddresses {
+		entry.addresses = entry.addresses[:maxPeerAddresses]
+	}
+
+	return nil
+}
+
+// evict forgets the peer the latest address of which was signed the longest
+// ago, if it was signed before the given time. Returns false if no peer was
+// forgotten.
+func (s *PeerStore) evict(before int64) bool {
+	var oldest string
+	for key, entry := range s.peers {
+		if oldest == "" || entry.latest() < s.peers[oldest].latest() {
+			oldest = key
+		}
+	}
+
+	if oldest == "" || s.peers[oldest].latest() >= before {
+		return false
+	}
+
+	delete(s.peers, oldest)
+	return true
+}
+
+// latest returns when the most recent address of the peer was signed.
+func (e *peerEntry) latest() int64 {
+	if len(e.addresses) == 0 {
+		return 0
+	}
+	return e.addresses[0].Timestamp
+}
+
+// AddRecord verifies and stores the addresses in a peer record.
+func (s *PeerStore) AddRecord(record *protobuf.PeerRecord) error {
+	if record.Id == nil {
+		return errors.New("network: peer record is missing an ID")
+	}
+	return s.Add(peer.ID(*record.Id), record.Addresses...)
+}
+
+// Addresses returns the addresses known for a peer, in the order they should
+// be dialed in.
+func (s *PeerStore) Addresses(id peer.ID) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, ok := s.peers[storeKey(id)]
+	if !ok {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(entry.addresses))
+	for _, address := range entry.addresses {
+		addresses = append(addresses, address.Address)
+	}
+	return addresses
+}
+
+// Record returns the signed addresses known for a peer, so that they may be
+// passed on to other nodes.
+func (s *PeerStore) Record(id peer.ID) (*protobuf.PeerRecord, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, ok := s.peers[storeKey(id)]
+	if !ok {
+		return nil, false
+	}
+
+	pid := protobuf.ID(entry.id)
+	return &protobuf.PeerRecord{
+		Id:        &pid,
+		Addresses: append([]*protobuf.SignedAddress{}, entry.addresses...),
+	}, true
+}
+
+// Peers returns the IDs of all peers with known addresses.
+func (s *PeerStore) Peers() []peer.ID {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	ids := make([]peer.ID, 0, len(s.peers))
+	for _, entry := range s.peers {
+		ids = append(ids, entry.id)
+	}
+	return ids
+}
+
+// Remove forgets all addresses of a peer.
+func (s *PeerStore) Remove(id peer.ID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.peers, storeKey(id))
+}
+
+// Prune forgets addresses signed before the given time, and peers left
+// without any address.
+func (s *PeerStore) Prune(before time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, entry := range s.peers {
+		addresses := entry.addresses[:0]
+		for _, address := range entry.addresses {
+			if address.Timestamp >= before.UnixNano() {
+				addresses = append(addresses, address)
+			}
+		}
+		entry.addresses = addresses
+
+		if len(entry.addresses) == 0 {
+			delete(s.peers, key)
+		}
+	}
+}
+
+// PeerRecord returns the addresses of this node signed with its current keys,
+// in order of preference.
+func (n *Network) PeerRecord() (*protobuf.PeerRecord, error) {
+	n.identityMutex.RLock()
+	id, keys := protobuf.ID(n.id), n.keys
+	n.identityMutex.RUnlock()
+
+	record := &protobuf.PeerRecord{Id: &id}
+	timestamp := time.Now().UnixNano()
+
+	for _, listenAddress := range n.AdvertisedAddresses() {
+		address := &protobuf.SignedAddress{
+			Address:   listenAddress,
+			Timestamp: timestamp,
+		}
+
+		var err error
+		address.Signature, err = keys.Sign(n.opts.signaturePolicy, n.opts.hashPolicy, SerializeAddress(&id, address))
+		if err != nil {
+			return nil, err
+		}
+
+		record.Addresses = append(record.Addresses, address)
+	}
+
+	return record, nil
+}
+
+// PeerStore returns the store of known peer addresses.
+func (n *Network) PeerStore() *PeerStore {
+	return n.peerStore
+}
+
+// announceAddresses sends the signed addresses of this node to a peer.
+func (n *Network) announceAddresses(client *PeerClient) {
+	record, err := n.PeerRecord()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if err := client.Tell(context.Background(), record); err != nil {
+		log.Warnf("network: failed to announce addresses to %s: %v", client.Address, err)
+	}
+}
+
+// handlePeerRecord stores the addresses of a peer record received from a
+// peer. Requests with a record without addresses ask for the addresses known
+// for its ID, and are replied to with the record in the peer store.
+func (n *Network) handlePeerRecord(client *PeerClient, record *protobuf.PeerRecord, nonce uint64) {
+	if record.Id == nil {
+		log.Errorf("network: rejected peer record from %s: missing ID", client.Address)
+		return
+	}
+
+	if len(record.Addresses) == 0 && nonce > 0 {
+		reply, ok := n.peerStore.Record(peer.ID(*record.Id))
+		if !ok {
+			reply = &protobuf.PeerRecord{Id: record.Id}
+		}
+		if err := client.Reply(context.Background(), nonce, reply); err != nil {
+			log.Warnf("network: failed to reply to peer %s with a peer record: %v", client.Address, err)
+		}
+		return
+	}
+
+	if peer.ID(*record.Id).Equals(n.GetID()) {
+		return
+	}
+
+	if err := n.peerStore.AddRecord(record); err != nil {
+		log.Errorf("network: rejected peer record from %s: %v", client.Address, err)
+	}
+}
+
+// queryPeerRecord asks connected peers for the addresses they know a peer by,
+// and stores the addresses they reply with. Returns false if no peer knew any.
+func (n *Network) queryPeerRecord(id peer.ID) bool {
+	pid := protobuf.ID(peer.ID{Id: id.Id, NetKey: id.NetKey})
+	query := &protobuf.PeerRecord{Id: &pid}
+
+	var clients []*PeerClient
+	n.EachPeer(func(client *PeerClient) bool {
+		if client.ID() != nil && !client.ID().Equals(id) {
+			clients = append(clients, client)
+		}
+		return len(clients) < maxRecordQueries
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.opts.connectionTimeout)
+	defer cancel()
+
+	var found uint32
+	var wait sync.WaitGroup
+	for _, client := range clients {
+		wait.Add(1)
+		go func(client *PeerClient) {
+			defer wait.Done()
+
+			reply, err := client.Request(ctx, query)
+			if err != nil {
+				return
+			}
+
+			record, ok := reply.(*protobuf.PeerRecord)
+			if !ok || record.Id == nil || !bytes.Equal(record.Id.Id, id.Id) || len(record.Addresses) == 0 {
+				return
+			}
+			if err := n.peerStore.AddRecord(record); err != nil {
+				log.Warnf("network: peer %s relayed an invalid peer record: %v", client.Address, err)
+				return
+			}
+			atomic.StoreUint32(&found, 1)
+		}(client)
+	}
+	wait.Wait()
+
+	return atomic.LoadUint32(&found) == 1
+}
+
+// ClientByID returns a client for the peer with the given ID. If the peer is
+// not connected, the addresses known for it in the peer store are dialed in
+// order, falling back to the address in the ID, until the peer reached proves
+// to be the one with the ID, by signing a message with it. If none of them
+// is reached, connected peers are asked for the addresses they know the peer
+// by.
+func (n *Network) ClientByID(id peer.ID) (*PeerClient, error) {
+	var client *PeerClient
+
+	n.EachPeer(func(c *PeerClient) bool {
+		if c.ID() != nil && c.ID().Equals(id) {
+			client = c
+			return false
+		}
+		return true
+	})
+
+	if client != nil {
+		return client, nil
+	}
+
+	var errs []string
+	tried := make(map[string]struct{})
+
+	addresses := n.peerStore.Addresses(id)
+	if id.Address != "" {
+		addresses = append(addresses, id.Address)
+	}
+	if client := n.dialID(id, addresses, tried, &errs); client != nil {
+		return client, nil
+	}
+
+	if n.queryPeerRecord(id) {
+		if client := n.dialID(id, n.peerStore.Addresses(id), tried, &errs); client != nil {
+			return client, nil
+		}
+	}
+
+	if len(tried) == 0 {
+		return nil, errors.Errorf("network: no known addresses for peer %s", hex.EncodeToString(id.Id))
+	}
+
+	return nil, errors.Errorf("network: failed to dial peer %s: %s", hex.EncodeToString(id.Id), strings.Join(errs, "; "))
+}
+
+// dialID dials the addresses not tried yet in order, until the peer reached
+// proves to be the one with the ID. Errors dialing are appended to errs.
+func (n *Network) dialID(id peer.ID, addresses []string, tried map[string]struct{}, errs *[]string) *PeerClient {
+	for _, address := range addresses {
+		if _, seen := tried[address]; seen {
+			continue
+		}
+		tried[address] = struct{}{}
+
+		// An address may have been reassigned to another peer, which the
+		// connection is left to if it was established already.
+		connected := false
+		if unified, err := ToUnifiedAddressWithFamily(address, n.opts.preferredIPFamily); err == nil {
+			_, connected = n.peers.Load(unified)
+		}
+
+		client, err := n.Client(address)
+		if err != nil {
+			*errs = append(*errs, err.Error())
+			continue
+		}
+
+		if !client.waitIdentified(n.opts.connectionTimeout) {
+			*errs = append(*errs, fmt.Sprintf("peer at %s did not identify itself", address))
+			if !connected {
+				client.Close()
+			}
+			continue
+		}
+		// A peer which rotated its keys is still reached by its previous ID
+		// during the grace period.
+		if !n.acceptsSender(client, id) {
+			*errs = append(*errs, fmt.Sprintf("%s belongs to peer %s", address, hex.EncodeToString(client.ID().Id)))
+			if !connected {
+				client.Close()
+			}
+			continue
+		}
+
+		return client
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cocher/crypto"
+	"github.com/cocher/crypto/blake2b"
+	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func signTestAddress(t *testing.T, keys *crypto.KeyPair, id peer.ID, address string, timestamp int64) *protobuf.SignedAddress {
+	pid := protobuf.ID(id)
+	signed := &protobuf.SignedAddress{Address: address, Timestamp: timestamp}
+
+	var err error
+	signed.Signature, err = keys.Sign(ed25519.New(), blake2b.New(), SerializeAddress(&pid, signed))
+	assert.Nil(t, err)
+
+	return signed
+}
+
+func TestPeerStoreAdd(t *testing.T) {
+	t.Parallel()
+
+	store := NewPeerStore(ed25519.New(), blake2b.New())
+
+	keys := ed25519.RandomKeyPair()
+	id := peer.CreateID("tcp://localhost:1000", keys.PublicKey)
+
+	assert.Nil(t, store.Add(id,
+		signTestAddress(t, keys, id, "tcp://localhost:1000", 10),
+		signTestAddress(t, keys, id, "kcp://localhost:1000", 10),
+	))
+	assert.Equal(t, []string{"tcp://localhost:1000", "kcp://localhost:1000"}, store.Addresses(id), "addresses signed at the same time keep their order")
+
+	// a peer that moved is found under the same key, newest address first
+	moved := peer.CreateID("tcp://localhost:2000", keys.PublicKey)
+	assert.Nil(t, store.Add(moved, signTestAddress(t, keys, moved, "tcp://localhost:2000", 20)))
+	assert.Equal(t, []string{"tcp://localhost:2000", "tcp://localhost:1000", "kcp://localhost:1000"}, store.Addresses(id))
+	assert.Len(t, store.Peers(), 1)
+
+	// older signatures of a known address are ignored
+	assert.Nil(t, store.Add(id, signTestAddress(t, keys, id, "tcp://localhost:2000", 5)))
+	record, ok := store.Record(id)
+	assert.True(t, ok)
+	assert.Equal(t, int64(20), record.Addresses[0].Timestamp)
+	assert.Nil(t, store.AddRecord(record))
+
+	store.Prune(time.Unix(0, 15))
+	assert.Equal(t, []string{"tcp://localhost:2000"}, store.Addresses(id))
+
+	store.Remove(id)
+	assert.Nil(t, store.Addresses(id))
+	assert.Len(t, store.Peers(), 0)
+}
+
+func TestPeerStoreVerify(t *testing.T) {
+	t.Parallel()
+
+	store := NewPeerStore(ed25519.New(), blake2b.New())
+
+	keys := ed25519.RandomKeyPair()
+	id := peer.CreateID("tcp://localhost:1000", keys.PublicKey)
+
+	// the timestamp is covered by the signature
+	tampered := signTestAddress(t, keys, id, "tcp://localhost:1000", 10)
+	tampered.Timestamp++
+	assert.NotNil(t, store.Add(id, tampered))
+
+	// so is the address
+	tampered = signTestAddress(t, keys, id, "tcp://localhost:1000", 10)
+	tampered.Address = "tcp://localhost:1001"
+	assert.NotNil(t, store.Add(id, tampered))
+
+	// addresses can only be signed by the key of the peer
+	other := peer.CreateID("tcp://localhost:1000", ed25519.RandomKeyPair().PublicKey)
+	assert.NotNil(t, store.Add(other, signTestAddress(t, keys, other, "tcp://localhost:1000", 10)))
+
+	forged := id
+	forged.Id = other.Id
+	assert.NotNil(t, store.Add(forged, signTestAddress(t, keys, forged, "tcp://localhost:1000", 10)))
+
+	// a single bad address rejects the whole batch
+	assert.NotNil(t, store.Add(id, signTestAddress(t, keys, id, "tcp://localhost:1000", 10), tampered))
+	assert.Len(t, store.Peers(), 0)
+
+	assert.NotNil(t, store.AddRecord(&protobuf.PeerRecord{}))
+}
+
+func TestPeerStoreLimit(t *testing.T) {
+	t.Parallel()
+
+	store := NewPeerStore(ed25519.New(), blake2b.New())
+
+	keys := ed25519.RandomKeyPair()
+	id := peer.CreateID("tcp://localhost:1000", keys.PublicKey)
+
+	for i := 0; i < 2*maxPeerAddresses; i++ {
+		address := FormatAddress("tcp", "localhost", uint16(1000+i))
+		assert.Nil(t, store.Add(id, signTestAddress(t, keys, id, address, int64(i))))
+	}
+
+	addresses := store.Addresses(id)
+	assert.Len(t, addresses, maxPeerAddresses)
+	assert.Equal(t, FormatAddress("tcp", "localhost", uint16(1000+2*maxPeerAddresses-1)), addresses[0], "the most recent addresses are kept")
+}
+
+func TestPeerStoreCapacity(t *testing.T) {
+	t.Parallel()
+
+	store := NewPeerStore(ed25519.New(), blake2b.New())
+	store.maxPeers = 2
+
+	add := func(timestamp int64) (peer.ID, error) {
+		keys := ed25519.RandomKeyPair()
+		id := peer.CreateID("tcp://localhost:1000", keys.PublicKey)
+		return id, store.Add(id, signTestAddress(t, keys, id, "tcp://localhost:1000", timestamp))
+	}
+
+	first, err := add(10)
+	assert.Nil(t, err)
+	second, err := add(20)
+	assert.Nil(t, err)
+
+	// the peer signed the longest ago makes room for a newer one
+	third, err := add(30)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Addresses(first))
+	assert.NotNil(t, store.Addresses(second))
+	assert.NotNil(t, store.Addresses(third))
+
+	// but not for an older one
+	_, err = add(5)
+	assert.NotNil(t, err)
+	assert.Len(t, store.Peers(), 2)
+
+	// peers without addresses are not stored
+	keys := ed25519.RandomKeyPair()
+	assert.Nil(t, store.Add(peer.CreateID("tcp://localhost:1000", keys.PublicKey)))
+	assert.Len(t, store.Peers(), 2)
+
+	// addresses signed in the future would never be pruned
+	_, err = add(time.Now().Add(time.Hour).UnixNano())
+	assert.NotNil(t, err)
+}
+
+func TestPeerStorePruneLoop(t *testing.T) {
+	t.Parallel()
+
+	builder := NewBuilderWithOptions(PeerRecordTTL(40 * time.Millisecond))
+	builder.SetKeys(ed25519.RandomKeyPair())
+	builder.SetAddress(FormatAddress("tcp", "localhost", uint16(GetRandomUnusedPort())))
+	net, err := builder.Build()
+	assert.Nil(t, err)
+	defer net.Close()
+
+	keys := ed25519.RandomKeyPair()
+	id := peer.CreateID("tcp://localhost:1000", keys.PublicKey)
+	assert.Nil(t, net.PeerStore().Add(id, signTestAddress(t, keys, id, "tcp://localhost:1000", time.Now().UnixNano())))
+
+	assert.True(t, waitFor(func() bool { return net.PeerStore().Addresses(id) == nil }), "expected the addresses to be pruned")
+}
@@ -0,0 +1,367 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/network/codec"
+	"github.com/cocher/types/opcode"
+	"github.com/cocher/utils/log"
+	"github.com/cocher/utils/semver"
+
+	"github.com/pkg/errors"
+)
+
+// Protocol is a versioned protocol spoken by a Component.
+type Protocol struct {
+	// ID names the protocol, e.g. "/cocher/discovery".
+	ID string
+	// Version of the protocol this node speaks, e.g. "1.2.0".
+	Version string
+	// Range of versions spoken by peers this node can talk to, e.g.
+	// ">=1.1.0 <3.0.0". Defaults to the major version of Version.
+	Range string
+	// Opcodes of the messages of the protocol, which are only sent to peers
+	// speaking a version of the protocol in Range.
+	Opcodes []opcode.Opcode
+}
+
+// String returns the ID of the protocol followed by its version, as
+// advertised to peers.
+func (p Protocol) String() string {
+	return p.ID + "/" + p.Version
+}
+
+// parseProtocol splits a protocol advertised by a peer into its ID and version.
+func parseProtocol(raw string) (string, semver.Version, error) {
+	i := strings.LastIndexByte(raw, '/')
+	if i <= 0 {
+		return "", semver.Version{}, errors.Errorf("network: protocol %q has no version", raw)
+	}
+
+	version, err := semver.Parse(raw[i+1:])
+	if err != nil {
+		return "", semver.Version{}, err
+	}
+
+	return raw[:i], version, nil
+}
+
+// protocolSet holds the protocols spoken by the Components of a network.
+type protocolSet struct {
+	protocols []Protocol
+	ranges    map[string]semver.Range
+	// map of opcodes to the ID of the protocol they belong to
+	opcodes map[opcode.Opcode]string
+}
+
+// newProtocolSet collects and validates the protocols of Components.
+func newProtocolSet(components *ComponentList) (*protocolSet, error) {
+	set := &protocolSet{
+		ranges:  make(map[string]semver.Range),
+		opcodes: make(map[opcode.Opcode]string),
+	}
+
+	var err error
+	components.Each(func(Component ComponentInterface) {
+		if err == nil {
+			err = set.addComponent(Component)
+		}
+	})
+
+	return set, err
+}
+
+// addComponent adds the protocols of a Component to the set.
+func (s *protocolSet) addComponent(Component ComponentInterface) error {
+	provider, ok := Component.(ProtocolProvider)
+	if !ok {
+		return nil
+	}
+
+	for _, protocol := range provider.Protocols() {
+		if err := s.add(protocol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *protocolSet) add(protocol Protocol) error {
+	if protocol.ID == "" || strings.ContainsAny(protocol.ID, " \t") {
+		return errors.Errorf(ErrStrInvalidProtocol, protocol, "malformed ID")
+	}
+
+	if _, exists := s.ranges[protocol.ID]; exists {
+		return errors.Errorf(ErrStrDuplicateProtocol, protocol.ID)
+	}
+
+	version, err := semver.Parse(protocol.Version)
+	if err != nil {
+		return errors.Errorf(ErrStrInvalidProtocol, protocol, err)
+	}
+
+	if protocol.Range == "" {
+		protocol.Range = fmt.Sprintf("^%d", version.Major)
+		if version.Major == 0 {
+			protocol.Range = fmt.Sprintf("^0.%d", version.Minor)
+		}
+	}
+
+	r, err := semver.ParseRange(protocol.Range)
+	if err != nil {
+		return errors.Errorf(ErrStrInvalidProtocol, protocol, err)
+	}
+
+	for _, code := range protocol.Opcodes {
+		if owner, exists := s.opcodes[code]; exists {
+			return errors.Errorf(ErrStrProtocolOpcode, code, owner, protocol.ID)
+		}
+		s.opcodes[code] = protocol.ID
+	}
+
+	s.ranges[protocol.ID] = r
+	s.protocols = append(s.protocols, protocol)
+
+	return nil
+}
+
+// capabilities are the opcodes and protocols a peer advertised.
+type capabilities struct {
+	opcodes  map[opcode.Opcode]struct{}
+	versions map[string]semver.Version
+	// map of opcodes to the names of the different message types the peer
+	// registered them to
+	conflicts map[opcode.Opcode]string
+	codecs    map[string]struct{}
+}
+
+// UnsupportedError is returned when sending a message to a peer which
+// advertised that it does not support it.
+type UnsupportedError struct {
+	// Address of the peer.
+	Address string
+	// Opcode of the message.
+	Opcode opcode.Opcode
+	// Protocol the message belongs to, if the peer does not speak a
+	// compatible version of it.
+	Protocol string
+	// Conflict is the name of the message type the peer registered the
+	// opcode to, if it differs from the message sent.
+	Conflict string
+	// Codec the message is encoded with, if the peer can not decode it.
+	Codec string
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Codec != "" {
+		return fmt.Sprintf("network: peer %s can not decode messages encoded with %s", e.Address, e.Codec)
+	}
+	if e.Conflict != "" {
+		return fmt.Sprintf("network: peer %s registered opcode %d to another message type %s", e.Address, e.Opcode, e.Conflict)
+	}
+	if e.Protocol != "" {
+		return fmt.Sprintf("network: peer %s does not speak a compatible version of protocol %s", e.Address, e.Protocol)
+	}
+	return fmt.Sprintf("network: peer %s does not support messages with opcode %d", e.Address, e.Opcode)
+}
+
+// IsUnsupported returns true if the cause of an error is a message that the
+// peer does not support.
+func IsUnsupported(err error) bool {
+	_, ok := errors.Cause(err).(*UnsupportedError)
+	return ok
+}
+
+// loadProtocols returns the protocols currently spoken by the Components.
+func (n *Network) loadProtocols() *protocolSet {
+	return n.protocols.Load().(*protocolSet)
+}
+
+// Protocols returns the protocols spoken by the Components of this node.
+func (n *Network) Protocols() []Protocol {
+	return append([]Protocol{}, n.loadProtocols().protocols...)
+}
+
+// sendCapabilities advertises the opcodes and protocols this node supports to a peer.
+func (n *Network) sendCapabilities(client *PeerClient) {
+	msg := &protobuf.Capabilities{}
+
+	for _, entry := range n.opts.registry.Entries() {
+		msg.Opcodes = append(msg.Opcodes, uint32(entry.Opcode))
+
+		if entry.Name != "" {
+			msg.MessageTypes = append(msg.MessageTypes, &protobuf.MessageType{Opcode: uint32(entry.Opcode), Name: entry.Name})
+		}
+	}
+
+	for _, protocol := range n.loadProtocols().protocols {
+		msg.Protocols = append(msg.Protocols, protocol.String())
+	}
+
+	// the codec envelopes are encoded with comes first
+	envelope := n.opts.envelopeCodec.Name()
+	msg.Codecs = []string{envelope}
+	for _, name := range codec.Names() {
+		if name != envelope {
+			msg.Codecs = append(msg.Codecs, name)
+		}
+	}
+
+	if err := client.Tell(context.Background(), msg); err != nil {
+		log.Warnf("network: failed to send capabilities to %s: %v", client.Address, err)
+	}
+}
+
+// advertiseCapabilities advertises the opcodes and protocols this node
+// supports to all peers again, after they changed.
+func (n *Network) advertiseCapabilities() {
+	n.EachPeer(func(client *PeerClient) bool {
+		n.sendCapabilities(client)
+		return true
+	})
+}
+
+// handleCapabilities stores the opcodes and protocols a peer advertised, and
+// reports message types the peer registered to other opcodes than this node.
+func (n *Network) handleCapabilities(client *PeerClient, msg *protobuf.Capabilities) {
+	caps := &capabilities{
+		opcodes:   make(map[opcode.Opcode]struct{}, len(msg.Opcodes)),
+		versions:  make(map[string]semver.Version, len(msg.Protocols)),
+		conflicts: make(map[opcode.Opcode]string),
+		codecs:    map[string]struct{}{codec.ProtoName: {}},
+	}
+
+	for _, name := range msg.Codecs {
+		caps.codecs[name] = struct{}{}
+	}
+
+	// Envelopes are only encoded with another codec than protobuf if the
+	// peer encodes its own with it, as it then decodes them with it too.
+	if envelope := n.opts.envelopeCodec.Name(); len(msg.Codecs) > 0 && msg.Codecs[0] == envelope && envelope != codec.ProtoName {
+		if state, ok := n.ConnectionState(client.Address); ok {
+			atomic.StoreUint32(&state.encodeEnvelopes, 1)
+		}
+	}
+
+	for _, code := range msg.Opcodes {
+		caps.opcodes[opcode.Opcode(code)] = struct{}{}
+	}
+
+	for _, messageType := range msg.MessageTypes {
+		code := opcode.Opcode(messageType.Opcode)
+
+		if local, err := n.opts.registry.GetMessageName(code); err == nil && local != "" && local != messageType.Name {
+			caps.conflicts[code] = messageType.Name
+			log.Errorf("network: peer %s registered opcode %d to message %s, but it is registered to %s here", client.Address, code, messageType.Name, local)
+		}
+
+		if local, err := n.opts.registry.GetOpcodeByName(messageType.Name); err == nil && local != code {
+			log.Errorf("network: peer %s registered message %s to opcode %d, but it is registered to %d here", client.Address, messageType.Name, code, local)
+		}
+	}
+
+	for _, raw := range msg.Protocols {
+		id, version, err := parseProtocol(raw)
+		if err != nil {
+			log.Warnf("network: peer %s advertised a malformed protocol: %v", client.Address, err)
+			continue
+		}
+		caps.versions[id] = version
+	}
+
+	client.capabilities.Store(caps)
+}
+
+// Supports returns an *UnsupportedError if the peer advertised that it does
+// not support a message, either because the opcode of the message is not
+// registered at the peer or registered to another message type, or because
+// the peer does not speak a compatible version of the protocol the message
+// belongs to, or because the peer can not decode the codec the message type
+// is registered with. Until the peer advertised its capabilities, or if it
+// never does, all messages are assumed supported.
+func (c *PeerClient) Supports(message interface{}) error {
+	caps, ok := c.capabilities.Load().(*capabilities)
+	if !ok {
+		return nil
+	}
+
+	code, err := c.Network.opts.registry.GetOpcode(message)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := caps.opcodes[code]; !ok {
+		return &UnsupportedError{Address: c.Address, Opcode: code}
+	}
+
+	if name, ok := caps.conflicts[code]; ok {
+		return &UnsupportedError{Address: c.Address, Opcode: code, Conflict: name}
+	}
+
+	if id, ok := c.Network.loadProtocols().opcodes[code]; ok && !c.speaks(caps, id) {
+		return &UnsupportedError{Address: c.Address, Opcode: code, Protocol: id}
+	}
+
+	if name := c.Network.opts.registry.Codec(code); name != "" {
+		if _, ok := caps.codecs[name]; !ok {
+			return &UnsupportedError{Address: c.Address, Opcode: code, Codec: name}
+		}
+	}
+
+	return nil
+}
+
+// SetCodec sets the codec protobuf messages sent to the peer are encoded
+// with, e.g. "json". Types registered with a codec of their own keep being
+// encoded with it. Returns an *UnsupportedError if the peer advertised that
+// it can not decode the codec. An empty name resets it to protobuf.
+func (c *PeerClient) SetCodec(name string) error {
+	if name != "" {
+		if _, err := codec.Get(name); err != nil {
+			return err
+		}
+
+		if caps, ok := c.capabilities.Load().(*capabilities); ok {
+			if _, ok := caps.codecs[name]; !ok {
+				return &UnsupportedError{Address: c.Address, Codec: name}
+			}
+		}
+	}
+
+	c.codec.Store(name)
+	return nil
+}
+
+// withCodec sets the codec negotiated with the peer on a context, unless the
+// context names one already.
+func (c *PeerClient) withCodec(ctx context.Context) context.Context {
+	if name, ok := c.codec.Load().(string); ok && name != "" && GetCodec(ctx) == "" {
+		return WithCodec(ctx, name)
+	}
+	return ctx
+}
+
+// SupportsProtocol returns true if the peer speaks a version of a protocol
+// of this node that is in the range of the protocol. Until the peer
+// advertised its capabilities, it is assumed to speak all protocols.
+func (c *PeerClient) SupportsProtocol(id string) bool {
+	caps, ok := c.capabilities.Load().(*capabilities)
+	if !ok {
+		return true
+	}
+	return c.speaks(caps, id)
+}
+
+func (c *PeerClient) speaks(caps *capabilities, id string) bool {
+	r, ok := c.Network.loadProtocols().ranges[id]
+	if !ok {
+		return false
+	}
+
+	version, ok := caps.versions[id]
+	return ok && r.Contains(version)
+}
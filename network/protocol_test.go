@@ -0,0 +1,233 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/network/codec"
+	"github.com/cocher/types/opcode"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type protocolTestComponent struct {
+	*Component
+
+	protocols []Protocol
+}
+
+func (p *protocolTestComponent) Protocols() []Protocol {
+	return p.protocols
+}
+
+// otherProtocolTestComponent is of another type, as the builder keeps a
+// single Component per type.
+type otherProtocolTestComponent struct {
+	protocolTestComponent
+}
+
+func buildWithProtocols(protocols []Protocol, others []Protocol) (*Network, error) {
+	builder := NewBuilder()
+	builder.AddComponent(&protocolTestComponent{protocols: protocols})
+	if others != nil {
+		builder.AddComponent(&otherProtocolTestComponent{protocolTestComponent{protocols: others}})
+	}
+	return builder.Build()
+}
+
+func TestParseProtocol(t *testing.T) {
+	t.Parallel()
+
+	id, version, err := parseProtocol("/cocher/discovery/1.2.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "/cocher/discovery", id)
+	assert.Equal(t, "1.2.0", version.String())
+
+	for _, raw := range []string{"discovery", "/1.0.0", "/cocher/discovery/1.2"} {
+		_, _, err := parseProtocol(raw)
+		assert.NotNilf(t, err, "expected %q to be rejected", raw)
+	}
+}
+
+func TestBuildProtocols(t *testing.T) {
+	t.Parallel()
+
+	ping := Protocol{ID: "/test/ping", Version: "1.1.0", Opcodes: []opcode.Opcode{opcode.PingCode}}
+	lookup := Protocol{ID: "/test/lookup", Version: "0.3.0", Range: ">=0.2.0 <0.4.0"}
+
+	net, err := buildWithProtocols([]Protocol{ping}, []Protocol{lookup})
+	assert.Nil(t, err)
+	// The range defaults to the major version.
+	expected := ping
+	expected.Range = "^1"
+	assert.Equal(t, []Protocol{expected, lookup}, net.Protocols())
+
+	_, err = buildWithProtocols([]Protocol{ping}, []Protocol{ping})
+	assert.EqualError(t, err, errors.Errorf(ErrStrDuplicateProtocol, ping.ID).Error())
+
+	other := Protocol{ID: "/test/other", Version: "1.0.0", Opcodes: []opcode.Opcode{opcode.PingCode}}
+	_, err = buildWithProtocols([]Protocol{ping, other}, nil)
+	assert.EqualError(t, err, errors.Errorf(ErrStrProtocolOpcode, opcode.PingCode, ping.ID, other.ID).Error())
+
+	for _, invalid := range []Protocol{
+		{ID: "", Version: "1.0.0"},
+		{ID: "/test/ping", Version: "1.0"},
+		{ID: "/test/ping", Version: "1.0.0", Range: "^one"},
+	} {
+		_, err = buildWithProtocols([]Protocol{invalid}, nil)
+		assert.NotNilf(t, err, "expected protocol %s to be rejected", invalid)
+	}
+}
+
+func TestSupports(t *testing.T) {
+	t.Parallel()
+
+	net, err := buildWithProtocols([]Protocol{
+		{ID: "/test/ping", Version: "1.1.0", Opcodes: []opcode.Opcode{opcode.PingCode}},
+	}, nil)
+	assert.Nil(t, err)
+
+	client, err := createPeerClient(net, "tcp://127.0.0.1:3000")
+	assert.Nil(t, err)
+
+	// Peers which did not advertise anything are assumed to support everything.
+	assert.Nil(t, client.Supports(&protobuf.Ping{}))
+	assert.True(t, client.SupportsProtocol("/test/ping"))
+
+	testCases := []struct {
+		caps     *protobuf.Capabilities
+		expected *UnsupportedError
+	}{
+		{
+			&protobuf.Capabilities{
+				Opcodes:   []uint32{uint32(opcode.PingCode)},
+				Protocols: []string{"/test/ping/1.4.2"},
+			},
+			nil,
+		},
+		{
+			&protobuf.Capabilities{
+				Opcodes:   []uint32{uint32(opcode.PongCode)},
+				Protocols: []string{"/test/ping/1.4.2"},
+			},
+			&UnsupportedError{Address: client.Address, Opcode: opcode.PingCode},
+		},
+		{
+			&protobuf.Capabilities{
+				Opcodes:   []uint32{uint32(opcode.PingCode)},
+				Protocols: []string{"/test/ping/2.0.0"},
+			},
+			&UnsupportedError{Address: client.Address, Opcode: opcode.PingCode, Protocol: "/test/ping"},
+		},
+		{
+			&protobuf.Capabilities{
+				Opcodes: []uint32{uint32(opcode.PingCode)},
+			},
+			&UnsupportedError{Address: client.Address, Opcode: opcode.PingCode, Protocol: "/test/ping"},
+		},
+		{
+			&protobuf.Capabilities{
+				Opcodes:   []uint32{uint32(opcode.PingCode)},
+				Protocols: []string{"/test/ping/1.4.2"},
+				MessageTypes: []*protobuf.MessageType{
+					{Opcode: uint32(opcode.PingCode), Name: "other.Ping"},
+				},
+			},
+			&UnsupportedError{Address: client.Address, Opcode: opcode.PingCode, Conflict: "other.Ping"},
+		},
+	}
+
+	for _, tt := range testCases {
+		net.handleCapabilities(client, tt.caps)
+
+		err := client.Supports(&protobuf.Ping{})
+		if tt.expected == nil {
+			assert.Nil(t, err)
+			assert.True(t, client.SupportsProtocol("/test/ping"))
+			continue
+		}
+
+		assert.Equal(t, tt.expected, err)
+		assert.True(t, IsUnsupported(errors.Wrap(err, "wrapped")))
+
+		// Unsupported messages fail before anything is sent.
+		assert.True(t, IsUnsupported(client.Tell(context.Background(), &protobuf.Ping{})))
+		_, err = client.Request(context.Background(), &protobuf.Ping{})
+		assert.True(t, IsUnsupported(err))
+	}
+
+	// Messages outside of any protocol only need their opcode to be known.
+	net.handleCapabilities(client, &protobuf.Capabilities{Opcodes: []uint32{uint32(opcode.PongCode)}})
+	assert.Nil(t, client.Supports(&protobuf.Pong{}))
+
+	// Peers which advertised no codecs only decode protobuf.
+	assert.Equal(t, &UnsupportedError{Address: client.Address, Codec: codec.JSONName}, client.SetCodec(codec.JSONName))
+
+	net.handleCapabilities(client, &protobuf.Capabilities{
+		Opcodes: []uint32{uint32(opcode.PongCode)},
+		Codecs:  []string{codec.ProtoName, codec.JSONName},
+	})
+	assert.Nil(t, client.SetCodec(codec.JSONName))
+	assert.Equal(t, codec.JSONName, GetCodec(client.withCodec(context.Background())))
+	assert.Equal(t, codec.ProtoName, GetCodec(client.withCodec(WithCodec(context.Background(), codec.ProtoName))), "codecs set on the context should take precedence")
+}
+
+func TestEnvelopeCodec(t *testing.T) {
+	t.Parallel()
+
+	n, err := NewBuilderWithOptions(EnvelopeCodec(codec.JSON)).Build()
+	assert.Nil(t, err)
+
+	client, err := createPeerClient(n, "tcp://127.0.0.1:3000")
+	assert.Nil(t, err)
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+
+	state := &ConnState{conn: local, writer: bufio.NewWriter(local), writerMutex: new(sync.Mutex)}
+	n.connections.Store(client.Address, state)
+
+	// Peers encoding their envelopes with protobuf get protobuf envelopes.
+	n.handleCapabilities(client, &protobuf.Capabilities{Codecs: []string{codec.ProtoName, codec.JSONName}})
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&state.encodeEnvelopes))
+
+	n.handleCapabilities(client, &protobuf.Capabilities{Codecs: []string{codec.JSONName, codec.ProtoName}})
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&state.encodeEnvelopes))
+
+	id := protobuf.ID(n.GetID())
+	sent := &protobuf.Message{Opcode: uint32(opcode.PingCode), Sender: &id, Message: []byte("ping")}
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- n.sendMessage(local, sent, state.writerMutex, state)
+		errs <- n.sendMessage(local, sent, state.writerMutex, state)
+	}()
+
+	// The envelope is flagged as encoded with JSON.
+	header := make([]byte, 4)
+	_, err = io.ReadFull(remote, header)
+	assert.Nil(t, err)
+	size := binary.BigEndian.Uint32(header)
+	assert.NotEqual(t, uint32(0), size&envelopeFlag)
+
+	raw := make([]byte, size&^envelopeFlag)
+	_, err = io.ReadFull(remote, raw)
+	assert.Nil(t, err)
+	assert.True(t, json.Valid(raw))
+
+	received, err := n.receiveMessage(remote)
+	assert.Nil(t, <-errs)
+	assert.Nil(t, err)
+	assert.Nil(t, <-errs)
+	assert.Equal(t, sent.Message, received.Message)
+	assert.Equal(t, sent.Sender.Address, received.Sender.Address)
+}
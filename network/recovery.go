@@ -0,0 +1,87 @@
+package network
+
+import (
+	"reflect"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/cocher/utils/log"
+)
+
+// PanicPolicy decides what happens after a Component callback, or a job
+// submitted to a peer client, panicked. The panic is always recovered from
+// and logged with its stack trace.
+type PanicPolicy int
+
+const (
+	// ContinueOnPanic carries on as if the callback returned.
+	ContinueOnPanic PanicPolicy = iota
+	// DisconnectOnPanic disconnects the peer the callback was handling.
+	DisconnectOnPanic
+	// RemoveComponentOnPanic stops calling any callback of the Component
+	// which panicked.
+	RemoveComponentOnPanic
+)
+
+// String returns the name of the policy.
+func (p PanicPolicy) String() string {
+	switch p {
+	case ContinueOnPanic:
+		return "continue"
+	case DisconnectOnPanic:
+		return "disconnect"
+	case RemoveComponentOnPanic:
+		return "remove Component"
+	}
+	return "unknown"
+}
+
+// Panics returns the number of panics recovered from since the network was
+// built.
+func (n *Network) Panics() uint64 {
+	return atomic.LoadUint64(&n.panics)
+}
+
+// safely calls a callback of a Component, or a job if info is nil, on behalf
+// of a peer client, which is nil for callbacks not handling a peer. Returns
+// true if the callback panicked.
+func (n *Network) safely(info *ComponentInfo, client *PeerClient, f func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			n.handlePanic(r, info, client)
+		}
+	}()
+
+	f()
+	return false
+}
+
+// handlePanic logs a panic recovered from and applies the panic policy.
+func (n *Network) handlePanic(r interface{}, info *ComponentInfo, client *PeerClient) {
+	atomic.AddUint64(&n.panics, 1)
+
+	source := "job"
+	if info != nil {
+		source = "Component " + reflect.TypeOf(info.Component).String()
+	}
+
+	address := ""
+	if client != nil {
+		address = client.Address
+	}
+
+	log.Errorf("network: recovered from panic in %s handling peer %q: %v\n%s", source, address, r, debug.Stack())
+
+	switch n.opts.panicPolicy {
+	case DisconnectOnPanic:
+		if client != nil {
+			log.Warnf("network: disconnecting peer %s after a panic", client.Address)
+			client.Close()
+		}
+	case RemoveComponentOnPanic:
+		if info != nil && info.remove() {
+			log.Warnf("network: removed %s after a panic", source)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/types/opcode"
+	"github.com/stretchr/testify/assert"
+)
+
+type panicTestComponent struct {
+	*Component
+
+	connects int
+}
+
+func (p *panicTestComponent) Receive(ctx *ComponentContext) error {
+	panic("receive panicked")
+}
+
+func (p *panicTestComponent) Send(ctx *SendContext) error {
+	panic("send panicked")
+}
+
+func (p *panicTestComponent) PeerConnect(client *PeerClient) {
+	p.connects++
+	panic("peer connect panicked")
+}
+
+func buildWithPanics(policy PanicPolicy, calls *[]string) (*Network, *panicTestComponent, error) {
+	component := new(panicTestComponent)
+
+	builder := NewBuilderWithOptions(OnPanic(policy))
+	builder.AddComponent(component)
+	builder.AddComponent(&receiveTestComponent{calls: calls})
+	net, err := builder.Build()
+	return net, component, err
+}
+
+func TestPanicPolicy(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	ping := func(net *Network, client *PeerClient) []string {
+		calls = nil
+		assert.Nil(t, net.routes.handler(opcode.PingCode)(&ComponentContext{client: client, message: &protobuf.Ping{}}))
+		return calls
+	}
+
+	// Panics are recovered from, and the Components after the one which
+	// panicked still run.
+	net, component, err := buildWithPanics(ContinueOnPanic, &calls)
+	assert.Nil(t, err)
+
+	client, err := createPeerClient(net, "tcp://127.0.0.1:3000")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"receive"}, ping(net, client))
+	assert.Equal(t, []string{"receive"}, ping(net, client))
+	client.Init()
+	assert.Equal(t, 1, component.connects)
+	assert.Equal(t, uint64(3), net.Panics())
+
+	// Jobs which panic do not stop the jobs after them.
+	done := make(chan struct{})
+	client.Submit(func() { panic("job panicked") })
+	client.Submit(func() { close(done) })
+	<-done
+	assert.Equal(t, uint64(4), net.Panics())
+
+	// Outgoing messages are sent as if the Send callback returned.
+	send := func(net *Network) error {
+		return net.intercept(&SendContext{ctx: context.Background(), message: &protobuf.Ping{}, addresses: []string{client.Address}})
+	}
+	assert.Nil(t, send(net))
+	assert.Equal(t, uint64(5), net.Panics())
+
+	// Components which panicked are not called any more.
+	net, component, err = buildWithPanics(RemoveComponentOnPanic, &calls)
+	assert.Nil(t, err)
+
+	client, err = createPeerClient(net, "tcp://127.0.0.1:3000")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"receive"}, ping(net, client))
+	client.Init()
+	assert.Equal(t, 0, component.connects)
+	assert.Equal(t, uint64(1), net.Panics())
+
+	// Peers a Component panicked handling are disconnected, and their
+	// message is not passed on.
+	net, _, err = buildWithPanics(DisconnectOnPanic, &calls)
+	assert.Nil(t, err)
+
+	client, err = createPeerClient(net, "tcp://127.0.0.1:3000")
+	assert.Nil(t, err)
+
+	assert.Empty(t, ping(net, client))
+	assert.Equal(t, uint32(1), client.closed)
+
+	// Outgoing messages are vetoed.
+	assert.NotNil(t, send(net))
+}
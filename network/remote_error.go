@@ -0,0 +1,102 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/cocher/internal/protobuf"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorCode classifies an error replied to a request.
+type ErrorCode uint32
+
+const (
+	// ErrorCodeUnknown is the code of errors which were not given one.
+	ErrorCodeUnknown ErrorCode = iota
+	// ErrorCodeInternal is the code of errors due to a bug of the peer, such
+	// as a handler which panicked.
+	ErrorCodeInternal
+	// ErrorCodeInvalidArgument is the code of errors due to a malformed request.
+	ErrorCodeInvalidArgument
+	// ErrorCodeNotFound is the code of errors due to the request referring to
+	// something the peer does not know of.
+	ErrorCodeNotFound
+	// ErrorCodeUnavailable is the code of errors due to the peer being
+	// temporarily unable to handle requests, which may be retried.
+	ErrorCodeUnavailable
+
+	// ErrorCodeApplication is the first of the codes left for applications to
+	// define. Codes below it are reserved.
+	ErrorCodeApplication ErrorCode = 1000
+)
+
+// RemoteError is returned by Request when the peer replied to the request
+// with an error instead of a message. Handlers may return one, or reply with
+// one with ReplyError, to set the code and details of the error replied.
+type RemoteError struct {
+	// Address of the peer which replied with the error, empty until it is
+	// received.
+	Address string
+	// Code classifies the error.
+	Code ErrorCode
+	// Message describes the error.
+	Message string
+	// Details of the error, keyed by name.
+	Details map[string]string
+}
+
+// NewRemoteError returns an error to reply to a request with.
+func NewRemoteError(code ErrorCode, message string) *RemoteError {
+	return &RemoteError{Code: code, Message: message}
+}
+
+// WithDetail adds a detail to the error, and returns the error.
+func (e *RemoteError) WithDetail(key string, value string) *RemoteError {
+	if e.Details == nil {
+		e.Details = make(map[string]string)
+	}
+	e.Details[key] = value
+	return e
+}
+
+func (e *RemoteError) Error() string {
+	if e.Address != "" {
+		return fmt.Sprintf("network: peer %s replied with error %d: %s", e.Address, e.Code, e.Message)
+	}
+	return fmt.Sprintf("network: error %d: %s", e.Code, e.Message)
+}
+
+// AsRemoteError returns the error a peer replied with if it is the cause of
+// an error.
+func AsRemoteError(err error) (*RemoteError, bool) {
+	remote, ok := errors.Cause(err).(*RemoteError)
+	return remote, ok
+}
+
+// unknownErrorMessage is the message errors which are no RemoteError are
+// replied with, as their text may tell peers about the internals of the node.
+const unknownErrorMessage = "request failed"
+
+// toErrorReply turns an error into the envelope replied to a request with.
+// Errors which are no RemoteError are replied with ErrorCodeUnknown and a
+// generic message.
+func toErrorReply(err error) *protobuf.ErrorReply {
+	remote, ok := AsRemoteError(err)
+	if !ok {
+		return &protobuf.ErrorReply{Code: uint32(ErrorCodeUnknown), Message: unknownErrorMessage}
+	}
+
+	return &protobuf.ErrorReply{Code: uint32(remote.Code), Message: remote.Message, Details: remote.Details}
+}
+
+// fromErrorReply turns an envelope a peer replied to a request with into an
+// error.
+func fromErrorReply(address string, reply *protobuf.ErrorReply) *RemoteError {
+	return &RemoteError{
+		Address: address,
+		Code:    ErrorCode(reply.Code),
+		Message: reply.Message,
+		Details: reply.Details,
+	}
+}
@@ -0,0 +1,391 @@
+package network
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// defaultStreamWindow is the number of replies to a streaming request the
+// requester buffers, unless set with WithStreamWindow.
+const defaultStreamWindow = 16
+
+var (
+	// ErrStreamClosed is returned sending a reply to a stream which was closed.
+	ErrStreamClosed = errors.New("network: stream was closed")
+	// ErrStreamCanceled is returned sending a reply to a stream the requester
+	// canceled or disconnected from.
+	ErrStreamCanceled = errors.New("network: stream was canceled by the requester")
+)
+
+// ReplyStream receives the replies to a streaming request. The responder
+// sends no more replies than the stream buffers, and is granted more as
+// replies are received.
+type ReplyStream struct {
+	client *PeerClient
+	nonce  uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	data   chan interface{}
+
+	// number of replies the stream buffers
+	window uint32
+	// number of replies received since credits were last granted
+	received uint32
+	// set once the responder ended the stream
+	ended uint32
+	// error Recv returns once the stream ended
+	err error
+}
+
+// RequestStream sends a streaming request to a peer, the replies of which are
+// received from the stream returned until the responder ends it. Canceling
+// the context, or closing the stream, asks the responder to stop replying.
+func (c *PeerClient) RequestStream(ctx context.Context, req interface{}) (*ReplyStream, error) {
+	if ctx == nil {
+		return nil, errors.New("network: invalid context")
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	sctx := &SendContext{ctx: c.withCodec(ctx), message: req, addresses: []string{c.Address}, request: true}
+	if err := c.Network.intercept(sctx); err != nil {
+		return nil, err
+	}
+
+	if len(sctx.addresses) != 1 {
+		return nil, errors.Errorf("network: request must be sent to exactly one peer, not %d", len(sctx.addresses))
+	}
+
+	clients, err := c.Network.clients(sctx, c)
+	if err != nil {
+		return nil, err
+	}
+	// The replies are awaited from the peer the request was rerouted to.
+	target := clients[0]
+
+	// Peers which do not know of streams would reply only once.
+	if err := target.Supports(&protobuf.StreamControl{}); err != nil {
+		return nil, err
+	}
+
+	signed, err := c.Network.PrepareMessage(sctx.ctx, sctx.message)
+	if err != nil {
+		return nil, err
+	}
+
+	window := GetStreamWindow(ctx)
+
+	signed.RequestNonce = atomic.AddUint64(&target.RequestNonce, 1)
+	signed.StreamWindow = window
+	setTimeout(ctx, signed)
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream := &ReplyStream{
+		client: target,
+		nonce:  signed.RequestNonce,
+		ctx:    ctx,
+		cancel: cancel,
+		// room for the end of the stream besides the replies
+		data:   make(chan interface{}, window+1),
+		window: window,
+	}
+
+	closeSignal := make(chan struct{})
+	target.Requests.Store(signed.RequestNonce, &RequestState{
+		data:        stream.data,
+		closeSignal: closeSignal,
+	})
+
+	// Stop tracking the request once the stream ended or was canceled.
+	go func() {
+		<-ctx.Done()
+
+		target.Requests.Delete(stream.nonce)
+		close(closeSignal)
+
+		if atomic.LoadUint32(&stream.ended) == 0 {
+			target.sendStreamControl(stream.nonce, false, &protobuf.StreamControl{Cancel: true})
+		}
+	}()
+
+	if err := c.Network.Write(target.Address, signed); err != nil {
+		stream.finish(err)
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// Recv returns the next reply to the request, io.EOF once the responder ended
+// the stream, or a *RemoteError if it ended it with an error. Recv must not
+// be called concurrently.
+func (s *ReplyStream) Recv() (interface{}, error) {
+	// Replies buffered are dropped once the stream was closed.
+	if s.err == nil && s.ctx.Err() != nil {
+		s.err = s.ctx.Err()
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	select {
+	case res := <-s.data:
+		switch reply := res.(type) {
+		case *protobuf.StreamControl:
+			if reply.End {
+				s.finish(io.EOF)
+				return nil, io.EOF
+			}
+		case *protobuf.ErrorReply:
+			err := fromErrorReply(s.client.Address, reply)
+			s.finish(err)
+			return nil, err
+		}
+
+		// Grant the responder room for the replies received once half of
+		// the window was received.
+		if s.received++; s.received >= (s.window+1)/2 {
+			s.client.sendStreamControl(s.nonce, false, &protobuf.StreamControl{Credits: s.received})
+			s.received = 0
+		}
+
+		return res, nil
+	case <-s.ctx.Done():
+		s.err = s.ctx.Err()
+		return nil, s.err
+	}
+}
+
+// Close stops receiving replies, and asks the responder to stop replying if
+// it did not end the stream yet.
+func (s *ReplyStream) Close() {
+	s.cancel()
+}
+
+// finish ends the stream with the error Recv returns from then on.
+func (s *ReplyStream) finish(err error) {
+	atomic.StoreUint32(&s.ended, 1)
+	s.err = err
+	s.cancel()
+}
+
+// StreamWriter sends the replies to a streaming request, taken from the
+// ComponentContext of the request with Stream. Replies may be sent from
+// any goroutine until the stream is closed.
+type StreamWriter struct {
+	client *PeerClient
+	nonce  uint64
+
+	// context of the request, canceled once the stream was closed
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex sync.Mutex
+	// number of replies the requester can buffer
+	credits uint32
+	// error sending replies returns once the stream was closed or canceled
+	err error
+
+	// signaled when credits are granted
+	granted chan struct{}
+	// closed once the stream was closed or canceled
+	done chan struct{}
+}
+
+func newStreamWriter(ctx context.Context, cancel context.CancelFunc, client *PeerClient, nonce uint64, window uint32) *StreamWriter {
+	w := &StreamWriter{
+		client:  client,
+		nonce:   nonce,
+		ctx:     ctx,
+		cancel:  cancel,
+		credits: window,
+		granted: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	client.streams.Store(nonce, w)
+	return w
+}
+
+// Send sends a reply, waiting for the requester to have room for it.
+// Returns ErrStreamCanceled once the requester canceled the stream.
+func (w *StreamWriter) Send(ctx context.Context, message interface{}) error {
+	for {
+		w.mutex.Lock()
+		if w.err != nil {
+			w.mutex.Unlock()
+			return w.err
+		}
+		if w.credits > 0 {
+			w.credits--
+			w.mutex.Unlock()
+			break
+		}
+		w.mutex.Unlock()
+
+		select {
+		case <-w.granted:
+		case <-w.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return w.client.Reply(ctx, w.nonce, message)
+}
+
+// Close ends the stream after the replies sent.
+func (w *StreamWriter) Close(ctx context.Context) error {
+	if !w.finish(ErrStreamClosed) {
+		return nil
+	}
+	return w.client.sendStreamControl(w.nonce, true, &protobuf.StreamControl{End: true})
+}
+
+// CloseWithError ends the stream with an error, which the requester's Recv
+// returns as a *RemoteError.
+func (w *StreamWriter) CloseWithError(ctx context.Context, err error) error {
+	if !w.finish(ErrStreamClosed) {
+		return nil
+	}
+	return w.client.Reply(ctx, w.nonce, toErrorReply(err))
+}
+
+// Done returns a channel closed once the stream was closed or canceled.
+func (w *StreamWriter) Done() <-chan struct{} {
+	return w.done
+}
+
+// Context returns the context of the request, which is done once the
+// requester canceled the stream, its deadline passed or the stream was closed.
+func (w *StreamWriter) Context() context.Context {
+	return w.ctx
+}
+
+// finish stops the stream from sending replies. Returns false if it was
+// stopped already.
+func (w *StreamWriter) finish(err error) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.err != nil {
+		return false
+	}
+	w.err = err
+	close(w.done)
+	w.client.streams.Delete(w.nonce)
+	w.cancel()
+	return true
+}
+
+// grant gives the responder room for more replies.
+func (w *StreamWriter) grant(credits uint32) {
+	w.mutex.Lock()
+	w.credits += credits
+	w.mutex.Unlock()
+
+	select {
+	case w.granted <- struct{}{}:
+	default:
+	}
+}
+
+// handleStreamControl handles credits granted to the streams replying to the
+// requests of a peer, and cancellations of the requests.
+func (c *PeerClient) handleStreamControl(nonce uint64, msg *protobuf.StreamControl) {
+	if msg.Cancel {
+		if cancel, ok := c.handling.Load(nonce); ok {
+			cancel.(context.CancelFunc)()
+		}
+	}
+
+	value, ok := c.streams.Load(nonce)
+	if !ok {
+		return
+	}
+	w := value.(*StreamWriter)
+
+	if msg.Cancel {
+		w.finish(ErrStreamCanceled)
+		return
+	}
+	if msg.Credits > 0 {
+		w.grant(msg.Credits)
+	}
+}
+
+// cancelRequests cancels the handling of the requests of a peer, and the
+// streams replying to them.
+func (c *PeerClient) cancelRequests() {
+	c.handling.Range(func(key, value interface{}) bool {
+		value.(context.CancelFunc)()
+		return true
+	})
+	c.streams.Range(func(key, value interface{}) bool {
+		value.(*StreamWriter).finish(ErrStreamCanceled)
+		return true
+	})
+}
+
+// requestContext returns the context a request of a peer is handled with,
+// which is done once the deadline of the request passed, the peer canceled
+// it or the cancel function returned is called.
+func (c *PeerClient) requestContext(nonce uint64, timeout int64) (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(timeout))
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	c.handling.Store(nonce, cancel)
+	return ctx, func() {
+		c.handling.Delete(nonce)
+		cancel()
+	}
+}
+
+// cancelRequest asks a peer to stop handling a request it did not reply to,
+// if it knows how to.
+func (c *PeerClient) cancelRequest(nonce uint64) {
+	if c.Supports(&protobuf.StreamControl{}) == nil {
+		c.sendStreamControl(nonce, false, &protobuf.StreamControl{Cancel: true})
+	}
+}
+
+// setTimeout sets the time left until the deadline of a request in its
+// envelope.
+func setTimeout(ctx context.Context, signed *protobuf.Message) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if timeout := time.Until(deadline); timeout > 0 {
+			signed.Timeout = int64(timeout)
+		}
+	}
+}
+
+// sendStreamControl writes a control frame of a request to the peer. Control frames are not passed to outbound hooks.
+func (c *PeerClient) sendStreamControl(nonce uint64, reply bool, msg *protobuf.StreamControl) error {
+	signed, err := c.Network.PrepareMessage(context.Background(), msg)
+	if err != nil {
+		return err
+	}
+	signed.RequestNonce = nonce
+	signed.ReplyFlag = reply
+
+	if err := c.Network.Write(c.Address, signed); err != nil {
+		log.Warnf("network: failed to send stream control to peer %s: %v", c.Address, err)
+		return err
+	}
+	return nil
+}
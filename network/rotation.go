@@ -0,0 +1,258 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/cocher/crypto"
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/peer"
+	"github.com/cocher/utils/log"
+	"github.com/pkg/errors"
+)
+
+// successionDomain separates succession record signatures from message signatures.
+var successionDomain = []byte("cocher/key-succession")
+
+// maxSuccessionClockSkew is how far in the future the timestamp of a succession
+// record may be, to allow for clocks of peers running ahead.
+const maxSuccessionClockSkew = 30 * time.Second
+
+// succession tracks a node that rotated its keys, until its grace period expires.
+type succession struct {
+	successor peer.ID
+	expires   time.Time
+}
+
+// SerializeSuccession packs the fields of a succession record signed by both keys.
+func SerializeSuccession(record *protobuf.KeySuccession) []byte {
+	timestamp := make([]byte, 8)
+	binary.LittleEndian.PutUint64(timestamp, uint64(record.Timestamp))
+
+	successor := SerializeMessage(record.Successor, timestamp)
+	return SerializeMessage(record.Previous, append(append([]byte{}, successionDomain...), successor...))
+}
+
+// VerifySuccession checks that a succession record is signed by both the
+// previous and the successor key, and that both IDs match their keys.
+func VerifySuccession(sp crypto.SignaturePolicy, hp crypto.HashPolicy, record *protobuf.KeySuccession) error {
+	if record.Previous == nil || record.Successor == nil {
+		return errors.New("network: succession record is missing an ID")
+	}
+
+	if !bytes.Equal(record.Previous.Id, hp.HashBytes(record.Previous.NetKey)) ||
+		!bytes.Equal(record.Successor.Id, hp.HashBytes(record.Successor.NetKey)) {
+		return errors.New("network: succession record IDs do not match their keys")
+	}
+
+	if bytes.Equal(record.Previous.Id, record.Successor.Id) {
+		return errors.New("network: succession record does not change the key")
+	}
+
+	serialized := SerializeSuccession(record)
+
+	if !crypto.Verify(sp, hp, record.Previous.NetKey, serialized, record.Signature) {
+		return errors.New("network: succession record has a malformed signature of the previous key")
+	}
+
+	if !crypto.Verify(sp, hp, record.Successor.NetKey, serialized, record.SuccessorSignature) {
+		return errors.New("network: succession record has a malformed signature of the successor key")
+	}
+
+	return nil
+}
+
+// RotateKeys replaces the keys and ID of this node. A succession record signed
+// by both the current and the new keys is sent to every connected peer, which
+// update their state to the new ID in place and pass the record on. RotateKeys
+// waits for every connected peer to acknowledge the record or for ctx to be
+// done, whichever comes first, before switching to the new keys.
+func (n *Network) RotateKeys(ctx context.Context, keys *crypto.KeyPair) error {
+	if len(keys.PrivateKey) != n.opts.signaturePolicy.PrivateKeySize() ||
+		len(keys.PublicKey) != n.opts.signaturePolicy.PublicKeySize() {
+		return errors.New(ErrStrKeyPairPolicy)
+	}
+
+	n.identityMutex.RLock()
+	previous, previousKeys := n.id, n.keys
+	n.identityMutex.RUnlock()
+
+	successor := peer.CreateIDWithHashPolicy(previous.Address, keys.PublicKey, n.opts.hashPolicy)
+
+	previousID, successorID := protobuf.ID(previous), protobuf.ID(successor)
+	record := &protobuf.KeySuccession{
+		Previous:  &previousID,
+		Successor: &successorID,
+		Timestamp: time.Now().UnixNano(),
+	}
+
+	var err error
+	serialized := SerializeSuccession(record)
+
+	record.Signature, err = previousKeys.Sign(n.opts.signaturePolicy, n.opts.hashPolicy, serialized)
+	if err != nil {
+		return err
+	}
+
+	record.SuccessorSignature, err = keys.Sign(n.opts.signaturePolicy, n.opts.hashPolicy, serialized)
+	if err != nil {
+		return err
+	}
+
+	// Announce the record while still using the previous keys, so that peers
+	// accept it from the ID they know.
+	var wait sync.WaitGroup
+
+	n.EachPeer(func(client *PeerClient) bool {
+		wait.Add(1)
+
+		go func() {
+			defer wait.Done()
+
+			if _, err := client.Request(ctx, record); err != nil {
+				log.Warnf("network: peer %s did not acknowledge key succession: %v", client.Address, err)
+			}
+		}()
+
+		return true
+	})
+
+	wait.Wait()
+
+	n.identityMutex.Lock()
+	n.keys = keys
+	n.id = successor
+	n.identityMutex.Unlock()
+
+	n.successions.Store(previous.PublicKeyHex(), &succession{
+		successor: successor,
+		expires:   time.Now().Add(n.opts.rotationGracePeriod),
+	})
+
+	n.notifyRotation(previous, successor)
+
+	// Addresses signed by the previous key no longer match the ID.
+	n.EachPeer(func(client *PeerClient) bool {
+		go n.announceAddresses(client)
+		return true
+	})
+
+	log.Infof("Rotated keys from %s to %s.", previous.PublicKeyHex(), successor.PublicKeyHex())
+
+	return nil
+}
+
+// Successor returns the ID a peer rotated its keys to, if the grace period of
+// the rotation has not expired yet.
+func (n *Network) Successor(id peer.ID) (peer.ID, bool) {
+	value, ok := n.successions.Load(id.PublicKeyHex())
+	if !ok {
+		return peer.ID{}, false
+	}
+
+	s := value.(*succession)
+	if time.Now().After(s.expires) {
+		n.successions.Delete(id.PublicKeyHex())
+		return peer.ID{}, false
+	}
+
+	return s.successor, true
+}
+
+// acceptsSender returns true if a message from sender may be received over a
+// connection to client, which is the case for its current ID and, during the
+// grace period of a rotation, the ID it rotated from.
+func (n *Network) acceptsSender(client *PeerClient, sender peer.ID) bool {
+	if client.ID().Equals(sender) {
+		return true
+	}
+
+	successor, ok := n.Successor(sender)
+	return ok && successor.Equals(*client.ID())
+}
+
+// handleSuccession applies a succession record received from client, passes it
+// on to all other peers and acknowledges it if it was sent as a request.
+func (n *Network) handleSuccession(client *PeerClient, record *protobuf.KeySuccession, nonce uint64) {
+	err := VerifySuccession(n.opts.signaturePolicy, n.opts.hashPolicy, record)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	received := time.Now()
+	created := time.Unix(0, record.Timestamp)
+	if received.Sub(created) > n.opts.rotationGracePeriod {
+		log.Errorf("network: succession record of %s expired", peer.ID(*record.Previous).PublicKeyHex())
+		return
+	}
+	if created.Sub(received) > maxSuccessionClockSkew {
+		log.Errorf("network: succession record of %s is timestamped in the future", peer.ID(*record.Previous).PublicKeyHex())
+		return
+	}
+
+	previous, successor := peer.ID(*record.Previous), peer.ID(*record.Successor)
+
+	// Our own record may come back around through other peers.
+	if previous.Equals(n.GetID()) {
+		return
+	}
+
+	// The grace period runs from the time the record was received, so that a
+	// timestamp set ahead can not extend it.
+	s := &succession{successor: successor, expires: received.Add(n.opts.rotationGracePeriod)}
+	if _, seen := n.successions.LoadOrStore(previous.PublicKeyHex(), s); !seen {
+		n.applySuccession(client, record, previous, successor)
+	}
+
+	if nonce > 0 {
+		if err := client.Reply(context.Background(), nonce, record); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+func (n *Network) applySuccession(from *PeerClient, record *protobuf.KeySuccession, previous peer.ID, successor peer.ID) {
+	n.successions.Range(func(key, value interface{}) bool {
+		if time.Now().After(value.(*succession).expires) {
+			n.successions.Delete(key)
+		}
+		return true
+	})
+
+	n.peerStore.Remove(previous)
+
+	var forward []*PeerClient
+
+	n.EachPeer(func(client *PeerClient) bool {
+		if id := client.ID(); id != nil && id.Equals(previous) {
+			client.setID(successor)
+		} else if client != from {
+			forward = append(forward, client)
+		}
+		return true
+	})
+
+	n.notifyRotation(previous, successor)
+
+	for _, client := range forward {
+		if err := client.Tell(context.Background(), record); err != nil {
+			log.Warnf("network: failed to pass on key succession to %s: %v", client.Address, err)
+		}
+	}
+
+	log.Infof("Peer %s rotated its keys to %s.", previous.PublicKeyHex(), successor.PublicKeyHex())
+}
+
+func (n *Network) notifyRotation(previous peer.ID, successor peer.ID) {
+	n.Components.eachInfo(func(info *ComponentInfo) {
+		if handler, ok := info.Component.(RotationHandler); ok {
+			n.safely(info, nil, func() {
+				handler.Rotate(n, previous, successor)
+			})
+		}
+	})
+}
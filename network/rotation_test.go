@@ -0,0 +1,123 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cocher/crypto/blake2b"
+	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSuccession(t *testing.T, timestamp time.Time) *protobuf.KeySuccession {
+	sp, hp := ed25519.New(), blake2b.New()
+	previousKeys, successorKeys := sp.RandomKeyPair(), sp.RandomKeyPair()
+
+	previous := protobuf.ID(peer.CreateID("tcp://localhost:1000", previousKeys.PublicKey))
+	successor := protobuf.ID(peer.CreateID("tcp://localhost:1000", successorKeys.PublicKey))
+
+	record := &protobuf.KeySuccession{Previous: &previous, Successor: &successor, Timestamp: timestamp.UnixNano()}
+
+	var err error
+	record.Signature, err = previousKeys.Sign(sp, hp, SerializeSuccession(record))
+	assert.Nil(t, err)
+	record.SuccessorSignature, err = successorKeys.Sign(sp, hp, SerializeSuccession(record))
+	assert.Nil(t, err)
+
+	return record
+}
+
+func TestVerifySuccession(t *testing.T) {
+	t.Parallel()
+
+	sp, hp := ed25519.New(), blake2b.New()
+
+	record := newTestSuccession(t, time.Now())
+	assert.Nil(t, VerifySuccession(sp, hp, record))
+
+	// the record must be signed by the previous key
+	tampered := *record
+	tampered.Signature = record.SuccessorSignature
+	assert.NotNil(t, VerifySuccession(sp, hp, &tampered))
+
+	// the successor must prove possession of its key
+	tampered = *record
+	tampered.SuccessorSignature = record.Signature
+	assert.NotNil(t, VerifySuccession(sp, hp, &tampered))
+
+	// the signatures cover the timestamp
+	tampered = *record
+	tampered.Timestamp++
+	assert.NotNil(t, VerifySuccession(sp, hp, &tampered))
+
+	// IDs must be derived from their keys
+	successor := *record.Successor
+	successor.Id = record.Previous.Id
+	tampered = *record
+	tampered.Successor = &successor
+	assert.NotNil(t, VerifySuccession(sp, hp, &tampered))
+
+	tampered = *record
+	tampered.Previous = nil
+	assert.NotNil(t, VerifySuccession(sp, hp, &tampered))
+}
+
+func TestAcceptsSender(t *testing.T) {
+	t.Parallel()
+
+	net, err := NewBuilderWithOptions(RotationGracePeriod(50 * time.Millisecond)).Build()
+	assert.Nil(t, err)
+
+	record := newTestSuccession(t, time.Now())
+	previous, successor := peer.ID(*record.Previous), peer.ID(*record.Successor)
+
+	client := &PeerClient{id: &successor}
+	assert.True(t, net.acceptsSender(client, successor))
+	assert.False(t, net.acceptsSender(client, previous), "previous ID is unknown before the rotation")
+
+	net.successions.Store(previous.PublicKeyHex(), &succession{
+		successor: successor,
+		expires:   time.Now().Add(net.opts.rotationGracePeriod),
+	})
+	assert.True(t, net.acceptsSender(client, previous), "previous ID is accepted during the grace period")
+
+	other := peer.CreateID("tcp://localhost:1001", ed25519.RandomKeyPair().PublicKey)
+	assert.False(t, net.acceptsSender(&PeerClient{id: &other}, previous), "previous ID belongs to another peer")
+
+	time.Sleep(2 * net.opts.rotationGracePeriod)
+	assert.False(t, net.acceptsSender(client, previous), "previous ID is rejected after the grace period")
+
+	_, ok := net.Successor(previous)
+	assert.False(t, ok)
+}
+
+func TestHandleSuccessionTimestamp(t *testing.T) {
+	t.Parallel()
+
+	net, err := NewBuilderWithOptions(RotationGracePeriod(time.Minute)).Build()
+	assert.Nil(t, err)
+	client := &PeerClient{Network: net}
+
+	for _, timestamp := range []time.Time{
+		time.Now().Add(-2 * time.Minute),
+		time.Now().Add(time.Hour),
+	} {
+		record := newTestSuccession(t, timestamp)
+		net.handleSuccession(client, record, 0)
+
+		_, ok := net.Successor(peer.ID(*record.Previous))
+		assert.False(t, ok, "record timestamped %s should be rejected", timestamp)
+	}
+
+	// Clocks running slightly ahead are tolerated, but the grace period runs
+	// from the time the record was received.
+	record := newTestSuccession(t, time.Now().Add(maxSuccessionClockSkew/2))
+	net.handleSuccession(client, record, 0)
+
+	value, ok := net.successions.Load(peer.ID(*record.Previous).PublicKeyHex())
+	if assert.True(t, ok) {
+		assert.False(t, value.(*succession).expires.After(time.Now().Add(net.opts.rotationGracePeriod)))
+	}
+}
@@ -0,0 +1,349 @@
+package network
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/cocher/types/opcode"
+	"github.com/cocher/utils/log"
+
+	"github.com/pkg/errors"
+)
+
+// HandlerFunc handles a received message.
+type HandlerFunc func(ctx *ComponentContext) error
+
+// MiddlewareFunc wraps the handling of a received message by all handlers
+// after it. Calling next passes the message on, and not calling it stops the
+// message from being processed any further.
+type MiddlewareFunc func(ctx *ComponentContext, next HandlerFunc) error
+
+// ErrStopPropagation may be returned by a handler to stop the message from
+// being passed to the handlers after it. It is not logged.
+var ErrStopPropagation = errors.New("network: stop propagation of message")
+
+var (
+	contextType = reflect.TypeOf((*ComponentContext)(nil))
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// route is a handler or middleware along with the position it runs at.
+type route struct {
+	priority int
+	// order in which routes were added, to keep routes of the same priority
+	// in the order they were added
+	seq        int
+	handler    HandlerFunc
+	middleware MiddlewareFunc
+	// Component which registered the route, if any
+	info *ComponentInfo
+	// whether the route is the Receive callback of a Component, which sees
+	// all messages and so does not fail the requests it can not handle
+	receive bool
+}
+
+// link returns a handler running the route, then the handlers after it.
+// Routes which panic are recovered from according to the panic policy of
+// the network, and skipped once their Component is removed.
+func (r route) link(net *Network, next HandlerFunc) HandlerFunc {
+	info := r.info
+
+	if middleware := r.middleware; middleware != nil {
+		return func(ctx *ComponentContext) (err error) {
+			if info != nil && info.isRemoved() {
+				return next(ctx)
+			}
+			if net.safely(info, ctx.client, func() {
+				err = middleware(ctx, next)
+			}) {
+				ctx.fail(NewRemoteError(ErrorCodeInternal, "handler panicked"))
+			}
+			return err
+		}
+	}
+
+	handler, receive := r.handler, r.receive
+	return func(ctx *ComponentContext) error {
+		if info != nil && info.isRemoved() {
+			return next(ctx)
+		}
+
+		var err error
+		if net.safely(info, ctx.client, func() { err = handler(ctx) }) {
+			if !receive {
+				ctx.fail(NewRemoteError(ErrorCodeInternal, "handler panicked"))
+			}
+			if net.opts.panicPolicy == DisconnectOnPanic {
+				return nil
+			}
+		}
+
+		if err != nil {
+			if errors.Cause(err) == ErrStopPropagation {
+				return nil
+			}
+			log.Errorf("%+v", err)
+			if !receive {
+				ctx.fail(err)
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// chain links routes into a single handler running them in order.
+func (t *routeTable) chain(routes []route) HandlerFunc {
+	next := HandlerFunc(func(ctx *ComponentContext) error { return nil })
+	for i := len(routes) - 1; i >= 0; i-- {
+		next = routes[i].link(t.net, next)
+	}
+	return next
+}
+
+func sortRoutes(routes []route) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].priority != routes[j].priority {
+			return routes[i].priority < routes[j].priority
+		}
+		return routes[i].seq < routes[j].seq
+	})
+}
+
+// routeTable holds the handlers of all message types of a network.
+type routeTable struct {
+	net *Network
+
+	mutex sync.RWMutex
+	seq   int
+	// map of opcodes to the handlers of their message type only
+	typed map[opcode.Opcode][]route
+	// handlers and middleware of all messages, e.g. the Receive callbacks
+	// of Components which do not register handlers per message type
+	fallback []route
+	// map of opcodes to the chain of all handlers of their message type,
+	// including the fallback handlers
+	routes map[opcode.Opcode]HandlerFunc
+	// chain of the fallback handlers, for opcodes without handlers of their own
+	fallbackChain HandlerFunc
+}
+
+func newRouteTable(net *Network) *routeTable {
+	t := &routeTable{
+		net:    net,
+		typed:  make(map[opcode.Opcode][]route),
+		routes: make(map[opcode.Opcode]HandlerFunc),
+	}
+	t.fallbackChain = t.chain(nil)
+	return t
+}
+
+// add adds a route for the messages of an opcode, or for all messages if
+// all is true, and recomputes the chains affected.
+func (t *routeTable) add(code opcode.Opcode, all bool, r route) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	r.seq = t.seq
+	t.seq++
+
+	if all {
+		t.fallback = append(t.fallback, r)
+		sortRoutes(t.fallback)
+
+		t.fallbackChain = t.chain(t.fallback)
+		for code := range t.typed {
+			t.merge(code)
+		}
+		return
+	}
+
+	t.typed[code] = append(t.typed[code], r)
+	t.merge(code)
+}
+
+// merge recomputes the chain of an opcode.
+func (t *routeTable) merge(code opcode.Opcode) {
+	routes := append(append([]route{}, t.typed[code]...), t.fallback...)
+	sortRoutes(routes)
+	t.routes[code] = t.chain(routes)
+}
+
+// handler returns the chain of handlers of the messages of an opcode.
+func (t *routeTable) handler(code opcode.Opcode) HandlerFunc {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if handler, ok := t.routes[code]; ok {
+		return handler
+	}
+	return t.fallbackChain
+}
+
+// Router registers handlers per message type. Received messages are only
+// passed to the handlers of their type, looked up by opcode.
+//
+// Handlers of the same message type run one after the other in ascending
+// order of priority, and in the order they were registered if their priority
+// is the same. They run interleaved with the Receive callback of Components
+// which do not implement RouteProvider, by the priorities of the Components.
+// Middleware wraps all handlers of a lower priority, and may stop messages
+// from reaching them.
+type Router struct {
+	table *routeTable
+	// priority of handlers registered without one
+	priority int
+	// Component registering handlers, if any
+	info *ComponentInfo
+}
+
+// Handle registers a handler for the messages of the type msg points to,
+// which must be registered in the message registry of the network. The
+// handler is either a HandlerFunc, or a func(*ComponentContext, *MyMsg) error
+// which receives the message already cast to its type, e.g.
+//
+//	router.Handle(&MyMsg{}, func(ctx *network.ComponentContext, msg *MyMsg) error {
+//		return ctx.Reply(context.Background(), &MyReply{})
+//	})
+//
+// Handlers registered by a Component in RegisterHandlers have the priority of
+// the Component.
+func (r *Router) Handle(msg interface{}, handler interface{}) error {
+	return r.HandleWithPriority(r.priority, msg, handler)
+}
+
+// HandleWithPriority is like Handle, with the priority of the handler among
+// all handlers of the message type given.
+func (r *Router) HandleWithPriority(priority int, msg interface{}, handler interface{}) error {
+	code, err := r.table.net.opts.registry.GetOpcode(msg)
+	if err != nil {
+		return errors.Wrapf(err, "network: can not handle messages of type %T", msg)
+	}
+
+	f, err := wrapHandler(reflect.TypeOf(msg), handler)
+	if err != nil {
+		return err
+	}
+
+	r.table.add(code, false, route{priority: priority, handler: f, info: r.info})
+	return nil
+}
+
+// Registry returns the message registry of the network, which the types of
+// the messages handled must be registered in.
+func (r *Router) Registry() *opcode.Registry {
+	return r.table.net.opts.registry
+}
+
+// Use registers middleware wrapping the handling of all received messages by
+// handlers after it. Middleware registered by a Component in RegisterHandlers
+// has the priority of the Component.
+func (r *Router) Use(middleware MiddlewareFunc) {
+	r.UseWithPriority(r.priority, middleware)
+}
+
+// UseWithPriority is like Use, with the priority of the middleware among all
+// handlers given.
+func (r *Router) UseWithPriority(priority int, middleware MiddlewareFunc) {
+	r.table.add(opcode.UnregisteredCode, true, route{priority: priority, middleware: middleware, info: r.info})
+}
+
+// wrapHandler turns a typed handler into a HandlerFunc.
+func wrapHandler(msgType reflect.Type, handler interface{}) (HandlerFunc, error) {
+	switch f := handler.(type) {
+	case HandlerFunc:
+		return f, nil
+	case func(ctx *ComponentContext) error:
+		return f, nil
+	}
+
+	fn := reflect.ValueOf(handler)
+	if !fn.IsValid() || fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, errors.Errorf("network: handler of %s must be a function, not %T", msgType, handler)
+	}
+
+	ty := fn.Type()
+	if ty.NumIn() != 2 || ty.In(0) != contextType || !msgType.AssignableTo(ty.In(1)) ||
+		ty.NumOut() != 1 || ty.Out(0) != errorType || ty.IsVariadic() {
+		return nil, errors.Errorf("network: handler of %s must be a func(*ComponentContext, %s) error, not %s", msgType, msgType, ty)
+	}
+
+	return func(ctx *ComponentContext) error {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(ctx.Message())})
+		err, _ := out[0].Interface().(error)
+		return err
+	}, nil
+}
+
+// Router returns the router of the network, to register handlers per
+// message type outside of Components.
+func (n *Network) Router() *Router {
+	return &Router{table: n.routes}
+}
+
+// newRoutes registers the handlers of Components.
+func newRoutes(net *Network, components *ComponentList) (*routeTable, error) {
+	table := newRouteTable(net)
+
+	var err error
+	components.eachInfo(func(info *ComponentInfo) {
+		if err == nil {
+			err = table.register(info)
+		}
+	})
+
+	return table, err
+}
+
+// register registers the handlers of a Component. Components which implement
+// Middleware wrap the Components after them, Components which implement
+// RouteProvider register handlers per message type, and all other Components
+// receive every message through Receive.
+func (t *routeTable) register(info *ComponentInfo) error {
+	if middleware, ok := info.Component.(Middleware); ok {
+		t.add(opcode.UnregisteredCode, true, route{priority: info.Priority, middleware: middleware.Intercept, info: info})
+		return nil
+	}
+
+	provider, ok := info.Component.(RouteProvider)
+	if !ok {
+		t.add(opcode.UnregisteredCode, true, route{priority: info.Priority, handler: info.Component.Receive, info: info, receive: true})
+		return nil
+	}
+
+	if err := provider.RegisterHandlers(&Router{table: t, priority: info.Priority, info: info}); err != nil {
+		t.remove(info)
+		return errors.Wrapf(err, "network: failed to register handlers of Component %s", reflect.TypeOf(info.Component))
+	}
+
+	return nil
+}
+
+// remove removes all handlers and middleware a Component registered.
+func (t *routeTable) remove(info *ComponentInfo) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	keep := func(routes []route) []route {
+		kept := make([]route, 0, len(routes))
+		for _, r := range routes {
+			if r.info != info {
+				kept = append(kept, r)
+			}
+		}
+		return kept
+	}
+
+	t.fallback = keep(t.fallback)
+	t.fallbackChain = t.chain(t.fallback)
+
+	for code, routes := range t.typed {
+		if routes = keep(routes); len(routes) > 0 {
+			t.typed[code] = routes
+			t.merge(code)
+		} else {
+			delete(t.typed, code)
+			delete(t.routes, code)
+		}
+	}
+}
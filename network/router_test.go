@@ -0,0 +1,195 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/types/opcode"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type routeTestComponent struct {
+	*Component
+
+	calls *[]string
+}
+
+func (p *routeTestComponent) RegisterHandlers(router *Router) error {
+	if err := router.Handle(&protobuf.Ping{}, func(ctx *ComponentContext, msg *protobuf.Ping) error {
+		*p.calls = append(*p.calls, "ping")
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return router.HandleWithPriority(-1, &protobuf.Ping{}, HandlerFunc(func(ctx *ComponentContext) error {
+		*p.calls = append(*p.calls, "first ping")
+		return nil
+	}))
+}
+
+func (p *routeTestComponent) Receive(ctx *ComponentContext) error {
+	*p.calls = append(*p.calls, "unexpected")
+	return nil
+}
+
+type receiveTestComponent struct {
+	*Component
+
+	calls *[]string
+}
+
+func (p *receiveTestComponent) Receive(ctx *ComponentContext) error {
+	*p.calls = append(*p.calls, "receive")
+	return nil
+}
+
+func TestRouter(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	run := func(net *Network, code opcode.Opcode, msg interface{}) []string {
+		calls = nil
+		assert.Nil(t, net.routes.handler(code)(&ComponentContext{message: msg}))
+		return calls
+	}
+
+	builder := NewBuilder()
+	builder.AddComponentWithPriority(1, &routeTestComponent{calls: &calls})
+	builder.AddComponentWithPriority(0, &receiveTestComponent{calls: &calls})
+	net, err := builder.Build()
+	assert.Nil(t, err)
+
+	// Handlers run by priority, interleaved with Components receiving every
+	// message.
+	assert.Equal(t, []string{"first ping", "receive", "ping"}, run(net, opcode.PingCode, &protobuf.Ping{}))
+
+	// Components with handlers are only passed the messages they handle.
+	assert.Equal(t, []string{"receive"}, run(net, opcode.PongCode, &protobuf.Pong{}))
+
+	// Handlers of the same priority run in the order they were registered.
+	router := net.Router()
+	assert.Nil(t, router.Handle(&protobuf.Pong{}, func(ctx *ComponentContext, msg *protobuf.Pong) error {
+		calls = append(calls, "pong")
+		return nil
+	}))
+	assert.Nil(t, router.Handle(&protobuf.Pong{}, func(ctx *ComponentContext, msg *protobuf.Pong) error {
+		calls = append(calls, "second pong")
+		return nil
+	}))
+	assert.Equal(t, []string{"receive", "pong", "second pong"}, run(net, opcode.PongCode, &protobuf.Pong{}))
+
+	// Handlers must match the type of the message, which must be registered.
+	for _, handler := range []interface{}{
+		nil,
+		"handler",
+		func(ctx *ComponentContext) {},
+		func(ctx *ComponentContext, msg *protobuf.Pong) error { return nil },
+		func(msg *protobuf.Ping) error { return nil },
+		func(ctx *ComponentContext, msg *protobuf.Ping) {},
+	} {
+		assert.NotNilf(t, router.Handle(&protobuf.Ping{}, handler), "expected handler %T to be rejected", handler)
+	}
+	assert.NotNil(t, router.Handle(&protobuf.ID{}, HandlerFunc(func(ctx *ComponentContext) error { return nil })))
+}
+
+type middlewareTestComponent struct {
+	*Component
+
+	calls *[]string
+}
+
+func (p *middlewareTestComponent) Intercept(ctx *ComponentContext, next HandlerFunc) error {
+	if _, ok := ctx.Message().(*protobuf.Pong); ok {
+		*p.calls = append(*p.calls, "stopped")
+		return nil
+	}
+
+	*p.calls = append(*p.calls, "before")
+	ctx.Set("annotation", "set by middleware")
+	err := next(ctx)
+	*p.calls = append(*p.calls, "after")
+	return err
+}
+
+type stopTestComponent struct {
+	*Component
+
+	calls *[]string
+}
+
+func (p *stopTestComponent) Receive(ctx *ComponentContext) error {
+	value, _ := ctx.Get("annotation")
+	*p.calls = append(*p.calls, value.(string))
+
+	if _, ok := ctx.Message().(*protobuf.Disconnect); ok {
+		return ErrStopPropagation
+	}
+	return nil
+}
+
+type failTestComponent struct {
+	*Component
+}
+
+func (p *failTestComponent) Receive(ctx *ComponentContext) error {
+	return errors.New("failed by test")
+}
+
+func TestRouterErrors(t *testing.T) {
+	t.Parallel()
+
+	builder := NewBuilder()
+	builder.AddComponentWithPriority(0, new(failTestComponent))
+	net, err := builder.Build()
+	assert.Nil(t, err)
+
+	run := func(msg interface{}) error {
+		ctx := &ComponentContext{message: msg}
+		assert.Nil(t, net.routes.handler(opcode.PingCode)(ctx))
+		return ctx.err
+	}
+
+	// Components receiving every message do not fail requests others may
+	// reply to.
+	assert.Nil(t, run(&protobuf.Ping{}))
+
+	// Errors of handlers of the message type fail requests.
+	assert.Nil(t, net.Router().Handle(&protobuf.Ping{}, func(ctx *ComponentContext, msg *protobuf.Ping) error {
+		return errors.New("ping failed")
+	}))
+	assert.EqualError(t, run(&protobuf.Ping{}), "ping failed")
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	run := func(net *Network, code opcode.Opcode, msg interface{}) []string {
+		calls = nil
+		assert.Nil(t, net.routes.handler(code)(&ComponentContext{message: msg}))
+		return calls
+	}
+
+	builder := NewBuilder()
+	builder.AddComponentWithPriority(0, &middlewareTestComponent{calls: &calls})
+	builder.AddComponentWithPriority(1, &stopTestComponent{calls: &calls})
+	builder.AddComponentWithPriority(2, &receiveTestComponent{calls: &calls})
+	net, err := builder.Build()
+	assert.Nil(t, err)
+
+	// Middleware wraps the Components after it, which see its annotations.
+	assert.Equal(t, []string{"before", "set by middleware", "receive", "after"}, run(net, opcode.PingCode, &protobuf.Ping{}))
+
+	// Components may stop messages from reaching the Components after them.
+	assert.Equal(t, []string{"stopped"}, run(net, opcode.PongCode, &protobuf.Pong{}))
+	assert.Equal(t, []string{"before", "set by middleware", "after"}, run(net, opcode.DisconnectCode, &protobuf.Disconnect{}))
+
+	// Middleware registered with the router wraps handlers of lower priority.
+	net.Router().UseWithPriority(-1, func(ctx *ComponentContext, next HandlerFunc) error {
+		calls = append(calls, "outer")
+		return next(ctx)
+	})
+	assert.Equal(t, []string{"outer", "before", "set by middleware", "receive", "after"}, run(net, opcode.PingCode, &protobuf.Ping{}))
+}
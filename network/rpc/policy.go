@@ -0,0 +1,285 @@
+package rpc
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cocher/network"
+	"github.com/cocher/network/backoff"
+	"github.com/cocher/peer"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// number of latencies of past requests kept to compute percentiles
+	latencyWindow = 128
+	// number of latencies observed before their percentile is trusted
+	minLatencies = 10
+)
+
+// Policy decides how requests are retried, hedged and failed over. Policies
+// keep track of the latency of their requests, and are safe for concurrent
+// use.
+type Policy struct {
+	// Policy options
+	// backoff delays retries of requests, nil to not retry them
+	backoff *backoff.Backoff
+	// attemptTimeout bounds each attempt at a request, zero to bound it by
+	// the context of the request only
+	attemptTimeout time.Duration
+	// hedgePercentile of latencies after which requests are hedged, zero to
+	// not hedge them
+	hedgePercentile float64
+	// hedgeDelay after which requests are hedged until enough latencies
+	// were observed
+	hedgeDelay time.Duration
+	// retryable decides which errors a request is retried after
+	retryable func(err error) bool
+
+	mutex     sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+// Option are configurable options for request policies
+type Option func(*Policy)
+
+// WithRetries retries requests which failed, waiting between attempts as the
+// backoff decides, until it reaches its max attempts. Only idempotent
+// requests should be retried.
+func WithRetries(b *backoff.Backoff) Option {
+	return func(o *Policy) {
+		o.backoff = b
+	}
+}
+
+// WithAttemptTimeout bounds each attempt at a request, on top of the context
+// the request is sent with.
+func WithAttemptTimeout(d time.Duration) Option {
+	return func(o *Policy) {
+		o.attemptTimeout = d
+	}
+}
+
+// WithHedging also sends a request given to RequestAny to the next peer when
+// no reply arrived after the given percentile of the latencies of past
+// requests, e.g. 0.95. The delay given is waited instead until enough
+// latencies were observed. Only idempotent requests should be hedged.
+func WithHedging(percentile float64, delay time.Duration) Option {
+	return func(o *Policy) {
+		o.hedgePercentile = percentile
+		o.hedgeDelay = delay
+	}
+}
+
+// WithRetryIf decides which errors requests are retried after. By default,
+// requests are retried unless the peer does not support them, or replied
+// with an error other than network.ErrorCodeUnavailable.
+func WithRetryIf(retryable func(err error) bool) Option {
+	return func(o *Policy) {
+		o.retryable = retryable
+	}
+}
+
+func defaultOptions() Option {
+	return func(o *Policy) {
+		o.retryable = defaultRetryable
+	}
+}
+
+// New returns a new request policy with specified options
+func New(opts ...Option) *Policy {
+	p := new(Policy)
+	defaultOptions()(p)
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// defaultRetryable returns false for errors which a peer would return again.
+func defaultRetryable(err error) bool {
+	if remote, ok := network.AsRemoteError(err); ok {
+		return remote.Code == network.ErrorCodeUnavailable
+	}
+	return !network.IsUnsupported(err)
+}
+
+// attempt sends a request to one of the peers of a request.
+type attempt func(ctx context.Context, i int) (interface{}, error)
+
+// Request sends a request to a peer, retrying it as the policy decides.
+func (p *Policy) Request(ctx context.Context, client *network.PeerClient, req interface{}) (interface{}, error) {
+	return p.run(ctx, 1, func(ctx context.Context, i int) (interface{}, error) {
+		return client.Request(ctx, req)
+	})
+}
+
+// RequestAny sends a request to the first of a list of peers which replies.
+// Peers are tried in order, failing over to the next peer once a peer could
+// not be reached with ClientByID or failed to reply, and hedging the request
+// to the next peer if the policy hedges requests. Once all peers failed, they
+// are tried again if the policy retries requests.
+func (p *Policy) RequestAny(ctx context.Context, net *network.Network, ids []peer.ID, req interface{}) (interface{}, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("rpc: no peer to send request to")
+	}
+
+	return p.run(ctx, len(ids), func(ctx context.Context, i int) (interface{}, error) {
+		client, err := net.ClientByID(ids[i])
+		if err != nil {
+			return nil, err
+		}
+		return client.Request(ctx, req)
+	})
+}
+
+// run runs rounds of attempts at a request until one succeeds, waiting
+// between rounds as the backoff of the policy decides.
+func (p *Policy) run(ctx context.Context, peers int, f attempt) (interface{}, error) {
+	var b *backoff.Backoff
+	if p.backoff != nil {
+		copied := *p.backoff
+		copied.Reset()
+		b = &copied
+	}
+
+	for {
+		res, err := p.round(ctx, peers, f)
+		if err == nil {
+			return res, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if b == nil || b.TimeoutExceeded() || !p.retryable(err) {
+			return nil, err
+		}
+
+		timer := time.NewTimer(b.NextDuration())
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// round tries every peer once in order, until one replies. The next peer is
+// tried once the previous failed, or once the hedging delay passed.
+func (p *Policy) round(ctx context.Context, peers int, f attempt) (interface{}, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		res interface{}
+		err error
+	}
+	results := make(chan result, peers)
+
+	next, pending := 0, 0
+	start := func() {
+		i := next
+		next++
+		pending++
+
+		go func() {
+			res, err := p.try(ctx, i, f)
+			results <- result{res: res, err: err}
+		}()
+	}
+
+	start()
+
+	var err error
+	for pending > 0 {
+		var hedge <-chan time.Time
+		var timer *time.Timer
+		if next < peers && p.hedgePercentile > 0 {
+			timer = time.NewTimer(p.hedgeAfter())
+			hedge = timer.C
+		}
+
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return r.res, nil
+			}
+			err = r.err
+
+			if next < peers {
+				start()
+			}
+		case <-hedge:
+			start()
+		case <-ctx.Done():
+			err = ctx.Err()
+			pending = 0
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+
+	return nil, err
+}
+
+// try makes a single attempt at a request, bounded by the attempt timeout of
+// the policy, and records its latency if it succeeded.
+func (p *Policy) try(ctx context.Context, i int, f attempt) (interface{}, error) {
+	if p.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.attemptTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	res, err := f(ctx, i)
+	if err == nil {
+		p.observe(time.Since(start))
+	}
+	return res, err
+}
+
+// observe records the latency of a request.
+func (p *Policy) observe(latency time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.latencies) < latencyWindow {
+		p.latencies = append(p.latencies, latency)
+		return
+	}
+	p.latencies[p.next] = latency
+	p.next = (p.next + 1) % latencyWindow
+}
+
+// hedgeAfter returns the delay after which requests are hedged.
+func (p *Policy) hedgeAfter() time.Duration {
+	p.mutex.Lock()
+	latencies := append([]time.Duration{}, p.latencies...)
+	p.mutex.Unlock()
+
+	if len(latencies) < minLatencies {
+		return p.hedgeDelay
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	i := int(p.hedgePercentile * float64(len(latencies)-1))
+	if i >= len(latencies) {
+		i = len(latencies) - 1
+	}
+	return latencies[i]
+}
@@ -0,0 +1,182 @@
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/network"
+	"github.com/cocher/network/backoff"
+	"github.com/cocher/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+// pongComponent replies to pings with a pong, after failing as many pings
+// as asked for.
+type pongComponent struct {
+	*network.Component
+
+	delay time.Duration
+	// number of pings to fail
+	failures int32
+	// error code of the pings failed
+	code network.ErrorCode
+	// number of pings received
+	pings int32
+}
+
+func (p *pongComponent) RegisterHandlers(router *network.Router) error {
+	return router.Handle(&protobuf.Ping{}, func(ctx *network.ComponentContext, msg *protobuf.Ping) error {
+		return p.pong(ctx)
+	})
+}
+
+func (p *pongComponent) pong(ctx *network.ComponentContext) error {
+	if atomic.AddInt32(&p.pings, 1) <= p.failures {
+		return network.NewRemoteError(p.code, "failed by test")
+	}
+
+	time.Sleep(p.delay)
+	return ctx.Reply(context.Background(), &protobuf.Pong{})
+}
+
+func newNode(t *testing.T, component *pongComponent) *network.Network {
+	builder := network.NewBuilder()
+	builder.SetKeys(ed25519.RandomKeyPair())
+	builder.SetAddress(network.FormatAddress("tcp", "127.0.0.1", uint16(network.GetRandomUnusedPort())))
+	if component != nil {
+		builder.AddComponent(component)
+	}
+
+	net, err := builder.Build()
+	if err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+
+	go net.Listen()
+	net.BlockUntilListening()
+
+	return net
+}
+
+func retries(attempts int) *backoff.Backoff {
+	b := backoff.DefaultBackoff()
+	b.MaxAttempts = attempts
+	b.MinInterval = 10 * time.Millisecond
+	b.MaxInterval = 20 * time.Millisecond
+	return b
+}
+
+func TestRetries(t *testing.T) {
+	t.Parallel()
+
+	component := &pongComponent{failures: 2, code: network.ErrorCodeUnavailable}
+	server := newNode(t, component)
+	defer server.Close()
+	node := newNode(t, nil)
+	defer node.Close()
+
+	client, err := node.Client(server.Address)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Requests are not retried by default.
+	_, err = New().Request(ctx, client, &protobuf.Ping{})
+	remote, ok := network.AsRemoteError(err)
+	if assert.True(t, ok, "expected a remote error, got %v", err) {
+		assert.Equal(t, network.ErrorCodeUnavailable, remote.Code)
+	}
+
+	// Requests are retried until the backoff gives up.
+	atomic.StoreInt32(&component.pings, 0)
+	_, err = New(WithRetries(retries(1))).Request(ctx, client, &protobuf.Ping{})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&component.pings))
+
+	atomic.StoreInt32(&component.pings, 0)
+	response, err := New(WithRetries(retries(2))).Request(ctx, client, &protobuf.Ping{})
+	assert.Nil(t, err)
+	assert.Equal(t, &protobuf.Pong{}, response)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&component.pings))
+
+	// Errors the peer would reply again are not retried.
+	component.code = network.ErrorCodeInvalidArgument
+	atomic.StoreInt32(&component.pings, 0)
+	_, err = New(WithRetries(retries(2))).Request(ctx, client, &protobuf.Ping{})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&component.pings))
+
+	// Each attempt is bounded by the attempt timeout.
+	component.delay = time.Second
+	atomic.StoreInt32(&component.pings, 0)
+	start := time.Now()
+	_, err = New(WithAttemptTimeout(100*time.Millisecond)).Request(ctx, client, &protobuf.Ping{})
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "expected the attempt to time out")
+}
+
+func TestFailover(t *testing.T) {
+	t.Parallel()
+
+	component := new(pongComponent)
+	server := newNode(t, component)
+	defer server.Close()
+	node := newNode(t, nil)
+	defer node.Close()
+
+	unreachable := network.FormatAddress("tcp", "127.0.0.1", uint16(network.GetRandomUnusedPort()))
+	ids := []peer.ID{peer.CreateID(unreachable, ed25519.RandomKeyPair().PublicKey), server.GetID()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Peers which can not be reached are failed over.
+	response, err := New().RequestAny(ctx, node, ids, &protobuf.Ping{})
+	assert.Nil(t, err)
+	assert.Equal(t, &protobuf.Pong{}, response)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&component.pings))
+
+	_, err = New().RequestAny(ctx, node, ids[:1], &protobuf.Ping{})
+	assert.NotNil(t, err)
+
+	_, err = New().RequestAny(ctx, node, nil, &protobuf.Ping{})
+	assert.NotNil(t, err)
+}
+
+func TestHedging(t *testing.T) {
+	t.Parallel()
+
+	slow, fast := &pongComponent{delay: 2 * time.Second}, new(pongComponent)
+	slowServer := newNode(t, slow)
+	defer slowServer.Close()
+	fastServer := newNode(t, fast)
+	defer fastServer.Close()
+	node := newNode(t, nil)
+	defer node.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Requests slower than usual are hedged to the next peer.
+	policy := New(WithHedging(0.95, 100*time.Millisecond))
+	start := time.Now()
+	response, err := policy.RequestAny(ctx, node, []peer.ID{slowServer.GetID(), fastServer.GetID()}, &protobuf.Ping{})
+	assert.Nil(t, err)
+	assert.Equal(t, &protobuf.Pong{}, response)
+	assert.True(t, time.Since(start) < time.Second, "expected the request to be hedged")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&slow.pings))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fast.pings))
+
+	// Once enough latencies were observed, requests are hedged after their
+	// percentile.
+	policy = New(WithHedging(0.95, 100*time.Millisecond))
+	for i := 0; i < minLatencies; i++ {
+		policy.observe(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 8*time.Millisecond, policy.hedgeAfter())
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"io"
 	"sync"
+	"sync/atomic"
 
 	"net"
 
@@ -16,16 +17,28 @@ import (
 
 var errEmptyMsg = errors.New("received an empty message from a peer")
 
+// envelopeFlag is set in the size of a message encoded with the envelope
+// codec of the network instead of protobuf.
+const envelopeFlag = 1 << 31
+
 // sendMessage marshals, signs and sends a message over a stream.
 func (n *Network) sendMessage(w io.Writer, message *protobuf.Message, writerMutex *sync.Mutex, state *ConnState) error {
-	bytes, err := proto.Marshal(message)
+	var bytes []byte
+	var err error
+	var flag uint32
+	if atomic.LoadUint32(&state.encodeEnvelopes) == 1 {
+		bytes, err = n.opts.envelopeCodec.Marshal(message)
+		flag = envelopeFlag
+	} else {
+		bytes, err = proto.Marshal(message)
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal message")
 	}
 
 	// Serialize size.
 	buffer := make([]byte, 4)
-	binary.BigEndian.PutUint32(buffer, uint32(len(bytes)))
+	binary.BigEndian.PutUint32(buffer, uint32(len(bytes))|flag)
 
 	buffer = append(buffer, bytes...)
 	totalSize := len(buffer)
@@ -85,6 +98,9 @@ func (n *Network) receiveMessage(conn interface{}) (*protobuf.Message, error) {
 		return nil, errEmptyMsg
 	}
 
+	encoded := size&envelopeFlag != 0
+	size &^= envelopeFlag
+
 	if size > uint32(n.opts.recvBufferSize) {
 		return nil, errors.Errorf("message has length of %d which is either broken or too large(default %d)", size, n.opts.recvBufferSize)
 	}
@@ -103,7 +119,11 @@ func (n *Network) receiveMessage(conn interface{}) (*protobuf.Message, error) {
 
 	// Deserialize message.
 	msg := new(protobuf.Message)
-	err = proto.Unmarshal(buffer, msg)
+	if encoded {
+		err = n.opts.envelopeCodec.Unmarshal(buffer, msg)
+	} else {
+		err = proto.Unmarshal(buffer, msg)
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal message")
 	}
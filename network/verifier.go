@@ -0,0 +1,162 @@
+package network
+
+import (
+	"time"
+
+	"github.com/cocher/crypto"
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/utils/log"
+)
+
+// verifyRequest is a received message waiting for its signature to be verified.
+type verifyRequest struct {
+	msg  *protobuf.Message
+	done func(ok bool)
+}
+
+// batchVerifier briefly accumulates received messages so that their signatures
+// are verified in batches rather than one by one.
+type batchVerifier struct {
+	sp    crypto.SignaturePolicy
+	hp    crypto.HashPolicy
+	size  int
+	delay time.Duration
+
+	requests chan *verifyRequest
+}
+
+// newBatchVerifier returns nil if the signature policy does not support batch
+// verification or batches would hold a single message.
+func newBatchVerifier(sp crypto.SignaturePolicy, hp crypto.HashPolicy, size int, delay time.Duration) *batchVerifier {
+	if _, ok := sp.(crypto.BatchVerifier); !ok || size <= 1 {
+		return nil
+	}
+
+	return &batchVerifier{
+		sp:       sp,
+		hp:       hp,
+		size:     size,
+		delay:    delay,
+		requests: make(chan *verifyRequest, size),
+	}
+}
+
+// loop collects requests until a batch is full or the oldest request has waited
+// for the batch delay, then verifies the batch on its own goroutine.
+func (v *batchVerifier) loop(kill <-chan struct{}) {
+	for {
+		var batch []*verifyRequest
+
+		select {
+		case <-kill:
+			return
+		case req := <-v.requests:
+			batch = append(make([]*verifyRequest, 0, v.size), req)
+		}
+
+		timer := time.NewTimer(v.delay)
+
+	collect:
+		for len(batch) < v.size {
+			select {
+			case <-kill:
+				timer.Stop()
+				return
+			case req := <-v.requests:
+				batch = append(batch, req)
+			case <-timer.C:
+				break collect
+			}
+		}
+
+		timer.Stop()
+
+		go v.verify(batch)
+	}
+}
+
+func (v *batchVerifier) verify(batch []*verifyRequest) {
+	publicKeys := make([][]byte, len(batch))
+	messages := make([][]byte, len(batch))
+	signatures := make([][]byte, len(batch))
+
+	for i, req := range batch {
+		publicKeys[i] = req.msg.Sender.NetKey
+		messages[i] = SerializeMessage(req.msg.Sender, req.msg.Message)
+		signatures[i] = req.msg.Signature
+	}
+
+	for i, ok := range crypto.VerifyBatch(v.sp, v.hp, publicKeys, messages, signatures) {
+		batch[i].done(ok)
+	}
+}
+
+// verifyMessage checks the signature of a received message, if any, and calls
+// done with the result on another goroutine.
+func (n *Network) verifyMessage(msg *protobuf.Message, done func(ok bool)) {
+	if msg.Signature == nil || n.verifier == nil {
+		go func() {
+			done(msg.Signature == nil || crypto.Verify(
+				n.opts.signaturePolicy,
+				n.opts.hashPolicy,
+				msg.Sender.NetKey,
+				SerializeMessage(msg.Sender, msg.Message),
+				msg.Signature,
+			))
+		}()
+		return
+	}
+
+	select {
+	case n.verifier.requests <- &verifyRequest{msg: msg, done: done}:
+	case <-n.kill:
+	}
+}
+
+// pendingMessage is a received message whose signature is being verified.
+type pendingMessage struct {
+	msg *protobuf.Message
+	ok  chan bool
+}
+
+// verifyInOrder returns a function verifying the signatures of messages
+// received over a single connection, which calls accept for every message
+// with a valid signature in the order they were received in, as the receive
+// window of a connection starts at the nonce of the first message pushed to
+// it. stop must be called once no more messages will be received.
+func (n *Network) verifyInOrder(accept func(msg *protobuf.Message)) (verify func(msg *protobuf.Message), stop func()) {
+	pending := make(chan *pendingMessage, n.opts.recvWindowSize)
+
+	go func() {
+		for p := range pending {
+			select {
+			case ok := <-p.ok:
+				if !ok {
+					log.Errorf("received message had an malformed signature")
+					continue
+				}
+				accept(p.msg)
+			case <-n.kill:
+				return
+			}
+		}
+	}()
+
+	verify = func(msg *protobuf.Message) {
+		p := &pendingMessage{msg: msg, ok: make(chan bool, 1)}
+		n.verifyMessage(msg, func(ok bool) {
+			p.ok <- ok
+		})
+
+		select {
+		case pending <- p:
+		case <-n.kill:
+		}
+	}
+
+	stop = func() {
+		close(pending)
+	}
+
+	return verify, stop
+}
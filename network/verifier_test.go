@@ -0,0 +1,110 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cocher/crypto"
+	"github.com/cocher/crypto/blake2b"
+	"github.com/cocher/crypto/ed25519"
+	"github.com/cocher/crypto/secp256k1"
+	"github.com/cocher/internal/protobuf"
+	"github.com/cocher/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func signedTestMessage(t *testing.T, sp crypto.SignaturePolicy, hp crypto.HashPolicy, raw []byte) *protobuf.Message {
+	kp := sp.RandomKeyPair()
+	id := protobuf.ID(peer.CreateID("tcp://localhost:1", kp.PublicKey))
+
+	signature, err := kp.Sign(sp, hp, SerializeMessage(&id, raw))
+	assert.Nil(t, err)
+
+	return &protobuf.Message{Message: raw, Sender: &id, Signature: signature}
+}
+
+func TestNewBatchVerifier(t *testing.T) {
+	t.Parallel()
+
+	assert.NotNil(t, newBatchVerifier(ed25519.New(), blake2b.New(), 64, time.Millisecond))
+	assert.Nil(t, newBatchVerifier(ed25519.New(), blake2b.New(), 1, time.Millisecond), "batches of one should not be collected")
+	assert.Nil(t, newBatchVerifier(secp256k1.New(), blake2b.New(), 64, time.Millisecond), "policy does not support batches")
+}
+
+func TestBatchVerifier(t *testing.T) {
+	t.Parallel()
+
+	sp, hp := ed25519.New(), blake2b.New()
+
+	kill := make(chan struct{})
+	defer close(kill)
+
+	v := newBatchVerifier(sp, hp, 8, 10*time.Millisecond)
+	go v.loop(kill)
+
+	// a bad signature in the middle of a batch should be the only one rejected
+	numMessages := 12
+	results := make(chan [2]int, numMessages)
+	for i := 0; i < numMessages; i++ {
+		i := i
+		msg := signedTestMessage(t, sp, hp, []byte{byte(i)})
+		if i == 5 {
+			msg.Message = []byte("tampered")
+		}
+
+		v.requests <- &verifyRequest{msg: msg, done: func(ok bool) {
+			if ok {
+				results <- [2]int{i, 1}
+			} else {
+				results <- [2]int{i, 0}
+			}
+		}}
+	}
+
+	for i := 0; i < numMessages; i++ {
+		select {
+		case r := <-results:
+			assert.Equal(t, r[0] != 5, r[1] == 1, "unexpected verification result for message %d", r[0])
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for message %d to be verified", i)
+		}
+	}
+}
+
+func TestVerifyInOrder(t *testing.T) {
+	t.Parallel()
+
+	net, err := NewBuilderWithOptions(BatchVerifyDelay(20 * time.Millisecond)).Build()
+	assert.Nil(t, err)
+	defer close(net.kill)
+
+	sp, hp := net.opts.signaturePolicy, net.opts.hashPolicy
+
+	accepted := make(chan byte, 8)
+	verify, stop := net.verifyInOrder(func(msg *protobuf.Message) {
+		accepted <- msg.Message[0]
+	})
+	defer stop()
+
+	// signed messages wait for their batch while unsigned ones are verified
+	// right away, yet all of them are accepted in the order they were received
+	for i := 0; i < 6; i++ {
+		msg := signedTestMessage(t, sp, hp, []byte{byte(i)})
+		if i%2 == 1 {
+			msg.Signature = nil
+		}
+		if i == 4 {
+			msg.Message = []byte("tampered")
+		}
+		verify(msg)
+	}
+
+	for _, want := range []byte{0, 1, 2, 3, 5} {
+		select {
+		case got := <-accepted:
+			assert.Equal(t, want, got)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for message %d to be accepted", want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/bits"
 
+	"github.com/cocher/crypto"
 	"github.com/cocher/crypto/blake2b"
 	"github.com/cocher/internal/protobuf"
 )
@@ -13,9 +14,14 @@ import (
 // ID is an identity of nodes, using its public key hash and network address.
 type ID protobuf.ID
 
-// CreateID is a factory function creating ID.
+// CreateID is a factory function creating ID, hashing the public key with blake2b.
 func CreateID(address string, publicKey []byte) ID {
-	return ID{Address: address, NetKey: publicKey, Id: blake2b.New().HashBytes(publicKey)}
+	return CreateIDWithHashPolicy(address, publicKey, blake2b.New())
+}
+
+// CreateIDWithHashPolicy is a factory function creating ID, hashing the public key with the given hash policy.
+func CreateIDWithHashPolicy(address string, publicKey []byte, hp crypto.HashPolicy) ID {
+	return ID{Address: address, NetKey: publicKey, Id: hp.HashBytes(publicKey)}
 }
 
 // String returns the identity address and public key.
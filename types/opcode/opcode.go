@@ -1,92 +1,108 @@
 package opcode
 
 import (
-	"reflect"
-	"sync"
-
-	"github.com/cocher/internal/protobuf"
+	"hash/fnv"
 
 	"github.com/gogo/protobuf/proto"
-	"github.com/pkg/errors"
+	golangproto "github.com/golang/protobuf/proto"
 )
 
-func init() {
-	msgOpcodePairs := []struct {
-		msg    proto.Message
-		opcode Opcode
-	}{
-		{&protobuf.Bytes{}, BytesCode},
-		{&protobuf.Ping{}, PingCode},
-		{&protobuf.Pong{}, PongCode},
-		{&protobuf.LookupNodeRequest{}, LookupNodeRequestCode},
-		{&protobuf.LookupNodeResponse{}, LookupNodeResponseCode},
-		{&protobuf.Keepalive{}, KeepaliveCode},
-		{&protobuf.KeepaliveResponse{}, KeepaliveResponseCode},
-		{&protobuf.Disconnect{}, DisconnectCode},
-	}
-
-	for _, pair := range msgOpcodePairs {
-		opcodeTbl.Store(pair.opcode, pair.msg)
-		t := reflect.TypeOf(pair.msg)
-		msgTbl.Store(t, pair.opcode)
-	}
-}
-
 type Opcode uint32
 
 const (
 	UnregisteredCode       Opcode = 0x00000 // 0
 	BytesCode              Opcode = 0x00001 // 1
+	KeepaliveCode          Opcode = 0x00002 // 2
+	KeepaliveResponseCode  Opcode = 0x00003 // 3
 	PingCode               Opcode = 0x0000a // 10
 	PongCode               Opcode = 0x0000b // 11
 	LookupNodeRequestCode  Opcode = 0x0000c // 12
 	LookupNodeResponseCode Opcode = 0x0000d // 13
 	DisconnectCode         Opcode = 0x0000e // 14
-	KeepaliveCode          Opcode = 0x00002 // 20
-	KeepaliveResponseCode  Opcode = 0x00003 // 21
-)
+	KeySuccessionCode      Opcode = 0x0000f // 15
+	PeerRecordCode         Opcode = 0x00010 // 16
+	IdentifyCode           Opcode = 0x00011 // 17
+	CapabilitiesCode       Opcode = 0x00012 // 18
+	ErrorReplyCode         Opcode = 0x00013 // 19
+	StreamControlCode      Opcode = 0x00014 // 20
 
-var (
-	// opcodeTbl is a map of <Opcode, proto.Message> pairs
-	opcodeTbl = sync.Map{}
-	// msgTbl is a map of <reflect.Type, Opcode> pairs
-	msgTbl = sync.Map{}
+	// NamedCodeBase is the first of the opcodes derived from message names.
+	// Opcodes below it are picked by hand.
+	NamedCodeBase Opcode = 0x80000000
 )
 
+// defaultRegistry is the registry of Networks not given one of their own
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the process-wide registry the package level
+// functions operate on, used by Networks not given a registry of their own
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
 // RegisterMessageType registers a new proto message to the given opcode
 func RegisterMessageType(opcode Opcode, msg proto.Message) error {
-	// reserve first 1000 opcodes
-	if opcode < 1000 {
-		return errors.New("types: opcode must be 1000 or greater")
-	}
-	raw, err := proto.Marshal(msg)
-	if err != nil {
-		return err
-	}
-	if len(raw) != 0 {
-		return errors.New("types: must provide an empty protobuf message")
-	}
-	if _, loaded := opcodeTbl.LoadOrStore(opcode, msg); loaded {
-		return errors.New("types: opcode already exists, choose a different opcode")
-	} else {
-		msgTbl.Store(reflect.TypeOf(msg), opcode)
+	return defaultRegistry.RegisterMessageType(opcode, msg)
+}
+
+// RegisterMessage registers a new proto message under an opcode derived from
+// its fully qualified protobuf name, so that every node registering the same
+// message agrees on its opcode without picking one by hand.
+func RegisterMessage(msg proto.Message) (Opcode, error) {
+	return defaultRegistry.RegisterMessage(msg)
+}
+
+// RegisterType registers a Go type which is no protobuf message to the given
+// opcode, to be encoded with the named codec, e.g. "json"
+func RegisterType(opcode Opcode, v interface{}, codec string) error {
+	return defaultRegistry.RegisterType(opcode, v, codec)
+}
+
+// Unregister removes the message registered to an opcode
+func Unregister(opcode Opcode) error {
+	return defaultRegistry.Unregister(opcode)
+}
+
+// NamedOpcode derives the opcode of a message from its fully qualified
+// protobuf name, e.g. "protobuf.Ping".
+func NamedOpcode(name string) Opcode {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return NamedCodeBase | Opcode(h.Sum32())&^NamedCodeBase
+}
+
+// MessageName returns the fully qualified protobuf name of a message, or an
+// empty string if the message type is not known to protobuf.
+func MessageName(msg proto.Message) string {
+	if name := proto.MessageName(msg); name != "" {
+		return name
 	}
-	return nil
+	return golangproto.MessageName(msg)
 }
 
 // GetMessageType returns the corresponding proto message type given an opcode
 func GetMessageType(code Opcode) (proto.Message, error) {
-	if i, ok := opcodeTbl.Load(code); ok {
-		return proto.Clone(i.(proto.Message)), nil
-	}
-	return nil, errors.New("types: opcode not found, did you register it?")
+	return defaultRegistry.GetMessageType(code)
 }
 
-// GetOpcode returns the corresponding opcode given a proto message
-func GetOpcode(msg proto.Message) (Opcode, error) {
-	t := reflect.TypeOf(msg)
-	if i, ok := msgTbl.Load(t); ok {
-		return i.(Opcode), nil
-	}
-	return UnregisteredCode, errors.New("types: message type not found, did you register it?")
+// GetOpcode returns the corresponding opcode given a message
+func GetOpcode(msg interface{}) (Opcode, error) {
+	return defaultRegistry.GetOpcode(msg)
+}
+
+// GetOpcodeByName returns the opcode of a registered message given its fully
+// qualified protobuf name
+func GetOpcodeByName(name string) (Opcode, error) {
+	return defaultRegistry.GetOpcodeByName(name)
+}
+
+// GetMessageName returns the fully qualified protobuf name of the message
+// registered to an opcode
+func GetMessageName(code Opcode) (string, error) {
+	return defaultRegistry.GetMessageName(code)
+}
+
+// GetOpcodes returns all registered opcodes in ascending order
+func GetOpcodes() []Opcode {
+	return defaultRegistry.Opcodes()
 }
@@ -89,3 +89,60 @@ func TestGetOpcode(t *testing.T) {
 		assert.Equal(t, tt.opcode, opcode, "opcodes should be equal")
 	}
 }
+
+func TestGetOpcodes(t *testing.T) {
+	t.Parallel()
+
+	opcodes := GetOpcodes()
+	assert.Contains(t, opcodes, PingCode)
+	assert.Contains(t, opcodes, IdentifyCode)
+
+	for i := 1; i < len(opcodes); i++ {
+		assert.True(t, opcodes[i-1] < opcodes[i], "opcodes should be sorted")
+	}
+}
+
+func TestRegisterMessage(t *testing.T) {
+	t.Parallel()
+
+	// Derived opcodes are part of the wire format and must never change.
+	assert.Equal(t, Opcode(0x96eedc8f), NamedOpcode("app.ChatMessage"))
+
+	assert.Equal(t, "protobuf.Ping", MessageName(&pb.Ping{}))
+	assert.Equal(t, "protobuf.TestMessage", MessageName(&protobuf.TestMessage{}))
+
+	code, err := RegisterMessage(&pb.SignedAddress{})
+	assert.Nil(t, err)
+	assert.Equal(t, Opcode(0xcf9d8730), code)
+	assert.True(t, code >= NamedCodeBase)
+
+	byName, err := GetOpcodeByName("protobuf.SignedAddress")
+	assert.Nil(t, err)
+	assert.Equal(t, code, byName)
+
+	name, err := GetMessageName(code)
+	assert.Nil(t, err)
+	assert.Equal(t, "protobuf.SignedAddress", name)
+
+	msgType, err := GetMessageType(code)
+	assert.Nil(t, err)
+	assert.Equal(t, reflect.TypeOf(&pb.SignedAddress{}), reflect.TypeOf(msgType))
+
+	// test failure if message is already registered, by name or by opcode
+	_, err = RegisterMessage(&pb.SignedAddress{})
+	assert.NotNil(t, err)
+	_, err = RegisterMessage(&pb.Ping{})
+	assert.NotNil(t, err)
+	assert.NotNil(t, RegisterMessageType(Opcode(1234), &pb.Ping{}))
+
+	// test failure if a numeric opcode is taken by a derived one
+	assert.NotNil(t, RegisterMessageType(code, &pb.ID{}))
+
+	// messages registered by number are known by name too
+	byName, err = GetOpcodeByName("protobuf.Ping")
+	assert.Nil(t, err)
+	assert.Equal(t, PingCode, byName)
+
+	_, err = GetOpcodeByName("protobuf.Missing")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,342 @@
+package opcode
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/cocher/internal/protobuf"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// Registry maps opcodes to the message types sent with them. Every registry
+// knows the messages of the reserved opcodes below 1000, which can not be
+// unregistered.
+type Registry struct {
+	mutex sync.RWMutex
+	// opcodes is a map of <Opcode, Entry> pairs
+	opcodes map[Opcode]Entry
+	// types is a map of <reflect.Type, Opcode> pairs
+	types map[reflect.Type]Opcode
+	// names is a map of <full protobuf message name, Opcode> pairs
+	names map[string]Opcode
+}
+
+// Entry is a message type registered to an opcode.
+type Entry struct {
+	Opcode Opcode
+	// Name is the fully qualified protobuf name of the message type, empty
+	// for Go types which are no protobuf messages.
+	Name string
+	// Codec is the name of the codec the message type is encoded with, empty
+	// for protobuf.
+	Codec   string
+	Message interface{}
+}
+
+// NewRegistry returns a registry which only knows the messages of the
+// reserved opcodes.
+func NewRegistry() *Registry {
+	r := &Registry{
+		opcodes: make(map[Opcode]Entry),
+		types:   make(map[reflect.Type]Opcode),
+		names:   make(map[string]Opcode),
+	}
+
+	msgOpcodePairs := []struct {
+		msg    proto.Message
+		opcode Opcode
+	}{
+		{&protobuf.Bytes{}, BytesCode},
+		{&protobuf.Ping{}, PingCode},
+		{&protobuf.Pong{}, PongCode},
+		{&protobuf.LookupNodeRequest{}, LookupNodeRequestCode},
+		{&protobuf.LookupNodeResponse{}, LookupNodeResponseCode},
+		{&protobuf.Keepalive{}, KeepaliveCode},
+		{&protobuf.KeepaliveResponse{}, KeepaliveResponseCode},
+		{&protobuf.Disconnect{}, DisconnectCode},
+		{&protobuf.KeySuccession{}, KeySuccessionCode},
+		{&protobuf.PeerRecord{}, PeerRecordCode},
+		{&protobuf.Identify{}, IdentifyCode},
+		{&protobuf.Capabilities{}, CapabilitiesCode},
+		{&protobuf.ErrorReply{}, ErrorReplyCode},
+		{&protobuf.StreamControl{}, StreamControlCode},
+	}
+
+	for _, pair := range msgOpcodePairs {
+		r.store(Entry{Opcode: pair.opcode, Name: MessageName(pair.msg), Message: pair.msg})
+	}
+
+	return r
+}
+
+// RegisterMessageType registers a new proto message to the given opcode. As
+// before registries existed, only the opcode has to be free: the opcodes of
+// named messages may be taken, and a message type already registered to
+// another opcode is sent with the new one from now on, while both opcodes
+// decode to it. Messages of the reserved opcodes can not be moved.
+func (r *Registry) RegisterMessageType(opcode Opcode, msg proto.Message) error {
+	// reserve first 1000 opcodes
+	if opcode < 1000 {
+		return errors.New("types: opcode must be 1000 or greater")
+	}
+	return r.register(opcode, msg, true)
+}
+
+// RegisterType registers a Go type which is no protobuf message to the given
+// opcode, to be encoded with the named codec, e.g. "json". v is a pointer to
+// a value of the type.
+func (r *Registry) RegisterType(opcode Opcode, v interface{}, codec string) error {
+	if err := checkOpcode(opcode); err != nil {
+		return err
+	}
+	if codec == "" {
+		return errors.New("types: a codec must be given for types which are no protobuf messages")
+	}
+
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return errors.New("types: must provide a pointer to a value of the type")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkFree(opcode, t); err != nil {
+		return err
+	}
+
+	r.store(Entry{Opcode: opcode, Codec: codec, Message: reflect.New(t.Elem()).Interface()})
+
+	return nil
+}
+
+func checkOpcode(opcode Opcode) error {
+	// reserve first 1000 opcodes
+	if opcode < 1000 {
+		return errors.New("types: opcode must be 1000 or greater")
+	}
+	if opcode >= NamedCodeBase {
+		return errors.New("types: opcode is reserved for messages registered by name")
+	}
+	return nil
+}
+
+// RegisterMessage registers a new proto message under an opcode derived from
+// its fully qualified protobuf name.
+func (r *Registry) RegisterMessage(msg proto.Message) (Opcode, error) {
+	name := MessageName(msg)
+	if name == "" {
+		return UnregisteredCode, errors.New("types: message has no protobuf name, was it generated by protoc?")
+	}
+
+	opcode := NamedOpcode(name)
+	return opcode, r.register(opcode, msg, false)
+}
+
+// register stores msg under opcode. Unless legacy is set, neither the type
+// nor the name of the message may be registered already.
+func (r *Registry) register(opcode Opcode, msg proto.Message, legacy bool) error {
+	raw, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(raw) != 0 {
+		return errors.New("types: must provide an empty protobuf message")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	name := MessageName(msg)
+	if legacy {
+		if _, loaded := r.opcodes[opcode]; loaded {
+			return errors.New("types: opcode already exists, choose a different opcode")
+		}
+		if existing, loaded := r.types[reflect.TypeOf(msg)]; loaded && existing < 1000 {
+			return errors.Errorf("types: message type %T is reserved to opcode %d", msg, existing)
+		}
+	} else {
+		if err := r.checkFree(opcode, reflect.TypeOf(msg)); err != nil {
+			return err
+		}
+		if existing, loaded := r.names[name]; loaded && name != "" {
+			return errors.Errorf("types: a message named %s is already registered with opcode %d", name, existing)
+		}
+	}
+
+	r.store(Entry{Opcode: opcode, Name: name, Message: msg})
+
+	return nil
+}
+
+// checkFree returns an error if either the opcode or the type is taken.
+func (r *Registry) checkFree(opcode Opcode, t reflect.Type) error {
+	if existing, loaded := r.opcodes[opcode]; loaded {
+		if reflect.TypeOf(existing.Message) == t {
+			return errors.Errorf("types: message type %s is already registered", t)
+		}
+		return errors.Errorf("types: opcode %d already exists for message type %s, choose a different opcode", opcode, reflect.TypeOf(existing.Message))
+	}
+	if existing, loaded := r.types[t]; loaded {
+		return errors.Errorf("types: message type %s is already registered with opcode %d", t, existing)
+	}
+	return nil
+}
+
+func (r *Registry) store(entry Entry) {
+	r.opcodes[entry.Opcode] = entry
+	r.types[reflect.TypeOf(entry.Message)] = entry.Opcode
+	if entry.Name != "" {
+		r.names[entry.Name] = entry.Opcode
+	}
+}
+
+// Unregister removes the message registered to an opcode. Messages of the
+// reserved opcodes can not be removed.
+func (r *Registry) Unregister(opcode Opcode) error {
+	if opcode < 1000 {
+		return errors.New("types: reserved opcodes can not be unregistered")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, ok := r.opcodes[opcode]
+	if !ok {
+		return errors.New("types: opcode not found, did you register it?")
+	}
+
+	delete(r.opcodes, opcode)
+	if t := reflect.TypeOf(entry.Message); r.types[t] == opcode {
+		delete(r.types, t)
+		if entry.Name != "" {
+			delete(r.names, entry.Name)
+		}
+		// a type registered twice through RegisterMessageType is sent with
+		// the opcode it is still registered to
+		for _, other := range r.opcodes {
+			if reflect.TypeOf(other.Message) == t {
+				r.store(other)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetMessageType returns the corresponding proto message type given an opcode
+func (r *Registry) GetMessageType(code Opcode) (proto.Message, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entry, ok := r.opcodes[code]
+	if !ok {
+		return nil, errors.New("types: opcode not found, did you register it?")
+	}
+	msg, ok := entry.Message.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("types: opcode %d is registered to a type which is no protobuf message", code)
+	}
+	return proto.Clone(msg), nil
+}
+
+// New returns a pointer to a new value of the type registered to an opcode,
+// to decode a message into.
+func (r *Registry) New(code Opcode) (interface{}, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entry, ok := r.opcodes[code]
+	if !ok {
+		return nil, errors.New("types: opcode not found, did you register it?")
+	}
+	return entry.new(), nil
+}
+
+// new returns a copy of the message of the entry, so that the one in the
+// registry is never handed out.
+func (e Entry) new() interface{} {
+	if msg, ok := e.Message.(proto.Message); ok {
+		return proto.Clone(msg)
+	}
+	return reflect.New(reflect.TypeOf(e.Message).Elem()).Interface()
+}
+
+// Codec returns the name of the codec the type registered to an opcode is
+// encoded with, empty for protobuf.
+func (r *Registry) Codec(code Opcode) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.opcodes[code].Codec
+}
+
+// GetOpcode returns the corresponding opcode given a message
+func (r *Registry) GetOpcode(msg interface{}) (Opcode, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if opcode, ok := r.types[reflect.TypeOf(msg)]; ok {
+		return opcode, nil
+	}
+	return UnregisteredCode, errors.New("types: message type not found, did you register it?")
+}
+
+// GetOpcodeByName returns the opcode of a registered message given its fully
+// qualified protobuf name
+func (r *Registry) GetOpcodeByName(name string) (Opcode, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if opcode, ok := r.names[name]; ok {
+		return opcode, nil
+	}
+	return UnregisteredCode, errors.Errorf("types: message %s not found, did you register it?", name)
+}
+
+// GetMessageName returns the fully qualified protobuf name of the message
+// registered to an opcode
+func (r *Registry) GetMessageName(code Opcode) (string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if entry, ok := r.opcodes[code]; ok {
+		return entry.Name, nil
+	}
+	return "", errors.New("types: opcode not found, did you register it?")
+}
+
+// Opcodes returns all registered opcodes in ascending order
+func (r *Registry) Opcodes() []Opcode {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	opcodes := make([]Opcode, 0, len(r.opcodes))
+	for opcode := range r.opcodes {
+		opcodes = append(opcodes, opcode)
+	}
+	sort.Slice(opcodes, func(i, j int) bool {
+		return opcodes[i] < opcodes[j]
+	})
+	return opcodes
+}
+
+// Entries returns all registered message types in ascending order of opcodes
+func (r *Registry) Entries() []Entry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := make([]Entry, 0, len(r.opcodes))
+	for _, entry := range r.opcodes {
+		entry.Message = entry.new()
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Opcode < entries[j].Opcode
+	})
+	return entries
+}
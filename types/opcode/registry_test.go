@@ -0,0 +1,101 @@
+package opcode
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/cocher/internal/protobuf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	first, second := NewRegistry(), NewRegistry()
+
+	// registries are independent of each other
+	assert.Nil(t, first.RegisterMessageType(Opcode(1000), &pb.ID{}))
+	assert.Nil(t, second.RegisterMessageType(Opcode(1000), &pb.SignedAddress{}))
+
+	msgType, err := first.GetMessageType(Opcode(1000))
+	assert.Nil(t, err)
+	assert.Equal(t, reflect.TypeOf(&pb.ID{}), reflect.TypeOf(msgType))
+
+	_, err = second.GetOpcode(&pb.ID{})
+	assert.NotNil(t, err, "message should only be registered in the first registry")
+
+	_, err = GetOpcode(&pb.ID{})
+	assert.NotNil(t, err, "message should not be registered in the default registry")
+
+	// every registry knows the reserved messages
+	code, err := second.GetOpcode(&pb.Ping{})
+	assert.Nil(t, err)
+	assert.Equal(t, PingCode, code)
+
+	// test failure if a reserved message is unregistered
+	assert.NotNil(t, first.Unregister(PingCode))
+
+	// unregistered opcodes and messages may be registered again
+	assert.Nil(t, first.Unregister(Opcode(1000)))
+	assert.NotNil(t, first.Unregister(Opcode(1000)))
+
+	_, err = first.GetMessageType(Opcode(1000))
+	assert.NotNil(t, err)
+	_, err = first.GetOpcodeByName("protobuf.ID")
+	assert.NotNil(t, err)
+
+	assert.Nil(t, first.RegisterMessageType(Opcode(1001), &pb.ID{}))
+	assert.Nil(t, first.RegisterMessageType(Opcode(1000), &pb.SignedAddress{}))
+
+	// as before registries existed, a type may be registered to a second
+	// numeric opcode, also in the range of derived opcodes, but an opcode
+	// only once
+	assert.NotNil(t, first.RegisterMessageType(Opcode(1001), &pb.SignedAddress{}))
+	assert.Nil(t, first.RegisterMessageType(NamedCodeBase+1, &pb.ID{}))
+	code, err = first.GetOpcode(&pb.ID{})
+	assert.Nil(t, err)
+	assert.Equal(t, NamedCodeBase+1, code)
+	assert.Nil(t, first.Unregister(NamedCodeBase+1))
+	code, err = first.GetOpcode(&pb.ID{})
+	assert.Nil(t, err)
+	assert.Equal(t, Opcode(1001), code)
+
+	entries := first.Entries()
+	assert.Equal(t, first.Opcodes()[0], entries[0].Opcode)
+	assert.Equal(t, Entry{Opcode: 1000, Name: "protobuf.SignedAddress", Message: &pb.SignedAddress{}}, entries[len(entries)-2])
+	assert.Equal(t, Entry{Opcode: 1001, Name: "protobuf.ID", Message: &pb.ID{}}, entries[len(entries)-1])
+}
+
+type jsonType struct {
+	Message string
+}
+
+func TestRegisterType(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+
+	assert.NotNil(t, r.RegisterType(Opcode(1000), &jsonType{}, ""), "a codec should be required")
+	assert.NotNil(t, r.RegisterType(Opcode(1000), jsonType{}, "json"), "a pointer should be required")
+	assert.Nil(t, r.RegisterType(Opcode(1000), &jsonType{}, "json"))
+	assert.NotNil(t, r.RegisterType(Opcode(1001), &jsonType{}, "json"), "types should only be registered once")
+
+	code, err := r.GetOpcode(&jsonType{Message: "hello"})
+	assert.Nil(t, err)
+	assert.Equal(t, Opcode(1000), code)
+	assert.Equal(t, "json", r.Codec(code))
+	assert.Equal(t, "", r.Codec(PingCode), "protobuf messages should have no codec")
+
+	// a new zero value is returned to decode into
+	v, err := r.New(code)
+	assert.Nil(t, err)
+	assert.Equal(t, &jsonType{}, v)
+
+	_, err = r.GetMessageType(code)
+	assert.NotNil(t, err, "types which are no protobuf messages should not be returned as such")
+
+	name, err := r.GetMessageName(code)
+	assert.Nil(t, err)
+	assert.Equal(t, "", name)
+}
@@ -1,5 +1,12 @@
 //go:generate go run gen_pb.go
 
+// gen_pb.go generates the Go code of every protobuf file of the repository,
+// with protoc-gen-gogofaster for messages and protoc-gen-cocher for services,
+// which must both be installed, e.g. with
+//
+//	go install github.com/gogo/protobuf/protoc-gen-gogofaster
+//	go install github.com/cocher/utils/script/protoc-gen-cocher
+
 package script
 
 import (
@@ -35,6 +42,7 @@ func generateProtos(dir string) error {
 				fmt.Sprintf("-I=%s", filepath.Join(goPath, "src", "github.com", "gogo", "protobuf", "protobuf")),
 				fmt.Sprintf("--proto_path=%s", filepath.Join(goPath, "src", "github.com")),
 				"--gogofaster_out=Mgoogle/protobuf/any.proto=github.com/gogo/protobuf/types,Mgoogle/protobuf/duration.proto=github.com/gogo/protobuf/types,Mgoogle/protobuf/struct.proto=github.com/gogo/protobuf/types,Mgoogle/protobuf/timestamp.proto=github.com/gogo/protobuf/types,Mgoogle/protobuf/wrappers.proto=github.com/gogo/protobuf/types:.",
+				"--cocher_out=.",
 				path,
 			}
 			cmd := exec.Command("protoc", args...)
@@ -0,0 +1,420 @@
+// protoc-gen-cocher is a protoc plugin generating typed clients and servers
+// of the services declared in protobuf files, sending requests between peers
+// of a network with PeerClient.Request and replying with ComponentContext.Reply.
+//
+// Install it with
+//
+//	go install github.com/cocher/utils/script/protoc-gen-cocher
+//
+// and generate the services of a file next to its messages with
+//
+//	protoc --gogofaster_out=. --cocher_out=. service.proto
+//
+// For every service Foo, a file service.cocher.go holds:
+//
+//   - FooClient, created with NewFooClient from a peer client, sending each
+//     method as a request and returning its reply.
+//   - FooServer, the interface the handlers of the methods implement, and
+//     RegisterFooServer registering them onto a router, e.g. in the
+//     RegisterHandlers callback of a Component.
+//   - RegisterFooMessages, registering the messages of the methods in a
+//     message registry.
+//
+// Methods streaming their replies are sent with PeerClient.RequestStream, and
+// replied to through a stream. Methods streaming their requests are not
+// supported.
+//
+// Requests are routed to their method by their message type, so each method
+// must take a message type of its own. The messages of the methods are
+// registered with opcodes derived from their names, unless registered
+// already, in the registry shared by the whole process when the package is
+// initialized, and in the registry of the network of the router a server is
+// registered onto. Networks with a registry of their own which only send
+// requests of a service register its messages with RegisterFooMessages.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/gogo/protobuf/protoc-gen-gogo/generator"
+	plugin "github.com/gogo/protobuf/protoc-gen-gogo/plugin"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fail(errors.Wrap(err, "protoc-gen-cocher: failed to read request"))
+	}
+
+	req := new(plugin.CodeGeneratorRequest)
+	if err := proto.Unmarshal(data, req); err != nil {
+		fail(errors.Wrap(err, "protoc-gen-cocher: failed to parse request"))
+	}
+
+	data, err = proto.Marshal(generate(req))
+	if err != nil {
+		fail(errors.Wrap(err, "protoc-gen-cocher: failed to marshal response"))
+	}
+
+	if _, err := os.Stdout.Write(data); err != nil {
+		fail(errors.Wrap(err, "protoc-gen-cocher: failed to write response"))
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// generate generates the services of the files to generate. Files without
+// services are skipped.
+func generate(req *plugin.CodeGeneratorRequest) *plugin.CodeGeneratorResponse {
+	resp := new(plugin.CodeGeneratorResponse)
+
+	files := make(map[string]*descriptor.FileDescriptorProto)
+	for _, file := range req.ProtoFile {
+		files[file.GetName()] = file
+	}
+
+	for _, name := range req.FileToGenerate {
+		file, ok := files[name]
+		if !ok {
+			resp.Error = proto.String(fmt.Sprintf("protoc-gen-cocher: file %s to generate was not given", name))
+			return resp
+		}
+
+		if len(file.Service) == 0 {
+			continue
+		}
+
+		content, err := generateFile(file)
+		if err != nil {
+			resp.Error = proto.String(err.Error())
+			return resp
+		}
+
+		resp.File = append(resp.File, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(strings.TrimSuffix(name, path.Ext(name)) + ".cocher.go"),
+			Content: proto.String(content),
+		})
+	}
+
+	return resp
+}
+
+type fileData struct {
+	Source   string
+	Package  string
+	Services []serviceData
+}
+
+type serviceData struct {
+	Name     string
+	Messages []string
+	Methods  []methodData
+}
+
+type methodData struct {
+	Name   string
+	Input  string
+	Output string
+	Stream bool
+}
+
+// generateFile generates the services of a file.
+func generateFile(file *descriptor.FileDescriptorProto) (string, error) {
+	data := fileData{Source: file.GetName(), Package: packageName(file)}
+
+	inputs := make(map[string]string)
+
+	for _, service := range file.Service {
+		s := serviceData{Name: generator.CamelCase(service.GetName())}
+		registered := make(map[string]bool)
+
+		for _, method := range service.Method {
+			name := s.Name + "." + generator.CamelCase(method.GetName())
+
+			if method.GetClientStreaming() {
+				return "", errors.Errorf("protoc-gen-cocher: method %s streams requests, which is not supported", name)
+			}
+
+			input, err := typeName(file, method.GetInputType())
+			if err != nil {
+				return "", errors.Wrapf(err, "protoc-gen-cocher: invalid request of method %s", name)
+			}
+			output, err := typeName(file, method.GetOutputType())
+			if err != nil {
+				return "", errors.Wrapf(err, "protoc-gen-cocher: invalid reply of method %s", name)
+			}
+
+			if other, taken := inputs[input]; taken {
+				return "", errors.Errorf("protoc-gen-cocher: methods %s and %s both take %s, but each method needs a request message of its own", other, name, input)
+			}
+			inputs[input] = name
+
+			for _, msg := range []string{input, output} {
+				if !registered[msg] {
+					registered[msg] = true
+					s.Messages = append(s.Messages, msg)
+				}
+			}
+
+			s.Methods = append(s.Methods, methodData{
+				Name:   generator.CamelCase(method.GetName()),
+				Input:  input,
+				Output: output,
+				Stream: method.GetServerStreaming(),
+			})
+		}
+
+		data.Services = append(data.Services, s)
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return "", errors.Wrap(err, "protoc-gen-cocher: failed to generate code")
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", errors.Wrap(err, "protoc-gen-cocher: failed to format generated code")
+	}
+	return string(source), nil
+}
+
+// packageName returns the name of the Go package of a file, as protoc-gen-gogo
+// names it.
+func packageName(file *descriptor.FileDescriptorProto) string {
+	name := file.GetOptions().GetGoPackage()
+	if i := strings.LastIndex(name, ";"); i >= 0 {
+		name = name[i+1:]
+	} else if name != "" {
+		name = path.Base(name)
+	}
+
+	if name == "" {
+		name = file.GetPackage()
+	}
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(file.GetName()), path.Ext(file.GetName()))
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// typeName returns the name of the Go type of a message, which must be
+// declared in the package of the file.
+func typeName(file *descriptor.FileDescriptorProto, name string) (string, error) {
+	prefix := "."
+	if file.GetPackage() != "" {
+		prefix += file.GetPackage() + "."
+	}
+
+	if !strings.HasPrefix(name, prefix) {
+		return "", errors.Errorf("message %s is not declared in package %s", strings.TrimPrefix(name, "."), file.GetPackage())
+	}
+
+	return generator.CamelCaseSlice(strings.Split(strings.TrimPrefix(name, prefix), ".")), nil
+}
+
+func unexport(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+var fileTemplate = template.Must(template.New("file").Funcs(template.FuncMap{"unexport": unexport}).Parse(`// Code generated by protoc-gen-cocher. DO NOT EDIT.
+// source: {{.Source}}
+
+package {{.Package}}
+
+import (
+	context "context"
+
+	network "github.com/cocher/network"
+	opcode "github.com/cocher/types/opcode"
+	proto "github.com/gogo/protobuf/proto"
+	errors "github.com/pkg/errors"
+)
+
+func init() {
+{{- range .Services}}
+	if err := Register{{.Name}}Messages(opcode.DefaultRegistry()); err != nil {
+		panic(err)
+	}
+{{- end}}
+}
+{{range $service := .Services}}
+// Register{{.Name}}Messages registers the messages of the methods of the
+// {{.Name}} service in a registry, with opcodes derived from their names,
+// unless registered already.
+func Register{{.Name}}Messages(registry *opcode.Registry) error {
+	for _, msg := range []proto.Message{
+{{- range .Messages}}
+		&{{.}}{},
+{{- end}}
+	} {
+		if _, err := registry.GetOpcode(msg); err == nil {
+			continue
+		}
+		if _, err := registry.RegisterMessage(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// {{.Name}}Client is the client API of the {{.Name}} service.
+type {{.Name}}Client interface {
+{{- range .Methods}}
+{{- if .Stream}}
+	{{.Name}}(ctx context.Context, in *{{.Input}}) ({{$service.Name}}_{{.Name}}Client, error)
+{{- else}}
+	{{.Name}}(ctx context.Context, in *{{.Input}}) (*{{.Output}}, error)
+{{- end}}
+{{- end}}
+}
+
+type {{unexport .Name}}Client struct {
+	client *network.PeerClient
+}
+
+// New{{.Name}}Client returns a client of the {{.Name}} service of a peer.
+func New{{.Name}}Client(client *network.PeerClient) {{.Name}}Client {
+	return &{{unexport .Name}}Client{client: client}
+}
+{{range .Methods}}
+{{- if .Stream}}
+func (c *{{unexport $service.Name}}Client) {{.Name}}(ctx context.Context, in *{{.Input}}) ({{$service.Name}}_{{.Name}}Client, error) {
+	stream, err := c.client.RequestStream(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return &{{unexport $service.Name}}{{.Name}}Client{stream: stream}, nil
+}
+
+// {{$service.Name}}_{{.Name}}Client receives the replies of the {{.Name}} method of the {{$service.Name}} service.
+type {{$service.Name}}_{{.Name}}Client interface {
+	// Recv returns the next reply, or io.EOF once the server sent all replies.
+	Recv() (*{{.Output}}, error)
+	// Close stops receiving replies.
+	Close()
+}
+
+type {{unexport $service.Name}}{{.Name}}Client struct {
+	stream *network.ReplyStream
+}
+
+func (x *{{unexport $service.Name}}{{.Name}}Client) Recv() (*{{.Output}}, error) {
+	res, err := x.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.(*{{.Output}})
+	if !ok {
+		return nil, errors.Errorf("{{$service.Name}}.{{.Name}}: expected a reply of type *{{.Output}}, got %T", res)
+	}
+	return out, nil
+}
+
+func (x *{{unexport $service.Name}}{{.Name}}Client) Close() {
+	x.stream.Close()
+}
+{{else}}
+func (c *{{unexport $service.Name}}Client) {{.Name}}(ctx context.Context, in *{{.Input}}) (*{{.Output}}, error) {
+	res, err := c.client.Request(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := res.(*{{.Output}})
+	if !ok {
+		return nil, errors.Errorf("{{$service.Name}}.{{.Name}}: expected a reply of type *{{.Output}}, got %T", res)
+	}
+	return out, nil
+}
+{{end}}
+{{- end}}
+// {{.Name}}Server is the server API of the {{.Name}} service. Errors returned
+// are replied to the client.
+type {{.Name}}Server interface {
+{{- range .Methods}}
+{{- if .Stream}}
+	{{.Name}}(ctx *network.ComponentContext, in *{{.Input}}, stream {{$service.Name}}_{{.Name}}Server) error
+{{- else}}
+	{{.Name}}(ctx *network.ComponentContext, in *{{.Input}}) (*{{.Output}}, error)
+{{- end}}
+{{- end}}
+}
+{{range .Methods}}
+{{- if .Stream}}
+// {{$service.Name}}_{{.Name}}Server sends the replies of the {{.Name}} method of the {{$service.Name}} service.
+type {{$service.Name}}_{{.Name}}Server interface {
+	// Send sends a reply, waiting for the client to have room for it.
+	Send(out *{{.Output}}) error
+	// Context returns the context of the request, done once the client
+	// canceled it.
+	Context() context.Context
+}
+
+type {{unexport $service.Name}}{{.Name}}Server struct {
+	stream *network.StreamWriter
+}
+
+func (x *{{unexport $service.Name}}{{.Name}}Server) Send(out *{{.Output}}) error {
+	return x.stream.Send(x.stream.Context(), out)
+}
+
+func (x *{{unexport $service.Name}}{{.Name}}Server) Context() context.Context {
+	return x.stream.Context()
+}
+{{end}}
+{{- end}}
+// Register{{.Name}}Server registers the handlers of the methods of the {{.Name}}
+// service onto a router, e.g. in the RegisterHandlers callback of a Component,
+// and the messages of the methods in the registry of its network.
+func Register{{.Name}}Server(router *network.Router, srv {{.Name}}Server) error {
+	if err := Register{{.Name}}Messages(router.Registry()); err != nil {
+		return err
+	}
+{{- range .Methods}}
+{{- if .Stream}}
+	if err := router.Handle(&{{.Input}}{}, func(ctx *network.ComponentContext, in *{{.Input}}) error {
+		stream, err := ctx.Stream()
+		if err != nil {
+			return ctx.ReplyError(context.Background(), err)
+		}
+		if err := srv.{{.Name}}(ctx, in, &{{unexport $service.Name}}{{.Name}}Server{stream: stream}); err != nil {
+			return stream.CloseWithError(context.Background(), err)
+		}
+		return stream.Close(context.Background())
+	}); err != nil {
+		return err
+	}
+{{- else}}
+	if err := router.Handle(&{{.Input}}{}, func(ctx *network.ComponentContext, in *{{.Input}}) error {
+		out, err := srv.{{.Name}}(ctx, in)
+		if err != nil {
+			return ctx.ReplyError(context.Background(), err)
+		}
+		return ctx.Reply(context.Background(), out)
+	}); err != nil {
+		return err
+	}
+{{- end}}
+{{- end}}
+	return nil
+}
+{{end}}`))
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	plugin "github.com/gogo/protobuf/protoc-gen-gogo/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func method(name string, input string, output string, stream bool) *descriptor.MethodDescriptorProto {
+	return &descriptor.MethodDescriptorProto{
+		Name:            proto.String(name),
+		InputType:       proto.String(input),
+		OutputType:      proto.String(output),
+		ServerStreaming: proto.Bool(stream),
+	}
+}
+
+func request(methods ...*descriptor.MethodDescriptorProto) *plugin.CodeGeneratorRequest {
+	return &plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{"foo/bar.proto"},
+		ProtoFile: []*descriptor.FileDescriptorProto{
+			{
+				Name:    proto.String("foo/bar.proto"),
+				Package: proto.String("foo.bar"),
+				Options: &descriptor.FileOptions{GoPackage: proto.String("github.com/cocher/foo;foo")},
+				Service: []*descriptor.ServiceDescriptorProto{
+					{Name: proto.String("cache"), Method: methods},
+				},
+			},
+			{Name: proto.String("foo/empty.proto")},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	resp := generate(request(
+		method("get", ".foo.bar.GetRequest", ".foo.bar.Entry", false),
+		method("list_all", ".foo.bar.Query.List", ".foo.bar.Entry", true),
+	))
+	if !assert.Nil(t, resp.Error) || !assert.Len(t, resp.File, 1) {
+		return
+	}
+	assert.Equal(t, "foo/bar.cocher.go", resp.File[0].GetName())
+
+	content := resp.File[0].GetContent()
+	file, err := parser.ParseFile(token.NewFileSet(), "bar.cocher.go", content, 0)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "foo", file.Name.Name)
+
+	declared := make(map[string]bool)
+	for name := range file.Scope.Objects {
+		declared[name] = true
+	}
+	for _, name := range []string{
+		"CacheClient", "NewCacheClient",
+		"Cache_ListAllClient",
+		"CacheServer", "RegisterCacheServer",
+		"RegisterCacheMessages",
+		"Cache_ListAllServer",
+	} {
+		assert.True(t, declared[name], "expected %s to be declared", name)
+	}
+
+	assert.Contains(t, content, "Get(ctx context.Context, in *GetRequest) (*Entry, error)")
+	assert.Contains(t, content, "ListAll(ctx context.Context, in *Query_List) (Cache_ListAllClient, error)")
+	assert.Contains(t, content, "ListAll(ctx *network.ComponentContext, in *Query_List, stream Cache_ListAllServer) error")
+	assert.Contains(t, content, "&GetRequest{},\n\t\t&Entry{},\n\t\t&Query_List{},\n")
+
+	// Messages are registered in the default registry, and in the registry of
+	// the network servers are registered onto.
+	assert.Contains(t, content, "RegisterCacheMessages(opcode.DefaultRegistry())")
+	assert.Contains(t, content, "RegisterCacheMessages(router.Registry())")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	streaming := method("put", ".foo.bar.PutRequest", ".foo.bar.Entry", false)
+	streaming.ClientStreaming = proto.Bool(true)
+
+	for _, req := range []*plugin.CodeGeneratorRequest{
+		request(streaming),
+		request(
+			method("get", ".foo.bar.GetRequest", ".foo.bar.Entry", false),
+			method("watch", ".foo.bar.GetRequest", ".foo.bar.Entry", true),
+		),
+		request(method("get", ".other.GetRequest", ".foo.bar.Entry", false)),
+	} {
+		resp := generate(req)
+		assert.NotNil(t, resp.Error)
+		assert.Empty(t, resp.File)
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	for _, tc := range []struct {
+		options *descriptor.FileOptions
+		pkg     string
+		name    string
+	}{
+		{&descriptor.FileOptions{GoPackage: proto.String("github.com/cocher/foo;bar")}, "pkg", "bar"},
+		{&descriptor.FileOptions{GoPackage: proto.String("github.com/cocher/foo")}, "pkg", "foo"},
+		{nil, "foo.bar", "foo_bar"},
+		{nil, "", "file"},
+	} {
+		file := &descriptor.FileDescriptorProto{
+			Name:    proto.String("dir/file.proto"),
+			Package: proto.String(tc.pkg),
+			Options: tc.options,
+		}
+		assert.Equal(t, tc.name, packageName(file))
+	}
+}
@@ -0,0 +1,326 @@
+// Package semver parses semantic versions and the ranges of versions
+// protocols declare themselves compatible with.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Version is a semantic version, MAJOR.MINOR.PATCH with an optional
+// pre-release. Build metadata is dropped when parsing.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease string
+}
+
+// Parse parses a full semantic version, optionally prefixed with "v".
+func Parse(s string) (Version, error) {
+	v, parts, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if parts != 3 {
+		return Version{}, errors.Errorf("semver: version %q is missing a minor or patch number", s)
+	}
+	return v, nil
+}
+
+// MustParse is like Parse but panics if the version is malformed.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the version in its canonical form.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or greater than o,
+// following the precedence rules of semantic versioning.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return compareUint(v.Major, o.Major)
+	case v.Minor != o.Minor:
+		return compareUint(v.Minor, o.Minor)
+	case v.Patch != o.Patch:
+		return compareUint(v.Patch, o.Patch)
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease orders pre-releases before releases, and compares their
+// dot separated identifiers numerically if both are numbers, lexically
+// otherwise, with numbers before other identifiers.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareUint(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareUint(uint64(len(as)), uint64(len(bs)))
+}
+
+// parsePartial parses a version which may leave out trailing numbers or
+// replace them with a wildcard ("x", "X" or "*"). It returns the number of
+// parts given, the rest being zero.
+func parsePartial(s string) (Version, int, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "v")
+
+	if i := strings.IndexByte(raw, '+'); i >= 0 {
+		raw = raw[:i]
+	}
+
+	var v Version
+	if i := strings.IndexByte(raw, '-'); i >= 0 {
+		raw, v.Prerelease = raw[:i], raw[i+1:]
+		if v.Prerelease == "" {
+			return Version{}, 0, errors.Errorf("semver: version %q has an empty pre-release", s)
+		}
+	}
+
+	fields := strings.Split(raw, ".")
+	if len(fields) > 3 {
+		return Version{}, 0, errors.Errorf("semver: version %q has too many parts", s)
+	}
+
+	numbers := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	parts := 0
+	for _, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+
+		n, err := strconv.ParseUint(field, 10, 64)
+		if err != nil || (len(field) > 1 && field[0] == '0') {
+			return Version{}, 0, errors.Errorf("semver: version %q has a malformed number %q", s, field)
+		}
+		*numbers[parts] = n
+		parts++
+	}
+
+	if v.Prerelease != "" && parts != 3 {
+		return Version{}, 0, errors.Errorf("semver: version %q has a pre-release but no patch number", s)
+	}
+
+	return v, parts, nil
+}
+
+type operator int
+
+const (
+	opEQ operator = iota
+	opGT
+	opGTE
+	opLT
+	opLTE
+)
+
+type comparator struct {
+	op      operator
+	version Version
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case opGT:
+		return cmp > 0
+	case opGTE:
+		return cmp >= 0
+	case opLT:
+		return cmp < 0
+	case opLTE:
+		return cmp <= 0
+	}
+	return cmp == 0
+}
+
+// Range is a set of versions. Comparators separated by spaces must all match,
+// and sets of them separated by "||" are alternatives. Supported comparators
+// are "=", ">", ">=", "<", "<=" followed by a version, "^1.2.3" for versions
+// compatible with 1.2.3, "~1.2.3" for patches of 1.2, partial versions such
+// as "1.2" or "1.x" for all versions they leave open, and "*" for any version.
+type Range struct {
+	raw  string
+	sets [][]comparator
+}
+
+// ParseRange parses a range of versions.
+func ParseRange(s string) (Range, error) {
+	r := Range{raw: s}
+
+	for _, alternative := range strings.Split(s, "||") {
+		var set []comparator
+		for _, field := range strings.Fields(alternative) {
+			comparators, err := parseComparator(field)
+			if err != nil {
+				return Range{}, errors.Wrapf(err, "semver: malformed range %q", s)
+			}
+			set = append(set, comparators...)
+		}
+		r.sets = append(r.sets, set)
+	}
+
+	return r, nil
+}
+
+// MustParseRange is like ParseRange but panics if the range is malformed.
+func MustParseRange(s string) Range {
+	r, err := ParseRange(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Contains returns true if the version is in the range.
+func (r Range) Contains(v Version) bool {
+	for _, set := range r.sets {
+		matches := true
+		for _, c := range set {
+			if !c.matches(v) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the range as it was parsed.
+func (r Range) String() string {
+	return r.raw
+}
+
+// parseComparator expands a single comparator of a range into bounds.
+func parseComparator(s string) ([]comparator, error) {
+	var op string
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(s, prefix) {
+			op = prefix
+			break
+		}
+	}
+
+	v, parts, err := parsePartial(s[len(op):])
+	if err != nil {
+		return nil, err
+	}
+
+	// The first version past all versions a partial version leaves open.
+	next := func(parts int) Version {
+		switch parts {
+		case 1:
+			return Version{Major: v.Major + 1}
+		case 2:
+			return Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+
+	switch op {
+	case ">=":
+		return []comparator{{opGTE, v}}, nil
+	case "<":
+		return []comparator{{opLT, v}}, nil
+	case ">":
+		if parts == 3 {
+			return []comparator{{opGT, v}}, nil
+		}
+		if parts == 0 {
+			return nil, errors.Errorf("semver: no version is greater than %q", s)
+		}
+		return []comparator{{opGTE, next(parts)}}, nil
+	case "<=":
+		if parts == 3 {
+			return []comparator{{opLTE, v}}, nil
+		}
+		if parts == 0 {
+			return []comparator{{opGTE, Version{}}}, nil
+		}
+		return []comparator{{opLT, next(parts)}}, nil
+	case "^":
+		// Changes left of the first non-zero number are breaking.
+		upper := parts
+		switch {
+		case v.Major > 0 || parts == 1:
+			upper = 1
+		case v.Minor > 0 || parts == 2:
+			upper = 2
+		}
+		if parts == 0 {
+			return []comparator{{opGTE, Version{}}}, nil
+		}
+		return []comparator{{opGTE, v}, {opLT, next(upper)}}, nil
+	case "~":
+		if parts == 0 {
+			return []comparator{{opGTE, Version{}}}, nil
+		}
+		if parts == 3 {
+			parts = 2
+		}
+		return []comparator{{opGTE, v}, {opLT, next(parts)}}, nil
+	}
+
+	// A plain or "=" version is exact if complete, and covers all versions it
+	// leaves open otherwise.
+	switch parts {
+	case 0:
+		return []comparator{{opGTE, Version{}}}, nil
+	case 3:
+		return []comparator{{opEQ, v}}, nil
+	}
+	return []comparator{{opGTE, v}, {opLT, next(parts)}}, nil
+}
@@ -0,0 +1,117 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		raw     string
+		version Version
+		valid   bool
+	}{
+		{"1.2.3", Version{Major: 1, Minor: 2, Patch: 3}, true},
+		{"v0.10.0", Version{Minor: 10}, true},
+		{"1.0.0-beta.2", Version{Major: 1, Prerelease: "beta.2"}, true},
+		{"1.0.0+build.5", Version{Major: 1}, true},
+		{"1.2", Version{}, false},
+		{"1.2.3.4", Version{}, false},
+		{"01.2.3", Version{}, false},
+		{"1.2.x", Version{}, false},
+		{"1.0.0-", Version{}, false},
+		{"one.two.three", Version{}, false},
+	}
+
+	for _, tt := range testCases {
+		version, err := Parse(tt.raw)
+		if !tt.valid {
+			assert.NotNilf(t, err, "expected %q to be rejected", tt.raw)
+			continue
+		}
+		assert.Nilf(t, err, "expected %q to parse", tt.raw)
+		assert.Equal(t, tt.version, version)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	// in ascending order of precedence
+	ordered := []string{
+		"0.9.9",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.10.0",
+		"2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			assert.Equalf(t, expected, MustParse(ordered[i]).Compare(MustParse(ordered[j])), "comparing %s to %s", ordered[i], ordered[j])
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		raw      string
+		contains []string
+		excludes []string
+	}{
+		{"*", []string{"0.0.1", "3.2.1"}, nil},
+		{"", []string{"0.0.1", "3.2.1"}, nil},
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4", "1.2.2"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"1.x", []string{"1.0.0", "1.9.9"}, []string{"0.9.9", "2.0.0"}},
+		{"1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "1.1.9"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0", "0.2.2"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{"^0.2", []string{"0.2.0", "0.2.9"}, []string{"0.3.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{">=1.0.0 <2.0.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.9", "2.0.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{">1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"^1.0.0 || ^3.0.0", []string{"1.1.0", "3.1.0"}, []string{"2.0.0", "4.0.0"}},
+	}
+
+	for _, tt := range testCases {
+		r, err := ParseRange(tt.raw)
+		if !assert.Nilf(t, err, "expected range %q to parse", tt.raw) {
+			continue
+		}
+		for _, raw := range tt.contains {
+			assert.Truef(t, r.Contains(MustParse(raw)), "expected %q to contain %s", tt.raw, raw)
+		}
+		for _, raw := range tt.excludes {
+			assert.Falsef(t, r.Contains(MustParse(raw)), "expected %q to exclude %s", tt.raw, raw)
+		}
+	}
+
+	for _, raw := range []string{">x", "^1.a", "1.2.3.4", ">=1.0.0 <two"} {
+		_, err := ParseRange(raw)
+		assert.NotNilf(t, err, "expected range %q to be rejected", raw)
+	}
+}